@@ -0,0 +1,77 @@
+package streams
+
+import "sort"
+
+// PartitionByKey returns a stream consisting of the elements of this stream arranged so that all elements with the same key (computed using the
+// given key function) are evaluated by the same routine of a parallel stream. Operations keeping per key state therefore never see the same key
+// from two routines at once. Keys are spread across the routines of the stream by the number of their elements, largest first, and the order of
+// elements sharing a key is preserved. Parallelizing or repartitioning the returned stream discards the key affinity.
+func PartitionByKey[T any, K comparable](s Stream[T], key func(x T) K) Stream[T] {
+	source := s.(*stream[T])
+	if key == nil {
+		panic(errIllegalArgument("PartitionByKey", "nil"))
+	} else if err := source.close(); err != nil {
+		panic(err)
+	}
+
+	n := 1
+	if source.parallel {
+		n = source.executor.maxRoutines
+	}
+	supplier := elementsSupplier(source)
+	var bounds []int
+	e := source.executor
+	e.perElement = false
+	e.bounds = func(length int) []int {
+		if len(bounds) == 0 || bounds[len(bounds)-1] != length {
+			return subIntervals(length, n)
+		}
+		return bounds
+	}
+
+	result := source.sourced(func() []T {
+		var data []T
+		data, bounds = partitionByKey(supplier(), key, n)
+		return data
+	})
+	result.auto, result.executor = false, e
+	return result
+}
+
+// partitionByKey arranges the given data into n contiguous partitions such that elements sharing a key are in the same partition, returns the
+// arranged data and the bounds of the partitions. Keys are assigned to the least loaded partition in descending order of their number of elements.
+func partitionByKey[T any, K comparable](data []T, key func(x T) K, n int) ([]T, []int) {
+	groups := make(map[K][]T)
+	keys := make([]K, 0)
+	for _, x := range data {
+		k := key(x)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], x)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return len(groups[keys[i]]) > len(groups[keys[j]]) })
+
+	partitions := make([][]K, n)
+	loads := make([]int, n)
+	for _, k := range keys {
+		least := 0
+		for i := range loads {
+			if loads[i] < loads[least] {
+				least = i
+			}
+		}
+		partitions[least] = append(partitions[least], k)
+		loads[least] += len(groups[k])
+	}
+
+	arranged := make([]T, 0, len(data))
+	bounds := make([]int, 0, n+1)
+	for _, partition := range partitions {
+		bounds = append(bounds, len(arranged))
+		for _, k := range partition {
+			arranged = append(arranged, groups[k]...)
+		}
+	}
+	return arranged, append(bounds, len(arranged))
+}
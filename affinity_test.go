@@ -0,0 +1,66 @@
+package streams
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionByKey(t *testing.T) {
+
+	data := make([]int, 0)
+	for i := 0; i < 200; i++ {
+		data = append(data, i%7)
+	}
+	supplier := func() []int { return data }
+	key := func(x int) int { return x }
+
+	type partitionByKeyTest struct {
+		s        Stream[int]
+		routines int
+	}
+
+	partitionByKeyTests := []partitionByKeyTest{
+		{s: New(supplier), routines: 1},
+		{s: New(supplier).Parallelize(3), routines: 3},
+		{s: New(supplier).ParallelizeWith(IOBound(4)), routines: 4},
+	}
+
+	for _, test := range partitionByKeyTests {
+		s := PartitionByKey(test.s, key).(*stream[int])
+		assert.Equal(t, test.routines, s.ExecutionInfo().Workers)
+
+		// Each key must be in exactly one of the chunks given to routines.
+		arranged := s.supplier()
+		bounds := s.executor.bounds(len(arranged))
+		assert.Equal(t, test.routines+1, len(bounds))
+		owners := make(map[int]int)
+		for i := 0; i < len(bounds)-1; i++ {
+			for _, x := range arranged[bounds[i]:bounds[i+1]] {
+				if owner, ok := owners[x]; ok {
+					assert.Equal(t, i, owner)
+				}
+				owners[x] = i
+			}
+		}
+		assert.Equal(t, 7, len(owners))
+		assert.ElementsMatch(t, data, arranged)
+	}
+
+	var mux sync.Mutex
+	counts := make(map[int]int)
+	PartitionByKey(New(supplier).Parallelize(3), key).ForEach(func(x int) {
+		mux.Lock()
+		defer mux.Unlock()
+		counts[x]++
+	})
+	assert.Equal(t, map[int]int{0: 29, 1: 29, 2: 29, 3: 29, 4: 28, 5: 28, 6: 28}, counts)
+
+	arranged, bounds := partitionByKey([]string{"a", "b", "a", "c", "a", "b"}, func(x string) string { return x }, 2)
+	assert.Equal(t, []string{"a", "a", "a", "b", "b", "c"}, arranged)
+	assert.Equal(t, []int{0, 3, 6}, bounds)
+
+	assert.Panics(t, func() { PartitionByKey[int, int](New(supplier), nil) })
+
+}
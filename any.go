@@ -0,0 +1,47 @@
+package streams
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AsAny returns a stream consisting of the elements of the stream as values of type any, i.e to pass a typed stream to code written against
+// streams of interface{} elements.
+func AsAny[T any](s Stream[T]) Stream[any] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return mapElements(source, func(x T) any { return x })
+}
+
+// FromAny evaluates the stream and returns a stream consisting of its elements asserted to type T. Elements that are not of type T are left out and
+// reported by an error with code IllegalStreamMapping, whose Elements are the elements that could not be converted, nil is returned if all elements
+// were converted. The returned stream keeps the parallelism of the given stream.
+func FromAny[T any](s Stream[any]) (Stream[T], error) {
+	source := s.(*stream[any])
+	elements := source.Collect()
+	converted := make([]T, 0, len(elements))
+	var failed []any
+	for _, x := range elements {
+		if typed, ok := x.(T); ok {
+			converted = append(converted, typed)
+		} else {
+			failed = append(failed, x)
+		}
+	}
+
+	result := &stream[T]{
+		supplier:   func() []T { return converted },
+		operations: make([]operator[T], 0),
+		parallel:   source.parallel,
+		origin:     source.origin,
+		executor:   source.executor,
+	}
+	if len(failed) > 0 {
+		err := errIllegalStreamMapping(fmt.Sprint(reflect.TypeOf((*T)(nil)).Elem()))
+		err.elements = failed
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package streams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsAny(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3} }
+
+	assert.Equal(t, []any{1, 2, 3}, AsAny(New(supplier)).Collect())
+	assert.ElementsMatch(t, []any{2, 3}, AsAny(New(supplier).Parallelize(2).Filter(func(x int) bool { return x > 1 })).Collect())
+
+}
+
+func TestFromAny(t *testing.T) {
+
+	type fromAnyTest struct {
+		s        Stream[any]
+		expected []int
+		failed   []any
+	}
+
+	fromAnyTests := []fromAnyTest{
+		{s: New(func() []any { return []any{1, 2, 3} }), expected: []int{1, 2, 3}},
+		{s: New(func() []any { return []any{1, "2", 3, nil, 4.0} }), expected: []int{1, 3}, failed: []any{"2", nil, 4.0}},
+		{s: New(func() []any { return []any{1, "2", 3} }).Parallelize(2), expected: []int{1, 3}, failed: []any{"2"}},
+		{s: AsAny(New(func() []int { return []int{4, 5} })), expected: []int{4, 5}},
+	}
+
+	for _, test := range fromAnyTests {
+		s, err := FromAny[int](test.s)
+		assert.ElementsMatch(t, test.expected, s.Collect())
+		assert.Equal(t, test.s.Parallel(), s.Parallel())
+		if test.failed == nil {
+			assert.Nil(t, err)
+			continue
+		}
+		assert.Equal(t, IllegalStreamMapping, err.(*streamError).Code())
+		assert.Equal(t, "ErrIllegalStreamMapping: The given stream cannot be mapped to int.", err.Error())
+		assert.ElementsMatch(t, test.failed, err.(*streamError).Elements())
+	}
+
+	// Interface types are asserted by implementation.
+	errs, err := FromAny[error](New(func() []any { return []any{assert.AnError, "error"} }))
+	assert.Equal(t, []error{assert.AnError}, errs.Collect())
+	assert.Equal(t, "ErrIllegalStreamMapping: The given stream cannot be mapped to error.", err.Error())
+
+}
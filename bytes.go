@@ -0,0 +1,84 @@
+package streams
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	mapBytesOperatorName        = "MAP_BYTES"
+	mapBytesInPlaceOperatorName = "MAP_BYTES_IN_PLACE"
+)
+
+// BufferPool a pool of byte buffers that is safe for concurrent use, used to avoid an allocation per element when transforming streams of bytes.
+type BufferPool struct {
+	size    int
+	pool    sync.Pool
+	headers sync.Pool // Pointers whose buffers have been taken by Get, reused by Put so that returning a buffer does not allocate.
+}
+
+// NewBufferPool creates a pool whose new buffers have the given initial capacity.
+func NewBufferPool(size int) *BufferPool {
+	if size < 0 {
+		panic(errIllegalArgument("NewBufferPool", fmt.Sprint(size)))
+	}
+	p := &BufferPool{size: size}
+	p.pool.New = func() any {
+		buffer := make([]byte, 0, p.size)
+		return &buffer
+	}
+	return p
+}
+
+// Get returns an empty buffer from the pool, allocating one if the pool is empty.
+func (p *BufferPool) Get() []byte {
+	header := p.pool.Get().(*[]byte)
+	buffer := (*header)[:0]
+	*header = nil
+	p.headers.Put(header)
+	return buffer
+}
+
+// Put returns a buffer to the pool, the buffer must not be used after. The pointer holding the buffer in the pool is one released by Get, so Put
+// only allocates when more buffers are put than have been taken.
+func (p *BufferPool) Put(buffer []byte) {
+	header, ok := p.headers.Get().(*[]byte)
+	if !ok {
+		header = &[]byte{}
+	}
+	*header = buffer
+	p.pool.Put(header)
+}
+
+// MapBytes returns a stream consisting of the results of applying the given function to the elements of the stream. The function appends the
+// transformed element to dst, a buffer taken from the given pool, and returns the result. Elements of the returned stream can be given back to
+// the pool once they are no longer used.
+func MapBytes(s Stream[[]byte], p *BufferPool, f func(dst, src []byte) []byte) Stream[[]byte] {
+	source := s.(*stream[[]byte])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return new(source, operator[[]byte]{
+		apply: func(x []byte) ([]byte, bool) {
+			return f(p.Get(), x), true
+		},
+		name:      mapBytesOperatorName,
+		undefined: f == nil || p == nil,
+	})
+}
+
+// MapBytesInPlace returns a stream consisting of the results of applying the given function to the elements of the stream. The function may modify
+// the given element and return it (or a slice of it) so that no allocation is made, the elements supplied to the stream are therefore modified.
+func MapBytesInPlace(s Stream[[]byte], f func(b []byte) []byte) Stream[[]byte] {
+	source := s.(*stream[[]byte])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return new(source, operator[[]byte]{
+		apply: func(x []byte) ([]byte, bool) {
+			return f(x), true
+		},
+		name:      mapBytesInPlaceOperatorName,
+		undefined: f == nil,
+	})
+}
@@ -0,0 +1,86 @@
+package streams
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferPool(t *testing.T) {
+
+	pool := NewBufferPool(16)
+	buffer := pool.Get()
+	assert.Equal(t, 0, len(buffer))
+	assert.GreaterOrEqual(t, cap(buffer), 16)
+
+	pool.Put(append(buffer, "abc"...))
+	assert.Equal(t, 0, len(pool.Get()))
+
+	// Buffers keep their contents until they are taken again.
+	for i := 0; i < 10; i++ {
+		buffer := append(pool.Get(), "abc"...)
+		assert.Equal(t, "abc", string(buffer))
+		pool.Put(buffer)
+	}
+	pool.Put(make([]byte, 5, 32))
+	assert.Equal(t, 0, len(pool.Get()))
+
+	assert.Panics(t, func() { NewBufferPool(-1) })
+
+}
+
+func TestMapBytes(t *testing.T) {
+
+	supplier := func() [][]byte { return [][]byte{[]byte("a"), []byte("bb"), []byte("ccc"), []byte("dddd")} }
+	upper := func(dst, src []byte) []byte { return append(dst, bytes.ToUpper(src)...) }
+
+	type mapBytesTest struct {
+		s        Stream[[]byte]
+		expected []string
+	}
+
+	mapBytesTests := []mapBytesTest{
+		{s: New(supplier), expected: []string{"A", "BB", "CCC", "DDDD"}},
+		{s: New(supplier).Parallelize(2), expected: []string{"A", "BB", "CCC", "DDDD"}},
+	}
+
+	for _, test := range mapBytesTests {
+		pool := NewBufferPool(8)
+		var mux sync.Mutex
+		results := make([]string, 0)
+		MapBytes(test.s, pool, upper).ForEach(func(b []byte) {
+			defer pool.Put(b)
+			mux.Lock()
+			defer mux.Unlock()
+			results = append(results, string(b))
+		})
+		assert.ElementsMatch(t, test.expected, results)
+	}
+
+	assert.NotNil(t, MapBytes(New(supplier), nil, upper).DryRun())
+	assert.NotNil(t, MapBytes(New(supplier), NewBufferPool(0), nil).DryRun())
+
+}
+
+func TestMapBytesInPlace(t *testing.T) {
+
+	data := [][]byte{[]byte(" a "), []byte(" bb"), []byte("ccc ")}
+	trim := func(b []byte) []byte { return bytes.TrimSpace(b) }
+
+	results := MapBytesInPlace(New(func() [][]byte { return data }), trim).Collect()
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")}, results)
+
+	swap := func(b []byte) []byte {
+		for i := range b {
+			b[i] ^= 0x20
+		}
+		return b
+	}
+	data = [][]byte{[]byte("ab"), []byte("cd")}
+	results = MapBytesInPlace(New(func() [][]byte { return data }).Parallelize(2), swap).Collect()
+	assert.ElementsMatch(t, [][]byte{[]byte("AB"), []byte("CD")}, results)
+	assert.Equal(t, []byte("AB"), data[0])
+
+}
@@ -0,0 +1,62 @@
+package streams
+
+import (
+	"fmt"
+	"sync"
+)
+
+// capture records the source elements that cause a panic when the operations of a stream are applied to them.
+type capture[T any] struct {
+	max      int
+	mux      sync.Mutex
+	elements []any
+	panics   []any
+}
+
+// captured the panic raised by the function of an operation wrapped by a capture, it is recovered once the operations have been applied to an element
+// so that the source element can be recorded.
+type captured struct {
+	panic  any
+	record func(x any, r any)
+}
+
+// wrap wraps each of the given operations so that a panic raised by its function is raised again as a captured panic, the element is then recorded
+// and dropped from the stream by applyOperations. Errors raised by the package (i.e the failure of TryMap) are raised unchanged.
+func (c *capture[T]) wrap(operations []operator[T]) []operator[T] {
+	wrapped := make([]operator[T], len(operations))
+	for i := range operations {
+		apply := operations[i].apply
+		wrapped[i] = operations[i]
+		wrapped[i].apply = func(x T) (T, bool) {
+			defer func() {
+				if r := recover(); r != nil {
+					if _, ok := r.(Error); ok {
+						panic(r)
+					}
+					panic(captured{panic: r, record: c.record})
+				}
+			}()
+			return apply(x)
+		}
+	}
+	return wrapped
+}
+
+// record records the element and the panic it caused, at most max elements are kept.
+func (c *capture[T]) record(x any, r any) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(c.elements) < c.max {
+		c.elements = append(c.elements, x)
+		c.panics = append(c.panics, r)
+	}
+}
+
+// raise panics with an error carrying the captured elements if any element caused a panic.
+func (c *capture[T]) raise() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(c.elements) > 0 {
+		panic(errElementPanic(fmt.Sprint(c.panics[0]), c.elements))
+	}
+}
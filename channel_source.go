@@ -0,0 +1,96 @@
+package streams
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Source a handle on the source of a stream created from a channel, used to coordinate the shutdown of long running pipelines.
+type Source struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	doneOnce sync.Once
+	pulling  int32
+}
+
+// channelPageSize the maximum number of elements pulled from a channel at a time.
+const channelPageSize = 256
+
+// FromChannel creates a new stream whose elements are received from the given channel, i.e the channel of a time.Ticker. Elements are pulled in pages
+// until the channel is closed or the returned source is shut down, a page waits for an element and holds the elements that have already arrived after
+// it. The next page is only pulled once the previous one has been evaluated and only if the operations of the stream can still pass elements, so that
+// ForEach, ForEachWhile and ToChannel process elements as they arrive and a Limit stops pulling from an unbounded channel. Terminal operations that
+// need all elements (i.e Collect) return once the channel is closed or the source is shut down.
+func FromChannel[T any](channel <-chan T) (Stream[T], *Source) {
+	source := &Source{stop: make(chan struct{}), done: make(chan struct{})}
+	a := paged(func() ([]T, bool) {
+		atomic.StoreInt32(&source.pulling, 1)
+		return receive(source, channel)
+	})
+	return &stream[T]{
+		supplier:   a.supply,
+		operations: make([]operator[T], 0),
+		origin:     channelSourceName,
+		release: func(early bool) {
+			if early {
+				source.Stop()
+			}
+			source.doneOnce.Do(func() { close(source.done) })
+		},
+		interrupt: source.Stop,
+		appended:  a,
+	}, source
+}
+
+// receive returns the next page of elements from the channel, it waits for an element and takes the elements that have already arrived after it up to
+// channelPageSize. The indication returned is false once the channel is closed or the source is stopped.
+func receive[T any](source *Source, channel <-chan T) ([]T, bool) {
+	data := make([]T, 0)
+	select {
+	case <-source.stop:
+		return data, false
+	case x, ok := <-channel:
+		if !ok {
+			return data, false
+		}
+		data = append(data, x)
+	}
+	for len(data) < channelPageSize {
+		select {
+		case <-source.stop:
+			return data, false
+		case x, ok := <-channel:
+			if !ok {
+				return data, false
+			}
+			data = append(data, x)
+		default:
+			return data, true
+		}
+	}
+	return data, true
+}
+
+// Stop stops pulling elements from the channel without waiting for the terminal operation of the stream, it is called once a terminal operation
+// stops before consuming all elements of the stream (see Stoppable).
+func (source *Source) Stop() {
+	source.stopOnce.Do(func() { close(source.stop) })
+}
+
+// Shutdown stops pulling elements from the channel, the elements that have already been pulled are still passed through the pipeline. Shutdown
+// waits for the terminal operation of the stream to return, or the given context to be done in which case the error of the context is returned.
+// If elements are not being pulled yet Shutdown returns immediately and the stream will be evaluated without any elements.
+func (source *Source) Shutdown(ctx context.Context) error {
+	source.Stop()
+	if atomic.LoadInt32(&source.pulling) == 0 {
+		return nil
+	}
+	select {
+	case <-source.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
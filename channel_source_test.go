@@ -0,0 +1,122 @@
+package streams
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromChannel(t *testing.T) {
+
+	type fromChannelTest struct {
+		parallelize bool
+		expected    []int
+	}
+
+	fromChannelTests := []fromChannelTest{
+		{parallelize: false, expected: []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}},
+		{parallelize: true, expected: []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}},
+	}
+
+	for _, test := range fromChannelTests {
+		channel := make(chan int)
+		s, source := FromChannel(channel)
+		s = s.Map(func(x int) int { return 2 * x })
+		if test.parallelize {
+			s = s.Parallelize(2)
+		}
+
+		results := make(chan []int, 1)
+		go func() { results <- s.Collect() }()
+		for i := 0; i < 10; i++ {
+			channel <- i
+		}
+
+		assert.Nil(t, source.Shutdown(context.Background()))
+		assert.ElementsMatch(t, test.expected, <-results)
+		assert.True(t, s.Terminated())
+	}
+
+	// Closing the channel ends the stream.
+	channel := make(chan int, 3)
+	channel <- 1
+	channel <- 2
+	channel <- 3
+	close(channel)
+	s, source := FromChannel(channel)
+	assert.Equal(t, 3, s.Count())
+	assert.Nil(t, source.Shutdown(context.Background()))
+
+	// Elements are processed as they arrive.
+	channel = make(chan int)
+	s, source = FromChannel(channel)
+	seen := make(chan int)
+	go s.Map(func(x int) int { return 2 * x }).ForEach(func(x int) { seen <- x })
+	for i := 0; i < 3; i++ {
+		channel <- i
+		assert.Equal(t, 2*i, <-seen)
+	}
+	assert.Nil(t, source.Shutdown(context.Background()))
+
+	// A Limit stops pulling from an unbounded channel.
+	for _, parallel := range []bool{false, true} {
+		unbounded := make(chan int)
+		stopped := make(chan struct{})
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case unbounded <- i:
+				case <-stopped:
+					return
+				}
+			}
+		}()
+		s, source = FromChannel(unbounded)
+		if parallel {
+			s = s.Parallelize(2)
+		}
+		assert.Len(t, s.Filter(func(x int) bool { return x%2 == 0 }).Limit(5).Collect(), 5)
+		assert.Nil(t, source.Shutdown(context.Background()))
+		close(stopped)
+	}
+
+	// A long chain of pages is evaluated without nesting.
+	pages := make(chan int)
+	go func() {
+		for i := 0; i < 10000; i++ {
+			pages <- i
+		}
+		close(pages)
+	}()
+	s, _ = FromChannel(pages)
+	assert.Equal(t, 10000, s.Count())
+
+	// Streams that are never evaluated shut down immediately.
+	_, source = FromChannel(make(chan int))
+	assert.Nil(t, source.Shutdown(context.Background()))
+
+}
+
+func TestFromChannelShutdownTimeout(t *testing.T) {
+
+	channel := make(chan int)
+	s, source := FromChannel(channel)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.ForEach(func(x int) { <-release })
+		close(done)
+	}()
+	channel <- 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, source.Shutdown(ctx))
+
+	close(release)
+	<-done
+	assert.Nil(t, source.Shutdown(context.Background()))
+
+}
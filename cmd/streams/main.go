@@ -0,0 +1,273 @@
+// Command streams transforms JSON lines, CSV or plain text read from stdin or a file using record streams and writes the results to stdout, i.e
+//
+//	streams -in people.csv -from csv -filter "age > 18 && country == 'ZA'" -map "decade=age - age % 10" -select name,decade -to jsonl
+//	streams -in people.jsonl -group country
+//
+// Plain text lines are read as records with a single line field. Values of CSV fields that are numbers are read as numbers so that they can be
+// used in expressions. Grouping outputs one record per group holding the key and the number of records in the group.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phantom820/streams"
+)
+
+// Formats of input and output.
+const (
+	jsonlFormat = "jsonl"
+	csvFormat   = "csv"
+	linesFormat = "lines"
+	lineField   = "line"
+	countField  = "count"
+)
+
+// mappings values of a repeatable -map flag.
+type mappings []string
+
+func (m *mappings) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *mappings) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
+
+// options the parsed command line flags.
+type options struct {
+	in       string
+	from     string
+	to       string
+	filter   string
+	maps     mappings
+	fields   string
+	group    string
+	skip     int
+	limit    int
+	parallel int
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command with the given arguments, reading records from stdin unless a file is given and writing the results to stdout.
+func run(args []string, stdin io.Reader, stdout io.Writer) (err error) {
+	var opts options
+	flags := flag.NewFlagSet("streams", flag.ContinueOnError)
+	flags.StringVar(&opts.in, "in", "", "input file, defaults to stdin")
+	flags.StringVar(&opts.from, "from", jsonlFormat, "input format: jsonl, csv or lines")
+	flags.StringVar(&opts.to, "to", jsonlFormat, "output format: jsonl, csv or lines")
+	flags.StringVar(&opts.filter, "filter", "", "expression records must satisfy")
+	flags.Var(&opts.maps, "map", "field=expression to set on records, may be repeated")
+	flags.StringVar(&opts.fields, "select", "", "comma separated fields to keep")
+	flags.StringVar(&opts.group, "group", "", "field to group and count records by")
+	flags.IntVar(&opts.skip, "skip", 0, "number of records to skip")
+	flags.IntVar(&opts.limit, "limit", -1, "maximum number of records to output")
+	flags.IntVar(&opts.parallel, "parallel", 1, "level of parallelism")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if opts.in != "" {
+		file, err := os.Open(opts.in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		stdin = file
+	}
+	records, err := read(stdin, opts.from)
+	if err != nil {
+		return err
+	}
+
+	// Operations on record streams report illegal arguments and expressions by panicking.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if e, ok := recovered.(error); ok {
+				err = e
+				return
+			}
+			panic(recovered)
+		}
+	}()
+	results, err := transform(records, opts)
+	if err != nil {
+		return err
+	}
+	return write(stdout, results, opts.to, opts.fields)
+}
+
+// transform applies the operations given by the options to the records.
+func transform(records []streams.Record, opts options) ([]streams.Record, error) {
+	s := streams.NewRecordStream(func() []streams.Record { return records })
+	if opts.parallel > 1 {
+		s = s.Parallelize(opts.parallel)
+	}
+	if opts.filter != "" {
+		s = s.FilterExpr(opts.filter)
+	}
+	for _, m := range opts.maps {
+		field, expression, ok := strings.Cut(m, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("streams: illegal map %q, expected field=expression", m)
+		}
+		s = s.MapExpr(strings.TrimSpace(field), expression)
+	}
+	if opts.skip > 0 {
+		s = s.Skip(opts.skip)
+	}
+
+	if opts.group != "" {
+		counts := s.Stream().GroupBy(func(x streams.Record) string { return fmt.Sprint(x[opts.group]) }).Count()
+		keys := make([]string, 0, len(counts))
+		for key := range counts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		results := make([]streams.Record, 0, len(keys))
+		for _, key := range keys {
+			results = append(results, streams.Record{opts.group: key, countField: counts[key]})
+		}
+		if opts.limit >= 0 && opts.limit < len(results) {
+			results = results[:opts.limit]
+		}
+		return results, nil
+	}
+
+	if opts.limit >= 0 {
+		s = s.Limit(opts.limit)
+	}
+	if opts.fields != "" {
+		s = s.Select(strings.Split(opts.fields, ",")...)
+	}
+	return s.Collect(), nil
+}
+
+// read reads records in the given format.
+func read(r io.Reader, format string) ([]streams.Record, error) {
+	records := make([]streams.Record, 0)
+	switch format {
+	case jsonlFormat, linesFormat:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if format == linesFormat {
+				records = append(records, streams.Record{lineField: line})
+				continue
+			} else if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var record streams.Record
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				return nil, fmt.Errorf("streams: line %d: %w", len(records)+1, err)
+			}
+			records = append(records, record)
+		}
+		return records, scanner.Err()
+	case csvFormat:
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil || len(rows) == 0 {
+			return records, err
+		}
+		header := rows[0]
+		for _, row := range rows[1:] {
+			record := make(streams.Record, len(header))
+			for i, field := range header {
+				if n, err := strconv.ParseFloat(row[i], 64); err == nil {
+					record[field] = n
+				} else {
+					record[field] = row[i]
+				}
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("streams: unknown input format %q", format)
+}
+
+// write writes the records in the given format, CSV columns are the selected fields or otherwise all fields in sorted order.
+func write(w io.Writer, records []streams.Record, format string, fields string) error {
+	out := bufio.NewWriter(w)
+	switch format {
+	case jsonlFormat:
+		for _, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			out.Write(data)
+			out.WriteByte('\n')
+		}
+	case linesFormat:
+		header := columns(records, fields)
+		for _, record := range records {
+			if line, ok := record[lineField]; ok && len(record) == 1 {
+				fmt.Fprintln(out, line)
+				continue
+			}
+			values := make([]string, 0, len(record))
+			for _, field := range header {
+				if val, ok := record[field]; ok {
+					values = append(values, fmt.Sprint(val))
+				}
+			}
+			fmt.Fprintln(out, strings.Join(values, " "))
+		}
+	case csvFormat:
+		header := columns(records, fields)
+		writer := csv.NewWriter(out)
+		writer.Write(header)
+		for _, record := range records {
+			row := make([]string, len(header))
+			for i, field := range header {
+				if val, ok := record[field]; ok {
+					row[i] = fmt.Sprint(val)
+				}
+			}
+			writer.Write(row)
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("streams: unknown output format %q", format)
+	}
+	return out.Flush()
+}
+
+// columns returns the selected fields, or all fields of the records in sorted order if none are selected.
+func columns(records []streams.Record, fields string) []string {
+	if fields != "" {
+		return strings.Split(fields, ",")
+	}
+	set := make(map[string]struct{})
+	for _, record := range records {
+		for field := range record {
+			set[field] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(set))
+	for field := range set {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+	return result
+}
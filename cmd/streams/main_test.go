@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const people = `name,age,country
+a,20,ZA
+b,17,ZA
+c,31,US
+d,45,ZA
+`
+
+func TestRun(t *testing.T) {
+
+	type runTest struct {
+		args     []string
+		input    string
+		expected string
+	}
+
+	runTests := []runTest{
+		{args: []string{"-from", "csv", "-filter", "age > 18 && country == 'ZA'", "-select", "name"},
+			input: people, expected: "{\"name\":\"a\"}\n{\"name\":\"d\"}\n"},
+		{args: []string{"-from", "csv", "-map", "decade=age - age % 10", "-select", "name,decade", "-to", "csv", "-limit", "2"},
+			input: people, expected: "name,decade\na,20\nb,10\n"},
+		{args: []string{"-from", "csv", "-group", "country", "-parallel", "2"},
+			input: people, expected: "{\"count\":1,\"country\":\"US\"}\n{\"count\":3,\"country\":\"ZA\"}\n"},
+		{args: []string{"-from", "lines", "-to", "lines", "-skip", "1", "-filter", "line != ''"},
+			input: "first\n\nsecond\nthird\n", expected: "second\nthird\n"},
+		{args: []string{"-filter", "n >= 2", "-to", "lines"},
+			input: "{\"n\": 1}\n\n{\"n\": 2}\n{\"n\": 3}\n", expected: "2\n3\n"},
+	}
+
+	for _, test := range runTests {
+		var out bytes.Buffer
+		assert.Nil(t, run(test.args, strings.NewReader(test.input), &out))
+		assert.Equal(t, test.expected, out.String(), test.args)
+	}
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	assert.Nil(t, os.WriteFile(path, []byte(people), 0644))
+	var out bytes.Buffer
+	assert.Nil(t, run([]string{"-in", path, "-from", "csv", "-limit", "1", "-to", "csv"}, strings.NewReader(""), &out))
+	assert.Equal(t, "age,country,name\n20,ZA,a\n", out.String())
+
+}
+
+func TestRunErr(t *testing.T) {
+
+	for _, args := range [][]string{
+		{"-from", "xml"},
+		{"-to", "xml"},
+		{"-filter", "n >"},
+		{"-map", "n"},
+		{"-in", filepath.Join(t.TempDir(), "missing")},
+		{"-unknown"},
+	} {
+		var out bytes.Buffer
+		assert.NotNil(t, run(args, strings.NewReader("{\"n\": 1}\n"), &out), args)
+	}
+
+}
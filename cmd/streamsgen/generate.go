@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// Operation kinds supported in a pipeline spec.
+const (
+	filterKind  = "filter"
+	mapKind     = "map"
+	peekKind    = "peek"
+	limitKind   = "limit"
+	skipKind    = "skip"
+	collectKind = "collect"
+	countKind   = "count"
+	forEachKind = "foreach"
+	reduceKind  = "reduce"
+)
+
+// Op an operation of a pipeline, the argument is the name of a function in the target package or a count for limit and skip.
+type Op struct {
+	Kind string
+	Arg  string
+}
+
+// Spec describes a fused pipeline function to generate.
+type Spec struct {
+	Package  string // Package of the generated file.
+	Name     string // Name of the generated function.
+	Type     string // Element type of the pipeline.
+	Ops      []Op   // Intermediate operations in order.
+	Terminal Op     // Terminal operation.
+}
+
+// parseOps parses a comma separated list of operations of the form kind:arg, i.e "filter:isEven,map:square,limit:10".
+func parseOps(s string) ([]Op, error) {
+	ops := make([]Op, 0)
+	if strings.TrimSpace(s) == "" {
+		return ops, nil
+	}
+	for _, field := range strings.Split(s, ",") {
+		op, err := parseOp(field)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// parseOp parses an operation of the form kind:arg, the argument is optional for terminal operations that do not take one.
+func parseOp(s string) (Op, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(s), ":")
+	op := Op{Kind: strings.ToLower(kind), Arg: arg}
+	switch op.Kind {
+	case filterKind, mapKind, peekKind, forEachKind, reduceKind:
+		if arg == "" {
+			return Op{}, fmt.Errorf("streamsgen: operation %q requires a function name", s)
+		}
+	case limitKind, skipKind:
+		if n, err := strconv.Atoi(arg); err != nil || n < 0 {
+			return Op{}, fmt.Errorf("streamsgen: operation %q requires a non negative count", s)
+		}
+	case collectKind, countKind:
+	default:
+		return Op{}, fmt.Errorf("streamsgen: unknown operation %q", s)
+	}
+	return op, nil
+}
+
+// Generate returns the formatted source of a file containing the fused pipeline function described by the spec. The function applies the operations
+// in a single loop over its input without closures or intermediate slices.
+func Generate(spec Spec) ([]byte, error) {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "// Code generated by streamsgen. DO NOT EDIT.\n\npackage %s\n\n", spec.Package)
+
+	var signature, init, emit, result string
+	switch spec.Terminal.Kind {
+	case collectKind:
+		signature, init = "[]"+spec.Type, "result := make([]"+spec.Type+", 0, len(data))"
+		emit, result = "result = append(result, x)", "return result"
+	case countKind:
+		signature, init, emit, result = "int", "result := 0", "result++", "return result"
+	case forEachKind:
+		emit = spec.Terminal.Arg + "(x)"
+	case reduceKind:
+		signature, init = "("+spec.Type+", bool)", "var result "+spec.Type+"\nok := false"
+		emit = "if ok {\nresult = " + spec.Terminal.Arg + "(result, x)\n} else {\nresult, ok = x, true\n}"
+		result = "return result, ok"
+	default:
+		return nil, fmt.Errorf("streamsgen: %q is not a terminal operation", spec.Terminal.Kind)
+	}
+
+	fmt.Fprintf(&buffer, "// %s is a fused pipeline generated from %s.\n", spec.Name, describe(spec))
+	fmt.Fprintf(&buffer, "func %s(data []%s) %s {\n", spec.Name, spec.Type, signature)
+	if init != "" {
+		fmt.Fprintln(&buffer, init)
+	}
+	for i, op := range spec.Ops {
+		if op.Kind == limitKind || op.Kind == skipKind {
+			fmt.Fprintf(&buffer, "%s%d := 0\n", op.Kind, i)
+		}
+	}
+	fmt.Fprintln(&buffer, "for _, x := range data {")
+	for i, op := range spec.Ops {
+		switch op.Kind {
+		case filterKind:
+			fmt.Fprintf(&buffer, "if !%s(x) {\ncontinue\n}\n", op.Arg)
+		case mapKind:
+			fmt.Fprintf(&buffer, "x = %s(x)\n", op.Arg)
+		case peekKind:
+			fmt.Fprintf(&buffer, "%s(x)\n", op.Arg)
+		case limitKind:
+			fmt.Fprintf(&buffer, "if limit%d == %s {\nbreak\n}\nlimit%d++\n", i, op.Arg, i)
+		case skipKind:
+			fmt.Fprintf(&buffer, "if skip%d < %s {\nskip%d++\ncontinue\n}\n", i, op.Arg, i)
+		default:
+			return nil, fmt.Errorf("streamsgen: %q is not an intermediate operation", op.Kind)
+		}
+	}
+	fmt.Fprintln(&buffer, emit)
+	fmt.Fprintln(&buffer, "}")
+	if result != "" {
+		fmt.Fprintln(&buffer, result)
+	}
+	fmt.Fprintln(&buffer, "}")
+	return format.Source(buffer.Bytes())
+}
+
+// describe returns the operations of the spec in the form they are given on the command line.
+func describe(spec Spec) string {
+	ops := make([]string, 0, len(spec.Ops)+1)
+	for _, op := range spec.Ops {
+		ops = append(ops, op.Kind+":"+op.Arg)
+	}
+	if spec.Terminal.Arg == "" {
+		ops = append(ops, spec.Terminal.Kind)
+	} else {
+		ops = append(ops, spec.Terminal.Kind+":"+spec.Terminal.Arg)
+	}
+	return strings.Join(ops, ",")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// helpers functions referenced by the generated pipelines in tests.
+const helpers = `package p
+
+func isEven(x int) bool { return x%2 == 0 }
+
+func square(x int) int { return x * x }
+
+func add(x, y int) int { return x + y }
+
+func print(x int) {}
+`
+
+// check type checks the given source along with the helpers.
+func check(source []byte) error {
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, 2)
+	for i, src := range []string{helpers, string(source)} {
+		file, err := parser.ParseFile(fset, string(rune('a'+i))+".go", src, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	_, err := (&types.Config{}).Check("p", fset, files, nil)
+	return err
+}
+
+func TestGenerate(t *testing.T) {
+
+	type generateTest struct {
+		ops      string
+		terminal string
+		expected []string
+	}
+
+	generateTests := []generateTest{
+		{ops: "", terminal: "collect", expected: []string{"func Pipeline(data []int) []int {", "result = append(result, x)"}},
+		{ops: "filter:isEven,map:square", terminal: "count", expected: []string{"func Pipeline(data []int) int {", "if !isEven(x) {", "x = square(x)"}},
+		{ops: "skip:1,limit:2,peek:print", terminal: "foreach:print", expected: []string{"func Pipeline(data []int) {", "if skip0 < 1 {", "if limit1 == 2 {"}},
+		{ops: "filter:isEven", terminal: "reduce:add", expected: []string{"func Pipeline(data []int) (int, bool) {", "result = add(result, x)"}},
+	}
+
+	for _, test := range generateTests {
+		ops, err := parseOps(test.ops)
+		assert.Nil(t, err)
+		terminal, err := parseOp(test.terminal)
+		assert.Nil(t, err)
+
+		source, err := Generate(Spec{Package: "p", Name: "Pipeline", Type: "int", Ops: ops, Terminal: terminal})
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(source), "// Code generated by streamsgen. DO NOT EDIT."))
+		for _, expected := range test.expected {
+			assert.Contains(t, string(source), expected)
+		}
+		assert.Nil(t, check(source))
+	}
+
+}
+
+func TestGenerateErr(t *testing.T) {
+
+	for _, ops := range []string{"filter", "limit:-1", "skip:x", "sort:less"} {
+		_, err := parseOps(ops)
+		assert.NotNil(t, err, ops)
+	}
+
+	_, err := Generate(Spec{Package: "p", Name: "Pipeline", Type: "int", Terminal: Op{Kind: mapKind, Arg: "square"}})
+	assert.NotNil(t, err)
+	_, err = Generate(Spec{Package: "p", Name: "Pipeline", Type: "int", Ops: []Op{{Kind: countKind}}, Terminal: Op{Kind: countKind}})
+	assert.NotNil(t, err)
+	assert.Equal(t, "square_evens", snakeCase("SquareEvens"))
+
+}
@@ -0,0 +1,68 @@
+// Command streamsgen generates monomorphized pipeline functions which fuse a chain of stream operations into a single loop, for hot paths that cannot
+// afford the closure calls of a stream. It is intended to be used with go generate, i.e
+//
+//	//go:generate go run github.com/phantom820/streams/cmd/streamsgen -name=SquareEvens -type=int -ops=filter:isEven,map:square -terminal=collect
+//
+// generates func SquareEvens(data []int) []int in square_evens_gen.go. Operations are given as kind:arg where the kinds are filter, map, peek, limit
+// and skip, the terminal operation is one of collect, count, foreach:f and reduce:f.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	name := flag.String("name", "", "name of the generated function")
+	typ := flag.String("type", "", "element type of the pipeline")
+	ops := flag.String("ops", "", "comma separated intermediate operations")
+	terminal := flag.String("terminal", "collect", "terminal operation")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file, defaults to $GOPACKAGE")
+	output := flag.String("output", "", "output file, defaults to the snake cased name with a _gen.go suffix")
+	flag.Parse()
+
+	if err := run(*name, *typ, *ops, *terminal, *pkg, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run generates the pipeline function described by the flags and writes it to the output file.
+func run(name, typ, ops, terminal, pkg, output string) error {
+	if name == "" || typ == "" || pkg == "" {
+		return fmt.Errorf("streamsgen: -name, -type and -package are required")
+	}
+	spec := Spec{Package: pkg, Name: name, Type: typ}
+	var err error
+	if spec.Ops, err = parseOps(ops); err != nil {
+		return err
+	} else if spec.Terminal, err = parseOp(terminal); err != nil {
+		return err
+	}
+	source, err := Generate(spec)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = snakeCase(name) + "_gen.go"
+	}
+	return os.WriteFile(output, source, 0644)
+}
+
+// snakeCase converts a camel cased name to snake case.
+func snakeCase(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,29 @@
+package streams
+
+import "encoding/json"
+
+// Codec converts elements to and from bytes, used by operations that move elements out of memory.
+type Codec[T any] interface {
+	Encode(x T) ([]byte, error)    // Returns the encoding of the element.
+	Decode(data []byte) (T, error) // Returns the element with the given encoding.
+}
+
+// jsonCodec a codec which encodes elements as JSON.
+type jsonCodec[T any] struct{}
+
+// JSONCodec returns a codec which encodes elements as JSON, only exported fields of structs are preserved.
+func JSONCodec[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+
+// Encode returns the JSON encoding of the element.
+func (jsonCodec[T]) Encode(x T) ([]byte, error) {
+	return json.Marshal(x)
+}
+
+// Decode returns the element with the given JSON encoding.
+func (jsonCodec[T]) Decode(data []byte) (T, error) {
+	var x T
+	err := json.Unmarshal(data, &x)
+	return x, err
+}
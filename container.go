@@ -0,0 +1,76 @@
+package streams
+
+import (
+	"container/heap"
+	"container/list"
+	"container/ring"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// FromList creates a new stream whose elements are the values of the given list from front to back, the list is read when the stream is evaluated.
+// The evaluation panics with an error with code IllegalStreamMapping if a value of the list is not of type T.
+func FromList[T any](l *list.List) Stream[T] {
+	return withSource(New(func() []T {
+		data := make([]T, 0, l.Len())
+		for e := l.Front(); e != nil; e = e.Next() {
+			data = append(data, assertElement[T](e.Value))
+		}
+		return data
+	}), listSourceName)
+}
+
+// FromHeap creates a new stream whose elements are popped from the given heap when the stream is evaluated, so they are in heap order and the heap is
+// empty afterwards. The evaluation panics with an error with code IllegalStreamMapping if an element of the heap is not of type T.
+func FromHeap[T any](h heap.Interface) Stream[T] {
+	return withSource(New(func() []T {
+		data := make([]T, 0, h.Len())
+		for h.Len() > 0 {
+			data = append(data, assertElement[T](heap.Pop(h)))
+		}
+		return data
+	}), heapSourceName)
+}
+
+// Entry a key and value pair of a map.
+type Entry[K any, V any] struct {
+	Key   K
+	Value V
+}
+
+// FromSyncMap creates a new stream whose elements are the entries of the given map, the entries are read when the stream is evaluated. Like Range on
+// the map this is not a consistent snapshot if the map is modified concurrently, each key is read at most once and entries stored or deleted while
+// the map is read may or may not be included.
+func FromSyncMap(m *sync.Map) Stream[Entry[any, any]] {
+	return withSource(New(func() []Entry[any, any] {
+		data := make([]Entry[any, any], 0)
+		m.Range(func(key, value any) bool {
+			data = append(data, Entry[any, any]{Key: key, Value: value})
+			return true
+		})
+		return data
+	}), syncMapSourceName)
+}
+
+// ToRing returns a ring containing the elements from the stream, nil is returned if the stream has no elements.
+func ToRing[T any](s Stream[T]) *ring.Ring {
+	data := s.Collect()
+	r := ring.New(len(data))
+	for i := range data {
+		r.Value = data[i]
+		r = r.Next()
+	}
+	return r
+}
+
+// assertElement returns the given value asserted to type T, it panics if the value is not of type T.
+func assertElement[T any](x any) T {
+	typed, ok := x.(T)
+	if !ok {
+		err := errIllegalStreamMapping(fmt.Sprint(reflect.TypeOf((*T)(nil)).Elem()))
+		err.elements = []any{x}
+		panic(err)
+	}
+	return typed
+}
@@ -0,0 +1,109 @@
+package streams
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// intHeap a min heap of integers implementing heap.Interface.
+type intHeap []int
+
+func (h intHeap) Len() int           { return len(h) }
+func (h intHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h intHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *intHeap) Push(x any)        { *h = append(*h, x.(int)) }
+func (h *intHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func TestFromList(t *testing.T) {
+
+	l := list.New()
+	for _, x := range []int{3, 1, 2} {
+		l.PushBack(x)
+	}
+
+	assert.Equal(t, []int{3, 1, 2}, FromList[int](l).Collect())
+	assert.ElementsMatch(t, []int{2, 4, 6}, FromList[int](l).Parallelize(2).Map(func(x int) int { return x * 2 }).Collect())
+	assert.Equal(t, []int{}, FromList[int](list.New()).Collect())
+
+	// The list is read when the stream is evaluated.
+	s := FromList[int](l)
+	l.PushBack(4)
+	assert.Equal(t, 4, s.Count())
+
+	l.PushBack("5")
+	assert.PanicsWithError(t, "ErrIllegalStreamMapping: The given stream cannot be mapped to int.", func() { FromList[int](l).Collect() })
+
+}
+
+func TestFromHeap(t *testing.T) {
+
+	h := &intHeap{5, 2, 8, 1, 9}
+	heap.Init(h)
+
+	assert.Equal(t, []int{1, 2, 5, 8, 9}, FromHeap[int](h).Collect())
+	assert.Equal(t, 0, h.Len())
+	assert.Equal(t, []int{}, FromHeap[int](h).Collect())
+	assert.Panics(t, func() { FromHeap[string](&intHeap{1}).Collect() })
+
+}
+
+func TestToRing(t *testing.T) {
+
+	r := ToRing(New(func() []int { return []int{1, 2, 3} }))
+	assert.Equal(t, 3, r.Len())
+	values := make([]int, 0)
+	r.Do(func(x any) { values = append(values, x.(int)) })
+	assert.Equal(t, []int{1, 2, 3}, values)
+
+	assert.Nil(t, ToRing(New(func() []int { return []int{} })))
+	assert.Equal(t, 4, ToRing(New(func() []int { return []int{1, 2, 3, 4, 5, 6, 7, 8} }).Parallelize(2).Limit(4)).Len())
+
+}
+
+func TestFromSyncMap(t *testing.T) {
+
+	m := &sync.Map{}
+	m.Store("a", 1)
+	m.Store("b", 2)
+	m.Store("c", 3)
+
+	entries := FromSyncMap(m).Collect()
+	assert.ElementsMatch(t, []Entry[any, any]{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "c", Value: 3}}, entries)
+
+	// The map is read when the stream is evaluated.
+	s := FromSyncMap(m).Parallelize(2).Filter(func(e Entry[any, any]) bool { return e.Value.(int) > 1 })
+	m.Store("d", 4)
+	m.Delete("b")
+	assert.Equal(t, 2, s.Count())
+
+	// The entries are read before the operations are applied, so entries stored by the operations are not included.
+	keys := FromSyncMap(m).Peek(func(e Entry[any, any]) { m.Store(e.Key.(string)+"'", 0) }).Count()
+	assert.Equal(t, 3, keys)
+
+}
+
+func TestToSyncMap(t *testing.T) {
+
+	supplier := func() []string { return []string{"apple", "fig", "kiwi", "banana"} }
+	key := func(x string) any { return len(x) }
+	value := func(x string) any { return x }
+
+	m := New(supplier).ToSyncMap(key, value)
+	assert.ElementsMatch(t, []Entry[any, any]{{Key: 5, Value: "apple"}, {Key: 3, Value: "fig"}, {Key: 4, Value: "kiwi"}, {Key: 6, Value: "banana"}}, FromSyncMap(m).Collect())
+
+	m = New(supplier).Parallelize(2).ToSyncMap(func(x string) any { return x[:1] }, func(x string) any { return len(x) })
+	count := 0
+	m.Range(func(_, _ any) bool { count++; return true })
+	assert.Equal(t, 4, count)
+	assert.Panics(t, func() { New(supplier).ToSyncMap(nil, value) })
+
+}
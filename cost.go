@@ -0,0 +1,60 @@
+package streams
+
+// WithCostEstimator returns a stream consisting of the elements of this stream whose parallel evaluation splits the source into one chunk per routine
+// of roughly equal total cost, as estimated by the given function, instead of an equal number of elements. This balances routines when the time taken
+// to process elements varies by orders of magnitude, negative costs count as 0. It has no effect on a sequential stream or with per element dispatch,
+// parallelizing the returned stream discards the estimator.
+func (s *stream[T]) WithCostEstimator(cost func(x T) int) Stream[T] {
+	if cost == nil {
+		panic(errIllegalArgument("WithCostEstimator", "nil"))
+	}
+	n := 1
+	if s.parallel {
+		n = s.executor.maxRoutines
+	}
+	var bounds []int
+	restricted := s.restrict(func(data []T, _ int) []T {
+		bounds = costBounds(data, cost, n)
+		return data
+	})
+	restricted.executor.bounds = func(length int) []int {
+		if len(bounds) == 0 || bounds[len(bounds)-1] != length {
+			return subIntervals(length, n)
+		}
+		return bounds
+	}
+	if restricted.appended != nil {
+		restricted.appended.executor = restricted.executor
+	}
+	return restricted
+}
+
+// costBounds returns the bounds of n contiguous chunks of the data whose total costs are as close as possible to an equal share of the cost, the
+// share of a chunk is the cost left by the chunks before it divided by the number of chunks left. An element is placed in a chunk if at least half
+// of its cost falls within the share of the chunk, so chunks may be empty if a few elements dominate the cost.
+func costBounds[T any](data []T, cost func(x T) int, n int) []int {
+	costs := make([]int, len(data))
+	total := 0
+	for i := range data {
+		if c := cost(data[i]); c > 0 {
+			costs[i] = c
+			total += c
+		}
+	}
+	if total == 0 {
+		return subIntervals(len(data), n)
+	}
+	bounds := make([]int, 1, n+1)
+	remaining, i := total, 0
+	for k := 0; k < n-1; k++ {
+		// The share of the chunk is the remaining cost divided by the remaining chunks.
+		chunks, chunk := n-k, 0
+		for i < len(data) && (2*chunk+costs[i])*chunks <= 2*remaining {
+			chunk += costs[i]
+			i++
+		}
+		remaining -= chunk
+		bounds = append(bounds, i)
+	}
+	return append(bounds, len(data))
+}
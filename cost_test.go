@@ -0,0 +1,64 @@
+package streams
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostBounds(t *testing.T) {
+
+	identity := func(x int) int { return x }
+
+	type costBoundsTest struct {
+		data     []int
+		n        int
+		expected []int
+	}
+
+	costBoundsTests := []costBoundsTest{
+		{data: []int{1, 1, 1, 1}, n: 2, expected: []int{0, 2, 4}},
+		{data: []int{100, 1, 1, 1}, n: 2, expected: []int{0, 1, 4}},
+		{data: []int{1, 1, 1, 1, 1, 1, 12}, n: 2, expected: []int{0, 6, 7}},
+		{data: []int{0, 0, 0, 0}, n: 2, expected: []int{0, 2, 4}},
+		{data: []int{5, -3, 5}, n: 2, expected: []int{0, 2, 3}},
+		{data: []int{7}, n: 3, expected: []int{0, 0, 1, 1}},
+		{data: []int{3, 3, 3}, n: 1, expected: []int{0, 3}},
+	}
+
+	for _, test := range costBoundsTests {
+		assert.Equal(t, test.expected, costBounds(test.data, identity, test.n))
+	}
+
+}
+
+func TestWithCostEstimator(t *testing.T) {
+
+	// A few expensive elements at the start of the source.
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = 1
+	}
+	data[0], data[1] = 100, 100
+	cost := func(x int) int { return x }
+	sum := func(x, y int) int { return x + y }
+
+	// The elements given to each routine add up to about a quarter of the total cost.
+	var mux sync.Mutex
+	loads := make([]int, 0)
+	s := New(func() []int { return data }).Parallelize(4).WithCostEstimator(cost).(*stream[int])
+	run(s.supplier(), s.executor, func(partition []int) struct{} {
+		mux.Lock()
+		defer mux.Unlock()
+		loads = append(loads, New(func() []int { return partition }).Reduce(sum))
+		return struct{}{}
+	})
+	assert.ElementsMatch(t, []int{100, 100, 49, 49}, loads)
+
+	assert.Equal(t, 298, New(func() []int { return data }).Parallelize(4).WithCostEstimator(cost).Reduce(sum))
+	assert.Equal(t, 98, New(func() []int { return data }).Parallelize(4).WithCostEstimator(cost).Filter(func(x int) bool { return x == 1 }).Count())
+	assert.Equal(t, 298, New(func() []int { return data }).WithCostEstimator(cost).Reduce(sum))
+	assert.Panics(t, func() { New(func() []int { return data }).WithCostEstimator(nil) })
+
+}
@@ -0,0 +1,74 @@
+package streams
+
+import "sync/atomic"
+
+// Cursor evaluates a stream one resulting element at a time, allowing the consumption of several streams to be interleaved (i.e a merge join)
+// without channels. Elements are always evaluated sequentially by the routine calling Next.
+type Cursor[T any] struct {
+	pull       func() ([]T, bool) // Returns the next page of the source and whether more pages may follow.
+	operations []operator[T]
+	done       func()
+	stop       func() // Records that the stream stopped before consuming all elements of the source.
+	data       []T
+	loaded     bool
+	more       bool
+	closed     bool
+	i          int
+}
+
+// Open returns a cursor over the elements of the stream, the source of the stream is only pulled on the first call to Next. The source of a stream
+// created by FromChannel or AppendLazy is pulled a page at a time as the elements are consumed.
+func (s *stream[T]) Open() Cursor[T] {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	operations, done := s.evaluation()
+	pull := func() ([]T, bool) { return s.supply(), false }
+	if s.appended != nil {
+		next := s.appended.pull()
+		pull = func() ([]T, bool) {
+			data, ok := next()
+			s.page(data)
+			return data, ok
+		}
+	}
+	return Cursor[T]{pull: pull, operations: operations, done: done, stop: func() { atomic.StoreInt32(&s.early, 1) }}
+}
+
+// Next advances the stream by exactly one resulting element and returns it, false is returned once the stream has no more elements.
+func (c *Cursor[T]) Next() (T, bool) {
+	var zero T
+	if c.closed {
+		return zero, false
+	}
+	for {
+		for c.i < len(c.data) {
+			x := c.data[c.i]
+			c.i++
+			if val, ok := applyOperations(x, c.operations); ok {
+				return val, true
+			}
+		}
+		if c.loaded && (!c.more || exhausted(c.operations)) {
+			break
+		}
+		c.data, c.more = c.pull()
+		c.loaded, c.i = true, 0
+	}
+	c.Close()
+	return zero, false
+}
+
+// Close releases the cursor, subsequent calls to Next return false. Close is called by Next once the stream has no more elements, closing the cursor
+// before that stops the source of the stream early (see Stoppable).
+func (c *Cursor[T]) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if !c.loaded || c.i < len(c.data) || c.more {
+		c.stop()
+	}
+	c.data = nil
+	c.done()
+}
@@ -0,0 +1,83 @@
+package streams
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// supplierCalls the number of times suppliers given to New have been invoked, only counted in debug builds.
+var supplierCalls int64
+
+// SupplierCalls returns the number of times suppliers given to New have been invoked since the program started. Invocations are only counted in
+// debug builds, i.e built with the streamsdebug tag, so 0 is always returned otherwise. A terminal operation invokes the supplier of its stream
+// exactly once regardless of the operations used to derive the stream, which this count can be used to verify.
+func SupplierCalls() int64 {
+	return atomic.LoadInt64(&supplierCalls)
+}
+
+// countCalls returns a supplier which counts its invocations in debug builds.
+func countCalls[T any](supplier func() []T) func() []T {
+	if !debug || supplier == nil {
+		return supplier
+	}
+	return func() []T {
+		atomic.AddInt64(&supplierCalls, 1)
+		return supplier()
+	}
+}
+
+// leakCheck indicates whether routines started by the package are tracked, see DebugLeakCheck.
+var leakCheck int32
+
+// routines the routines started by the package that are still running, by id, while the leak check is enabled.
+var routines = struct {
+	sync.Mutex
+	next int64
+	live map[int64]string
+}{live: make(map[int64]string)}
+
+// DebugLeakCheck enables or disables tracking of the routines started by the package, i.e the routines evaluating a parallel stream. While enabled
+// LiveRoutines reports the routines that are still running, any routine reported once the terminal operation that started it has returned has
+// outlived it. Tracking adds overhead to starting routines so it is meant for tests, disabling it forgets the tracked routines.
+func DebugLeakCheck(enabled bool) {
+	routines.Lock()
+	defer routines.Unlock()
+	if enabled {
+		atomic.StoreInt32(&leakCheck, 1)
+		return
+	}
+	atomic.StoreInt32(&leakCheck, 0)
+	routines.live = make(map[int64]string)
+}
+
+// LiveRoutines returns the operations whose routines are still running, an operation is listed once for each of its routines. Routines are only
+// tracked while the leak check is enabled, see DebugLeakCheck.
+func LiveRoutines() []string {
+	routines.Lock()
+	defer routines.Unlock()
+	live := make([]string, 0, len(routines.live))
+	for _, operation := range routines.live {
+		live = append(live, operation)
+	}
+	sort.Strings(live)
+	return live
+}
+
+// trackRoutine records a routine started for the given operation if the leak check is enabled, the returned function must be invoked by the routine
+// before it returns.
+func trackRoutine(operation string) func() {
+	if atomic.LoadInt32(&leakCheck) == 0 {
+		return func() {}
+	}
+	routines.Lock()
+	defer routines.Unlock()
+	id := routines.next
+	routines.next++
+	routines.live[id] = operation
+	return func() {
+		routines.Lock()
+		defer routines.Unlock()
+		delete(routines.live, id)
+	}
+}
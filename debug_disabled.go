@@ -0,0 +1,6 @@
+//go:build !streamsdebug
+
+package streams
+
+// debug indicates whether the package was built with the streamsdebug tag.
+const debug = false
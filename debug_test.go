@@ -0,0 +1,56 @@
+package streams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplierCallsDebug(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3} }
+	expected := int64(0)
+	if debug {
+		expected = 2
+	}
+
+	before := SupplierCalls()
+	New(supplier).Parallelize(2).GroupBy(func(x int) string { return "" }).Count()
+	New(supplier).Partition(func(x int) []int { return []int{x} }).FlatMap().Collect()
+	New(supplier).DryRun()
+	assert.Equal(t, expected, SupplierCalls()-before)
+
+}
+
+func TestDebugLeakCheck(t *testing.T) {
+
+	DebugLeakCheck(true)
+	defer DebugLeakCheck(false)
+
+	data := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	New(func() []int { return data }).Parallelize(4).ForEach(func(x int) {})
+	New(func() []int { return data }).ParallelizeWith(IOBound(3)).Count()
+	assert.Panics(t, func() { New(func() []int { return data }).Parallelize(4).ForEach(func(x int) { panic(x) }) })
+	assert.Equal(t, []string{}, LiveRoutines())
+
+	// The routine calling a function that timed out outlives the call.
+	release := make(chan struct{})
+	slow := Timeout(time.Millisecond, func(x int) (int, error) {
+		<-release
+		return x, nil
+	})
+	_, err := slow(1)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"Timeout"}, LiveRoutines())
+	close(release)
+	assert.Eventually(t, func() bool { return len(LiveRoutines()) == 0 }, time.Second, time.Millisecond)
+
+	// Routines are not tracked once the check is disabled.
+	DebugLeakCheck(false)
+	release = make(chan struct{})
+	slow(1)
+	assert.Equal(t, []string{}, LiveRoutines())
+	close(release)
+
+}
@@ -0,0 +1,72 @@
+package streams
+
+import "sync"
+
+// DeltaBy computes the incremental changes between two snapshots of a dataset, elements are matched across snapshots using the given key function
+// and compared using eq. The added stream consists of elements of curr whose key is not in prev, the removed stream consists of elements of prev whose
+// key is not in curr and the changed stream consists of elements of curr whose key is in prev but which are not equal to the element of prev. The
+// given streams are closed and only evaluated once, when the first of the resulting streams is evaluated.
+func DeltaBy[T any, K comparable](prev, curr Stream[T], key func(x T) K, eq func(x, y T) bool) (added, removed, changed Stream[T]) {
+	p, c := prev.(*stream[T]), curr.(*stream[T])
+	if err := p.close(); err != nil {
+		panic(err)
+	} else if err := c.close(); err != nil {
+		panic(err)
+	}
+
+	var once sync.Once
+	var a, r, ch []T
+	evaluate := func() {
+		a, r, ch = delta(elementsSupplier(p)(), elementsSupplier(c)(), key, eq)
+	}
+
+	return &stream[T]{
+		supplier:   func() []T { once.Do(evaluate); return a },
+		operations: make([]operator[T], 0),
+		origin:     deltaSourceName,
+	}, &stream[T]{
+		supplier:   func() []T { once.Do(evaluate); return r },
+		operations: make([]operator[T], 0),
+		origin:     deltaSourceName,
+	}, &stream[T]{
+		supplier:   func() []T { once.Do(evaluate); return ch },
+		operations: make([]operator[T], 0),
+		origin:     deltaSourceName,
+	}
+}
+
+// delta returns the elements added to, removed from and changed in curr relative to prev, in the order in which they appear in their snapshot. Elements
+// that share a key within a snapshot are resolved by keeping the last of them.
+func delta[T any, K comparable](prev, curr []T, key func(x T) K, eq func(x, y T) bool) ([]T, []T, []T) {
+	previous, previousKeys := index(prev, key)
+	current, currentKeys := index(curr, key)
+
+	added, removed, changed := make([]T, 0), make([]T, 0), make([]T, 0)
+	for _, k := range currentKeys {
+		if y, ok := previous[k]; !ok {
+			added = append(added, current[k])
+		} else if !eq(y, current[k]) {
+			changed = append(changed, current[k])
+		}
+	}
+	for _, k := range previousKeys {
+		if _, ok := current[k]; !ok {
+			removed = append(removed, previous[k])
+		}
+	}
+	return added, removed, changed
+}
+
+// index returns the last element for each key along with the keys in order of first occurrence.
+func index[T any, K comparable](data []T, key func(x T) K) (map[K]T, []K) {
+	elements := make(map[K]T, len(data))
+	keys := make([]K, 0, len(data))
+	for _, x := range data {
+		k := key(x)
+		if _, ok := elements[k]; !ok {
+			keys = append(keys, k)
+		}
+		elements[k] = x
+	}
+	return elements, keys
+}
@@ -0,0 +1,52 @@
+package streams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeltaBy(t *testing.T) {
+
+	type row struct {
+		id    int
+		value string
+	}
+
+	type deltaTest struct {
+		prev    []row
+		curr    []row
+		added   []row
+		removed []row
+		changed []row
+	}
+
+	deltaTests := []deltaTest{
+		{prev: []row{}, curr: []row{}, added: []row{}, removed: []row{}, changed: []row{}},
+		{prev: []row{}, curr: []row{{1, "a"}}, added: []row{{1, "a"}}, removed: []row{}, changed: []row{}},
+		{prev: []row{{1, "a"}, {2, "b"}, {3, "c"}}, curr: []row{{1, "a"}, {3, "z"}, {4, "d"}},
+			added: []row{{4, "d"}}, removed: []row{{2, "b"}}, changed: []row{{3, "z"}}},
+	}
+
+	key := func(x row) int { return x.id }
+	eq := func(x, y row) bool { return x == y }
+
+	for _, test := range deltaTests {
+		prev, curr := New(func() []row { return test.prev }), New(func() []row { return test.curr }).Parallelize(2)
+		added, removed, changed := DeltaBy(prev, curr, key, eq)
+		assert.Equal(t, test.added, added.Collect())
+		assert.Equal(t, test.removed, removed.Collect())
+		assert.Equal(t, test.changed, changed.Collect())
+		assert.True(t, prev.Closed())
+		assert.True(t, curr.Closed())
+	}
+
+	// The snapshots are only evaluated once.
+	evaluations := 0
+	prev := New(func() []row { evaluations++; return []row{{1, "a"}} })
+	added, removed, _ := DeltaBy(prev, New(func() []row { return []row{{2, "b"}} }), key, eq)
+	assert.Equal(t, 1, added.Count())
+	assert.Equal(t, 1, removed.Count())
+	assert.Equal(t, 1, evaluations)
+
+}
@@ -2,7 +2,9 @@ package streams
 
 import (
 	"bytes"
+	"fmt"
 	"text/template"
+	"time"
 )
 
 const (
@@ -11,6 +13,16 @@ const (
 	StreamClosed         = 3
 	IllegalConfig        = 4
 	IllegalStreamMapping = 5
+	IllegalPlan          = 6
+	ElementPanic         = 7
+	CapacityExceeded     = 8
+	CircuitOpen          = 9
+	OperationTimeout     = 10
+	IllegalExpression    = 11
+	OperatorPanic        = 12
+	OperationFailed      = 13
+	EmptyStream          = 14
+	UnsortedStream       = 15
 )
 
 var (
@@ -19,12 +31,30 @@ var (
 	streamClosedTemplate, _         = template.New("StreamClosed").Parse("ErrStreamClosed: The stream has been closed.")
 	illegalConfigTemplate, _        = template.New("IllegalConfig").Parse("ErrIllegalStreamConfig: Illegal configuration value {{.value}} for property {{.config}}.")
 	illegalStreamMappingTemplate, _ = template.New("IllegalMapping").Parse("ErrIllegalStreamMapping: The given stream cannot be mapped to {{.type}}.")
+	elementPanicTemplate, _         = template.New("ElementPanic").Parse("ErrElementPanic: {{.count}} element(s) caused a panic, first panic: {{.panic}}.")
+	capacityExceededTemplate, _     = template.New("CapacityExceeded").Parse("ErrCapacityExceeded: The stream produced more than {{.max}} elements.")
+	illegalPlanTemplate, _          = template.New("IllegalPlan").Parse("ErrIllegalPlan: Illegal operation {{.operation}} at position {{.position}}: {{.reason}}.")
+	circuitOpenTemplate, _          = template.New("CircuitOpen").Parse("ErrCircuitOpen: The circuit is open after {{.failures}} consecutive failures, retry after {{.retry}}.")
+	timeoutTemplate, _              = template.New("Timeout").Parse("ErrTimeout: The operation did not complete within {{.timeout}}.")
+	illegalExpressionTemplate, _    = template.New("IllegalExpression").Parse("ErrIllegalExpression: Illegal expression {{.expr}}: {{.reason}}.")
+	operationFailedTemplate, _      = template.New("OperationFailed").Parse("ErrOperationFailed: Operation {{.operation}} failed: {{.err}}.")
+	emptyStreamTemplate, _          = template.New("EmptyStream").Parse("ErrEmptyStream: The stream has no elements for operation {{.operation}}.")
+	operatorPanicTemplate, _        = template.New("OperatorPanic").Parse("ErrOperatorPanic: Operation {{.operation}} at position {{.position}} panicked{{if .element}} on element {{.element}}{{end}}: {{.panic}}.")
+	unsortedStreamTemplate, _       = template.New("UnsortedStream").Parse("ErrUnsortedStream: The key {{.key}} appears in runs that are not adjacent for operation {{.operation}}, the elements must be ordered by their key.")
 )
 
+// Error an error raised by the package, the panics raised for the misuse of a stream (i.e an illegal configuration) carry an Error so that a recovered
+// value can be inspected using its code.
+type Error interface {
+	error
+	Code() int // Returns the code identifying the kind of error, i.e IllegalConfig.
+}
+
 type streamError struct {
-	code int
-	msg  string
-	Err  error
+	code     int
+	msg      string
+	Err      error
+	elements []any
 }
 
 // Code returns the error code for the error.
@@ -37,6 +67,21 @@ func (err streamError) streamError() string {
 	return err.msg
 }
 
+// Error returns the error message.
+func (err streamError) Error() string {
+	return err.msg
+}
+
+// Unwrap returns the error that caused the error, i.e the error a function given to an operation panicked with, nil if there is none.
+func (err streamError) Unwrap() error {
+	return err.Err
+}
+
+// Elements returns the elements of the stream associated with the error, i.e the elements that caused a panic.
+func (err streamError) Elements() []any {
+	return err.elements
+}
+
 // errStreamTerminated returns an error for a  stream that has already been terminated.
 func errStreamTerminated() streamError {
 	var buffer bytes.Buffer
@@ -64,3 +109,123 @@ func errIllegalConfig(config, value string) *streamError {
 	illegalConfigTemplate.Execute(&buffer, map[string]string{"config": config, "value": value})
 	return &streamError{code: IllegalConfig, msg: buffer.String()}
 }
+
+// errIllegalStreamMapping returns an error for a stream whose elements cannot be mapped to the given type.
+func errIllegalStreamMapping(typ string) *streamError {
+	var buffer bytes.Buffer
+	illegalStreamMappingTemplate.Execute(&buffer, map[string]string{"type": typ})
+	return &streamError{code: IllegalStreamMapping, msg: buffer.String()}
+}
+
+// errIllegalPlan returns an error for a stream whose operation at the given position cannot be executed.
+func errIllegalPlan(reason, operation string, position int) *streamError {
+	var buffer bytes.Buffer
+	illegalPlanTemplate.Execute(&buffer, map[string]any{"reason": reason, "operation": operation, "position": position})
+	return &streamError{code: IllegalPlan, msg: buffer.String()}
+}
+
+// errElementPanic returns an error for elements of a stream that caused a panic when operations were applied to them.
+func errElementPanic(cause string, elements []any) *streamError {
+	var buffer bytes.Buffer
+	elementPanicTemplate.Execute(&buffer, map[string]any{"count": len(elements), "panic": cause})
+	return &streamError{code: ElementPanic, msg: buffer.String(), elements: elements}
+}
+
+// errCapacityExceeded returns an error for a stream that produced more elements than the given maximum.
+func errCapacityExceeded(max int) *streamError {
+	var buffer bytes.Buffer
+	capacityExceededTemplate.Execute(&buffer, map[string]int{"max": max})
+	return &streamError{code: CapacityExceeded, msg: buffer.String()}
+}
+
+// errCircuitOpen returns an error for a call rejected by an open circuit breaker.
+func errCircuitOpen(failures int, retry time.Time) *streamError {
+	var buffer bytes.Buffer
+	circuitOpenTemplate.Execute(&buffer, map[string]any{"failures": failures, "retry": retry.Format(time.RFC3339Nano)})
+	return &streamError{code: CircuitOpen, msg: buffer.String()}
+}
+
+// errTimeout returns an error for an operation that did not complete within the given duration.
+func errTimeout(timeout time.Duration) *streamError {
+	var buffer bytes.Buffer
+	timeoutTemplate.Execute(&buffer, map[string]any{"timeout": timeout})
+	return &streamError{code: OperationTimeout, msg: buffer.String()}
+}
+
+// errIllegalExpression returns an error for an expression that cannot be parsed or evaluated.
+func errIllegalExpression(expr, reason string) *streamError {
+	var buffer bytes.Buffer
+	illegalExpressionTemplate.Execute(&buffer, map[string]string{"expr": expr, "reason": reason})
+	return &streamError{code: IllegalExpression, msg: buffer.String()}
+}
+
+// errOperatorPanic returns an error for a panic raised by the operation at the given position, the element the operation was applied to is described
+// only if it implements fmt.Stringer.
+func errOperatorPanic(cause any, operation string, position int, element any) *streamError {
+	var buffer bytes.Buffer
+	description := ""
+	if stringer, ok := element.(fmt.Stringer); ok {
+		description = stringer.String()
+	}
+	operatorPanicTemplate.Execute(&buffer, map[string]any{"operation": operation, "position": position, "element": description,
+		"panic": fmt.Sprint(cause)})
+	err, _ := cause.(error)
+	return &streamError{code: OperatorPanic, msg: buffer.String(), Err: err}
+}
+
+// errOperationFailed returns an error for an operation whose function returned the given error, i.e TryMap.
+func errOperationFailed(operation string, err error) *streamError {
+	var buffer bytes.Buffer
+	operationFailedTemplate.Execute(&buffer, map[string]any{"operation": operation, "err": err})
+	return &streamError{code: OperationFailed, msg: buffer.String(), Err: err}
+}
+
+// errEmptyStream returns an error for an operation that requires elements invoked on a stream without any.
+func errEmptyStream(operation string) *streamError {
+	var buffer bytes.Buffer
+	emptyStreamTemplate.Execute(&buffer, map[string]string{"operation": operation})
+	return &streamError{code: EmptyStream, msg: buffer.String()}
+}
+
+// errUnsortedStream returns an error for an operation that requires the elements to be ordered by their key invoked on a stream whose elements with
+// the given key are not adjacent.
+func errUnsortedStream(operation, key string) *streamError {
+	var buffer bytes.Buffer
+	unsortedStreamTemplate.Execute(&buffer, map[string]string{"operation": operation, "key": key})
+	return &streamError{code: UnsortedStream, msg: buffer.String()}
+}
+
+// catchFailure invokes f and returns the error of an operation that failed while f was evaluating a stream, i.e the error returned by the function
+// of TryMap. Other panics are raised again.
+func catchFailure(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if failure, ok := r.(*streamError); ok && failure.code == OperationFailed {
+				err = failure.Err
+				return
+			}
+			panic(r)
+		}
+	}()
+	f()
+	return nil
+}
+
+// ValidationError an element of a stream that failed validation along with the errors returned by the rules it violated.
+type ValidationError[T any] struct {
+	Element T
+	Errs    []error
+}
+
+// Error returns the error message.
+func (err ValidationError[T]) Error() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("ErrValidation: ")
+	for i, e := range err.Errs {
+		if i > 0 {
+			buffer.WriteString("; ")
+		}
+		buffer.WriteString(e.Error())
+	}
+	return buffer.String()
+}
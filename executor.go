@@ -0,0 +1,210 @@
+package streams
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// executor configuration for evaluating a stream in parallel.
+type executor struct {
+	maxRoutines int
+	perElement  bool                   // Dispatch elements to routines one at a time instead of in contiguous chunks.
+	bounds      func(length int) []int // Returns the bounds of the chunks given to routines for data of the given length, nil to split evenly.
+	scheduler   Scheduler              // Notified at the scheduling points of a parallel evaluation, nil if there is none.
+}
+
+// configure returns the given executor keeping the scheduler of this executor, for streams whose parallelism is changed.
+func (e executor) configure(c executor) executor {
+	c.scheduler = e.scheduler
+	return c
+}
+
+// Scheduler hooks into the scheduling points of the evaluation of a parallel stream. It is meant for tests, a scheduler can block in its hooks to
+// force a specific interleaving of routines and deterministically reproduce race conditions. Partitions are numbered from 0 in the order in which
+// they appear in the source, with per element dispatch each element is a partition.
+type Scheduler interface {
+	BeforePartitionStart(partition int) // Called by the routine evaluating the partition before it starts.
+	AfterPartitionEnd(partition int)    // Called by the routine evaluating the partition after it ends.
+	BeforeMerge()                       // Called once all partitions have ended, before their results are merged.
+}
+
+// CheckParallelism returns an error with code IllegalConfig if the given level of parallelism would be rejected by Parallelize, nil otherwise. It
+// allows a level of parallelism read from configuration to be validated without recovering from the panic raised by Parallelize.
+func CheckParallelism(n int) error {
+	if err := checkParallelism(n); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkParallelism returns an error if the given level of parallelism is not greater than 1.
+func checkParallelism(n int) *streamError {
+	if n <= 1 {
+		return errIllegalConfig("Parallelism", fmt.Sprint(n))
+	}
+	return nil
+}
+
+// Profile an execution profile for a parallel stream, it tunes the number of routines used and how elements are dispatched to them to the type
+// of workload.
+type Profile struct {
+	name     string
+	executor executor
+}
+
+// CPUBound returns a profile for workloads dominated by computation. It uses as many routines as there are CPUs and gives each routine a contiguous
+// chunk of elements.
+func CPUBound() Profile {
+	return Profile{name: "CPUBound", executor: executor{maxRoutines: runtime.NumCPU()}}
+}
+
+// IOBound returns a profile for workloads dominated by waiting, i.e network calls. It allows up to maxInFlight elements to be processed at the
+// same time and dispatches elements to routines one at a time so that a slow element does not hold up a whole chunk.
+func IOBound(maxInFlight int) Profile {
+	if maxInFlight < 1 {
+		panic(errIllegalConfig("IOBound", fmt.Sprint(maxInFlight)))
+	}
+	return Profile{name: "IOBound", executor: executor{maxRoutines: maxInFlight, perElement: true}}
+}
+
+// String returns the name of the profile.
+func (p Profile) String() string {
+	return p.name
+}
+
+// ExecutionInfo describes how a stream is evaluated.
+type ExecutionInfo struct {
+	Parallel   bool // Indicates whether the stream is evaluated by multiple routines.
+	Auto       bool // Indicates whether parallelism is decided when the stream is evaluated, see ParallelizeAuto.
+	Workers    int  // Maximum number of routines used to evaluate the stream, 1 for a sequential stream and the number of CPUs for an auto stream.
+	PerElement bool // Indicates whether elements are dispatched to routines one at a time instead of in contiguous chunks.
+}
+
+// executionInfo returns the execution info of a stream with the given mode and executor.
+func executionInfo(parallel, auto bool, e executor) ExecutionInfo {
+	switch {
+	case auto:
+		return ExecutionInfo{Auto: true, Workers: runtime.NumCPU()}
+	case parallel:
+		return ExecutionInfo{Parallel: true, Workers: e.maxRoutines, PerElement: e.perElement}
+	}
+	return ExecutionInfo{Workers: 1}
+}
+
+// group a set of routines evaluating a stream, like an errgroup whose error is the first panic raised by any of its routines. Once a routine has
+// panicked the group is cancelled so that the other routines stop taking work, Wait raises the panic in the routine waiting for the group.
+type group struct {
+	operation string // Operation the routines are started for, reported by LiveRoutines.
+	wg        sync.WaitGroup
+	once      sync.Once
+	cancelled int32
+	recovered any
+}
+
+// spawn runs f in a new routine of the group.
+func (g *group) spawn(f func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer trackRoutine(g.operation)()
+		defer func() {
+			if r := recover(); r != nil {
+				g.once.Do(func() { g.recovered = r })
+				atomic.StoreInt32(&g.cancelled, 1)
+			}
+		}()
+		f()
+	}()
+}
+
+// done returns an indication of whether the group has been cancelled.
+func (g *group) done() bool {
+	return atomic.LoadInt32(&g.cancelled) == 1
+}
+
+// wait waits for all routines of the group to return, the first panic raised by any of them is raised again.
+func (g *group) wait() {
+	g.wg.Wait()
+	if g.done() {
+		panic(g.recovered)
+	}
+}
+
+// run invokes f on partitions of the data from at most e.maxRoutines routines and returns the results of the partitions in the order in which
+// they complete. By default the data is split into one contiguous chunk per routine, with per element dispatch routines repeatedly take the next
+// unprocessed element until none are left. If f panics for any partition, partitions that have not started are skipped and the panic is raised
+// by run once the routines evaluating the other partitions have returned, so no routine outlives the call.
+func run[T any, R any](data []T, e executor, f func(partition []T) R) []R {
+	if len(data) == 0 {
+		return []R{}
+	} else if e.perElement {
+		return runPerElement(data, e, f)
+	}
+
+	subIntervals := subIntervals(len(data), e.maxRoutines)
+	if e.bounds != nil {
+		subIntervals = e.bounds(len(data))
+	}
+
+	g := group{operation: "ParallelEvaluation"}
+	var mux sync.Mutex
+	results := make([]R, 0, len(subIntervals)-1)
+	for i := 0; i < len(subIntervals)-1; i++ {
+		i, partition := i, data[subIntervals[i]:subIntervals[i+1]]
+		g.spawn(func() {
+			if g.done() {
+				return
+			}
+			result := evaluate(e, i, partition, f)
+			mux.Lock()
+			results = append(results, result)
+			mux.Unlock()
+		})
+	}
+	g.wait()
+	if e.scheduler != nil {
+		e.scheduler.BeforeMerge()
+	}
+	return results
+}
+
+// runPerElement invokes f on each element of the data from at most e.maxRoutines routines.
+func runPerElement[T any, R any](data []T, e executor, f func(partition []T) R) []R {
+	routines := e.maxRoutines
+	if routines > len(data) {
+		routines = len(data)
+	}
+
+	var next int64 = -1
+	var mux sync.Mutex
+	g := group{operation: "ParallelEvaluation"}
+	results := make([]R, 0, len(data))
+
+	for i := 0; i < routines; i++ {
+		g.spawn(func() {
+			for j := int(atomic.AddInt64(&next, 1)); j < len(data) && !g.done(); j = int(atomic.AddInt64(&next, 1)) {
+				result := evaluate(e, j, data[j:j+1], f)
+				mux.Lock()
+				results = append(results, result)
+				mux.Unlock()
+			}
+		})
+	}
+	g.wait()
+	if e.scheduler != nil {
+		e.scheduler.BeforeMerge()
+	}
+	return results
+}
+
+// evaluate invokes f on the given partition notifying the scheduler of the executor, if any, before and after.
+func evaluate[T any, R any](e executor, i int, partition []T, f func(partition []T) R) R {
+	if e.scheduler == nil {
+		return f(partition)
+	}
+	e.scheduler.BeforePartitionStart(i)
+	defer e.scheduler.AfterPartitionEnd(i)
+	return f(partition)
+}
@@ -0,0 +1,355 @@
+package streams
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expr a parsed expression over the fields of a record.
+type expr interface {
+	eval(r Record) any
+}
+
+// Nodes of an expression.
+type (
+	literalExpr struct{ value any }
+	fieldExpr   struct{ name string }
+	unaryExpr   struct {
+		op      string
+		operand expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+)
+
+// exprError an error raised while parsing or evaluating an expression, recovered and reported as an IllegalExpression error.
+type exprError struct {
+	reason string
+}
+
+// token a lexical token of an expression.
+type token struct {
+	kind  int
+	text  string
+	value any
+}
+
+// Kinds of tokens.
+const (
+	eofToken = iota
+	literalToken
+	identToken
+	opToken
+)
+
+// precedence of binary operators, higher binds tighter.
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// compileExpr parses the given expression, panics with an IllegalExpression error if it is malformed. Expressions support number, string ('..' or
+// "..."), true, false and null literals, field names, parentheses, the arithmetic operators + - * / %, the comparison operators == != < <= > >= and
+// the logical operators && || !. A missing field evaluates to null, arithmetic on null results in null and ordering comparisons with null are false.
+func compileExpr(source string) expr {
+	tokens, err := lex(source)
+	if err != nil {
+		panic(errIllegalExpression(source, err.reason))
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.parse(0)
+	if err == nil && p.peek().kind != eofToken {
+		err = &exprError{reason: fmt.Sprintf("unexpected %q", p.peek().text)}
+	}
+	if err != nil {
+		panic(errIllegalExpression(source, err.reason))
+	}
+	return e
+}
+
+// evalExpr evaluates the compiled expression on the record, panics with an IllegalExpression error if the expression cannot be evaluated.
+func evalExpr(source string, e expr, r Record) (result any) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if err, ok := recovered.(*exprError); ok {
+				panic(errIllegalExpression(source, err.reason))
+			}
+			panic(recovered)
+		}
+	}()
+	return e.eval(r)
+}
+
+// lex splits the expression into tokens.
+func lex(source string) ([]token, *exprError) {
+	tokens := make([]token, 0)
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			value, err := strconv.ParseFloat(string(runes[i:j]), 64)
+			if err != nil {
+				return nil, &exprError{reason: fmt.Sprintf("illegal number %q", string(runes[i:j]))}
+			}
+			tokens = append(tokens, token{kind: literalToken, text: string(runes[i:j]), value: value})
+			i = j
+		case r == '\'' || r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				j++
+			}
+			if j == len(runes) {
+				return nil, &exprError{reason: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: literalToken, text: string(runes[i : j+1]), value: string(runes[i+1 : j])})
+			i = j + 1
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			text := string(runes[i:j])
+			switch text {
+			case "true":
+				tokens = append(tokens, token{kind: literalToken, text: text, value: true})
+			case "false":
+				tokens = append(tokens, token{kind: literalToken, text: text, value: false})
+			case "null", "nil":
+				tokens = append(tokens, token{kind: literalToken, text: text, value: nil})
+			default:
+				tokens = append(tokens, token{kind: identToken, text: text})
+			}
+			i = j
+		default:
+			if i+1 < len(runes) {
+				if op := string(runes[i : i+2]); op == "&&" || op == "||" || op == "==" || op == "!=" || op == "<=" || op == ">=" {
+					tokens = append(tokens, token{kind: opToken, text: op})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/%<>!()", r) {
+				return nil, &exprError{reason: fmt.Sprintf("unexpected %q", string(r))}
+			}
+			tokens = append(tokens, token{kind: opToken, text: string(r)})
+			i++
+		}
+	}
+	return append(tokens, token{kind: eofToken, text: "end of expression"}), nil
+}
+
+// parser a precedence climbing parser over tokens.
+type parser struct {
+	tokens []token
+	i      int
+}
+
+// peek returns the current token.
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+// next returns the current token and advances to the next one.
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != eofToken {
+		p.i++
+	}
+	return t
+}
+
+// parse parses a binary expression whose operators have at least the given precedence.
+func (p *parser) parse(min int) (expr, *exprError) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != opToken || !ok || prec < min {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parse(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: t.text, left: left, right: right}
+	}
+}
+
+// unary parses an operand optionally preceded by unary operators.
+func (p *parser) unary() (expr, *exprError) {
+	t := p.next()
+	switch {
+	case t.kind == opToken && (t.text == "!" || t.text == "-"):
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: t.text, operand: operand}, nil
+	case t.kind == opToken && t.text == "(":
+		e, err := p.parse(0)
+		if err != nil {
+			return nil, err
+		} else if closing := p.next(); closing.text != ")" {
+			return nil, &exprError{reason: fmt.Sprintf("expected ) but found %q", closing.text)}
+		}
+		return e, nil
+	case t.kind == literalToken:
+		return literalExpr{value: t.value}, nil
+	case t.kind == identToken:
+		return fieldExpr{name: t.text}, nil
+	}
+	return nil, &exprError{reason: fmt.Sprintf("unexpected %q", t.text)}
+}
+
+func (e literalExpr) eval(r Record) any {
+	return e.value
+}
+
+func (e fieldExpr) eval(r Record) any {
+	if n, ok := number(r[e.name]); ok {
+		return n
+	}
+	return r[e.name]
+}
+
+func (e unaryExpr) eval(r Record) any {
+	x := e.operand.eval(r)
+	if e.op == "!" {
+		return !boolean(x, e.op)
+	} else if x == nil {
+		return nil
+	}
+	n, ok := number(x)
+	if !ok {
+		panic(&exprError{reason: fmt.Sprintf("operator - is not defined on %v", x)})
+	}
+	return -n
+}
+
+func (e binaryExpr) eval(r Record) any {
+	switch e.op {
+	case "&&":
+		return boolean(e.left.eval(r), e.op) && boolean(e.right.eval(r), e.op)
+	case "||":
+		return boolean(e.left.eval(r), e.op) || boolean(e.right.eval(r), e.op)
+	}
+
+	x, y := e.left.eval(r), e.right.eval(r)
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(x, y)
+	case "!=":
+		return !reflect.DeepEqual(x, y)
+	}
+
+	if x == nil || y == nil {
+		switch e.op {
+		case "<", "<=", ">", ">=":
+			return false
+		}
+		return nil
+	}
+	a, aNumber := number(x)
+	b, bNumber := number(y)
+
+	if aNumber && bNumber {
+		switch e.op {
+		case "+":
+			return a + b
+		case "-":
+			return a - b
+		case "*":
+			return a * b
+		case "/":
+			return a / b
+		case "%":
+			return math.Mod(a, b)
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		}
+	}
+	s, sString := x.(string)
+	t, tString := y.(string)
+	if sString && tString {
+		switch e.op {
+		case "+":
+			return s + t
+		case "<":
+			return s < t
+		case "<=":
+			return s <= t
+		case ">":
+			return s > t
+		case ">=":
+			return s >= t
+		}
+	}
+	panic(&exprError{reason: fmt.Sprintf("operator %s is not defined on %v and %v", e.op, x, y)})
+}
+
+// boolean returns the value as a bool, panics if it is not one.
+func boolean(x any, op string) bool {
+	b, ok := x.(bool)
+	if !ok {
+		panic(&exprError{reason: fmt.Sprintf("operator %s is not defined on %v", op, x)})
+	}
+	return b
+}
+
+// number returns the value as a float64 if it is numeric.
+func number(x any) (float64, bool) {
+	switch n := x.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
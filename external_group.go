@@ -0,0 +1,247 @@
+package streams
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// externalGroupBuckets number of files the elements of a stream are spilled to by GroupByExternal, each bucket holds the groups whose key hashes to it.
+const externalGroupBuckets = 64
+
+// ExternalGroups groups of a stream that have been spilled to disk, groups are read back one bucket at a time so that only the groups of a single
+// bucket are held in memory.
+type ExternalGroups[T any] struct {
+	dir     string
+	buckets []string
+	codec   Codec[T]
+}
+
+// externalGroupsComplete name of the file marking a directory to which all the groups of a stream have been spilled, see OpenExternalGroups.
+const externalGroupsComplete = "complete"
+
+// GroupByExternal groups the elements of the stream by the given key function and spills the groups to temporary files in the given directory, the
+// default directory for temporary files is used if it is empty. Elements are written to the files as they are evaluated so that only the source of
+// the stream is held in memory. The groups remain on disk until Close is called, so they can be aggregated many times or reopened using
+// OpenExternalGroups (i.e by a job resuming from the spilled groups after a restart).
+func GroupByExternal[T any](s Stream[T], key func(x T) string, codec Codec[T], tmpDir string) (*ExternalGroups[T], error) {
+	source := s.(*stream[T])
+	if key == nil {
+		panic(errIllegalArgument("GroupByExternal", "nil"))
+	} else if codec == nil {
+		panic(errIllegalArgument("GroupByExternal", "nil"))
+	} else if err := source.terminate(); err != nil {
+		panic(err)
+	}
+
+	dir, err := os.MkdirTemp(tmpDir, "streams-groups-")
+	if err != nil {
+		return nil, err
+	}
+	groups := &ExternalGroups[T]{dir: dir, codec: codec}
+	writers := make([]*bucketWriter, externalGroupBuckets)
+	var mux sync.Mutex
+	spill := func(x T) error {
+		k := key(x)
+		hash := fnv.New32a()
+		hash.Write([]byte(k))
+		i := hash.Sum32() % externalGroupBuckets
+		data, err := codec.Encode(x)
+		if err != nil {
+			return err
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		if writers[i] == nil {
+			path := filepath.Join(dir, strconv.Itoa(int(i)))
+			w, err := newBucketWriter(path)
+			if err != nil {
+				return err
+			}
+			writers[i] = w
+			groups.buckets = append(groups.buckets, path)
+		}
+		return writers[i].write([]byte(k), data)
+	}
+
+	var once sync.Once
+	defer func() {
+		if r := recover(); r != nil {
+			for _, w := range writers {
+				if w != nil {
+					w.close()
+				}
+			}
+			groups.Close()
+			panic(r)
+		}
+	}()
+	source.forEachWhile(func(x T) bool {
+		if spillErr := spill(x); spillErr != nil {
+			once.Do(func() { err = spillErr })
+			return false
+		}
+		return true
+	})
+	for _, w := range writers {
+		if w == nil {
+			continue
+		} else if closeErr := w.close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, externalGroupsComplete), nil, 0o644)
+	}
+	if err != nil {
+		groups.Close()
+		return nil, err
+	}
+	sort.Strings(groups.buckets)
+	return groups, nil
+}
+
+// OpenExternalGroups opens the groups spilled to the given directory by GroupByExternal, see Dir. An error is returned if the directory does not
+// hold all the groups of a stream, i.e the process spilling them stopped before they were all written.
+func OpenExternalGroups[T any](dir string, codec Codec[T]) (*ExternalGroups[T], error) {
+	if codec == nil {
+		panic(errIllegalArgument("OpenExternalGroups", "nil"))
+	} else if _, err := os.Stat(filepath.Join(dir, externalGroupsComplete)); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	groups := &ExternalGroups[T]{dir: dir, codec: codec}
+	for _, entry := range entries {
+		if entry.Name() != externalGroupsComplete {
+			groups.buckets = append(groups.buckets, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(groups.buckets)
+	return groups, nil
+}
+
+// Dir returns the directory the groups have been spilled to.
+func (g *ExternalGroups[T]) Dir() string {
+	return g.dir
+}
+
+// ForEach performs the given action on each group, groups are read back from disk one bucket at a time. The first error encountered while reading
+// the groups is returned.
+func (g *ExternalGroups[T]) ForEach(f func(g Group[T])) error {
+	for _, path := range g.buckets {
+		groups, keys, err := g.read(path)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			f(Group[T]{name: k, data: groups[k]})
+		}
+	}
+	return nil
+}
+
+// Close removes the files the groups have been spilled to.
+func (g *ExternalGroups[T]) Close() error {
+	return os.RemoveAll(g.dir)
+}
+
+// read returns the groups of the bucket at the given path along with their keys in order of first occurrence.
+func (g *ExternalGroups[T]) read(path string) (map[string][]T, []string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	groups := make(map[string][]T)
+	keys := make([]string, 0)
+	for {
+		k, err := readFrame(r)
+		if err == io.EOF {
+			return groups, keys, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		data, err := readFrame(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		x, err := g.codec.Decode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := groups[string(k)]; !ok {
+			keys = append(keys, string(k))
+		}
+		groups[string(k)] = append(groups[string(k)], x)
+	}
+}
+
+// bucketWriter writes length prefixed frames to a bucket file.
+type bucketWriter struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+// newBucketWriter creates the bucket file at the given path.
+func newBucketWriter(path string) (*bucketWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &bucketWriter{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// write writes the given key and encoded element as frames.
+func (b *bucketWriter) write(key, data []byte) error {
+	if err := writeFrame(b.w, key); err != nil {
+		return err
+	}
+	return writeFrame(b.w, data)
+}
+
+// close flushes and closes the bucket file.
+func (b *bucketWriter) close() error {
+	if err := b.w.Flush(); err != nil {
+		b.file.Close()
+		return err
+	}
+	return b.file.Close()
+}
+
+// writeFrame writes the given bytes prefixed with their length.
+func writeFrame(w io.Writer, data []byte) error {
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(data)))
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame reads bytes written by writeFrame, io.EOF is returned if there are no more frames.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
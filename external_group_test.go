@@ -0,0 +1,99 @@
+package streams
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByExternal(t *testing.T) {
+
+	type groupByExternalTest struct {
+		data     []string
+		expected map[string]int
+	}
+
+	groupByExternalTests := []groupByExternalTest{
+		{data: []string{}, expected: map[string]int{}},
+		{data: []string{"a1", "b1", "a2", "c1", "a3", "b2"}, expected: map[string]int{"a": 3, "b": 2, "c": 1}},
+	}
+
+	key := func(x string) string { return x[:1] }
+
+	for _, test := range groupByExternalTests {
+		for _, s := range []Stream[string]{
+			New(func() []string { return test.data }),
+			New(func() []string { return test.data }).Parallelize(2),
+		} {
+			dir := t.TempDir()
+			groups, err := GroupByExternal(s, key, JSONCodec[string](), dir)
+			assert.Nil(t, err)
+
+			counts := make(map[string]int)
+			assert.Nil(t, groups.ForEach(func(g Group[string]) {
+				counts[g.Name()] = g.Len()
+				for _, x := range g.Data() {
+					assert.True(t, strings.HasPrefix(x, g.Name()))
+				}
+			}))
+			assert.Equal(t, test.expected, counts)
+
+			assert.Nil(t, groups.Close())
+			entries, _ := os.ReadDir(dir)
+			assert.Empty(t, entries)
+		}
+	}
+
+}
+
+type failingCodec struct{ Codec[string] }
+
+func (failingCodec) Encode(x string) ([]byte, error) { return nil, errors.New("encode failed") }
+
+func TestGroupByExternalErr(t *testing.T) {
+
+	dir := t.TempDir()
+	groups, err := GroupByExternal[string](New(func() []string { return []string{"a"} }), func(x string) string { return x },
+		failingCodec{JSONCodec[string]()}, dir)
+	assert.Nil(t, groups)
+	assert.EqualError(t, err, "encode failed")
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+
+}
+
+func TestOpenExternalGroups(t *testing.T) {
+
+	data := []string{"a1", "b1", "a2"}
+	groups, err := GroupByExternal(New(func() []string { return data }).Parallelize(2), func(x string) string { return x[:1] }, JSONCodec[string](),
+		t.TempDir())
+	assert.Nil(t, err)
+	defer groups.Close()
+
+	reopened, err := OpenExternalGroups(groups.Dir(), JSONCodec[string]())
+	assert.Nil(t, err)
+	counts := make(map[string]int)
+	assert.Nil(t, reopened.ForEach(func(g Group[string]) { counts[g.Name()] = g.Len() }))
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, counts)
+
+	// A directory to which the groups have not all been spilled is rejected.
+	_, err = OpenExternalGroups(t.TempDir(), JSONCodec[string]())
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	key := func(x string) string { return x }
+	assert.Panics(t, func() { GroupByExternal(New(func() []string { return data }), nil, JSONCodec[string](), "") })
+	assert.Panics(t, func() { GroupByExternal[string](New(func() []string { return data }), key, nil, "") })
+	assert.Panics(t, func() { OpenExternalGroups[string](groups.Dir(), nil) })
+
+	// Spilled files are removed if the evaluation panics.
+	dir := t.TempDir()
+	assert.Panics(t, func() {
+		GroupByExternal(New(func() []string { return data }).Map(func(x string) string { panic(x) }), key, JSONCodec[string](), dir)
+	})
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+
+}
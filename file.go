@@ -0,0 +1,92 @@
+package streams
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fileOptions options for writing the elements of a stream to a file.
+type fileOptions struct {
+	gzip bool
+	perm os.FileMode
+}
+
+// FileOption an option for writing the elements of a stream to a file with ToFile.
+type FileOption func(o *fileOptions)
+
+// Gzip returns an option which compresses the file written by ToFile using gzip.
+func Gzip() FileOption {
+	return func(o *fileOptions) {
+		o.gzip = true
+	}
+}
+
+// FilePerm returns an option which sets the permissions of the file written by ToFile, the default is 0644.
+func FilePerm(perm os.FileMode) FileOption {
+	return func(o *fileOptions) {
+		o.perm = perm
+	}
+}
+
+// fileWriter writes encoded elements to a temporary file which replaces the target file once all elements have been written.
+type fileWriter struct {
+	path   string
+	file   *os.File
+	buffer *bufio.Writer
+	gzip   *gzip.Writer
+	w      io.Writer
+	perm   os.FileMode
+}
+
+// newFileWriter creates a temporary file in the directory of the file at the given path, so that it can be renamed to the path.
+func newFileWriter(path string, opts []FileOption) (*fileWriter, error) {
+	o := fileOptions{perm: 0644}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	w := &fileWriter{path: path, file: file, buffer: bufio.NewWriter(file), perm: o.perm}
+	w.w = w.buffer
+	if o.gzip {
+		w.gzip = gzip.NewWriter(w.buffer)
+		w.w = w.gzip
+	}
+	return w, nil
+}
+
+// write writes the data to the temporary file.
+func (w *fileWriter) write(data []byte) error {
+	_, err := w.w.Write(data)
+	return err
+}
+
+// commit flushes the temporary file and renames it to the target path.
+func (w *fileWriter) commit() error {
+	if w.gzip != nil {
+		if err := w.gzip.Close(); err != nil {
+			return err
+		}
+	}
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	} else if err := w.file.Sync(); err != nil {
+		return err
+	} else if err := w.file.Chmod(w.perm); err != nil {
+		return err
+	} else if err := w.file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(w.file.Name(), w.path)
+}
+
+// abort removes the temporary file, it has no effect once the file has been committed.
+func (w *fileWriter) abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
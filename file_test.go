@@ -0,0 +1,63 @@
+package streams
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToFile(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5} }
+	encode := func(x int) []byte { return []byte(strconv.Itoa(x) + "\n") }
+	dir := t.TempDir()
+
+	// Plain file.
+	path := filepath.Join(dir, "report.txt")
+	assert.Nil(t, New(supplier).ToFile(path, encode))
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "1\n2\n3\n4\n5\n", string(data))
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// Gzip and permissions.
+	path = filepath.Join(dir, "report.txt.gz")
+	assert.Nil(t, New(supplier).Parallelize(2).ToFile(path, encode, Gzip(), FilePerm(0600)))
+	file, _ := os.Open(path)
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	assert.Nil(t, err)
+	data, _ = io.ReadAll(r)
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4", "5"}, strings.Fields(string(data)))
+	info, _ = os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A panic leaves the existing file untouched and no temporary files behind.
+	path = filepath.Join(dir, "existing.txt")
+	os.WriteFile(path, []byte("old"), 0644)
+	assert.Panics(t, func() {
+		New(supplier).Map(func(x int) int {
+			if x == 3 {
+				panic(errors.New("failed"))
+			}
+			return x
+		}).ToFile(path, encode)
+	})
+	data, _ = os.ReadFile(path)
+	assert.Equal(t, "old", string(data))
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 3, len(entries))
+
+	// Missing directory.
+	assert.NotNil(t, New(supplier).ToFile(filepath.Join(dir, "missing", "report.txt"), encode))
+	assert.Panics(t, func() { New(supplier).ToFile(filepath.Join(dir, "nil.txt"), nil) })
+
+}
@@ -0,0 +1,254 @@
+package streams
+
+// DuplicateKeys returns the keys (computed using the given key function) that appear more than once in the stream. For a sequential stream keys
+// are returned in the order in which they were found to be duplicated, a parallel stream counts keys in shards and does not preserve any order.
+func DuplicateKeys[T any, K comparable](s Stream[T], key func(x T) K) []K {
+	source := s.(*stream[T])
+	if err := source.terminate(); err != nil {
+		panic(err)
+	}
+	operations, done := source.evaluation()
+	defer done()
+	if source.parallel {
+		return parallelDuplicateKeys(source.supply(), operations, key, source.executor)
+	}
+	return duplicateKeys(source.supply(), operations, key)
+}
+
+// InternStrings returns a stream consisting of the elements of this stream in which equal strings are replaced with a single shared instance, reducing
+// memory use of pipelines that hold on to many duplicated strings (i.e keys before GroupBy).
+func InternStrings(s Stream[string]) Stream[string] {
+	return InternStringsBy(s, func(x string) string { return x }, func(_ string, shared string) string { return shared })
+}
+
+// InternStringsBy returns a stream consisting of the elements of this stream in which the string read from each element using get is replaced,
+// using set, with a single shared instance of equal strings.
+func InternStringsBy[T any](s Stream[T], get func(x T) string, set func(x T, shared string) T) Stream[T] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return new(source, intern(get, set))
+}
+
+// MapAccum returns a stream consisting of the results of applying the given function to the elements of the stream along with a running state, the
+// state returned by f for an element is passed to f with the next element (i.e assigning sequence numbers). The function is applied sequentially in
+// the order of the source elements even if the stream is parallel, the operations before and after it are still evaluated in parallel.
+func MapAccum[S any, T any, U any](s Stream[T], init S, f func(state S, x T) (S, U)) Stream[U] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	} else if f == nil {
+		panic(errIllegalArgument("MapAccum", "nil"))
+	}
+	return orderedTransform(source, func(data []T) []U {
+		state := init
+		results := make([]U, len(data))
+		for i := range data {
+			state, results[i] = f(state, data[i])
+		}
+		return results
+	})
+}
+
+// Map returns a stream consisting of the results of applying the given function to the elements of the stream, unlike the Map method of a stream
+// the elements may change type. The operations of the stream are evaluated along with f when the returned stream is evaluated, by the routines of
+// the stream if it is parallel, and the returned stream keeps the parallelism of the stream.
+func Map[T any, U any](s Stream[T], f func(x T) U) Stream[U] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	} else if f == nil {
+		panic(errIllegalArgument("Map", "nil"))
+	}
+	return mapElements(source, f)
+}
+
+// FlatMap returns a stream consisting of the elements of the slices resulting from applying the given function to the elements of the stream, in the
+// order of the elements they result from for a sequential stream. The operations of the stream are evaluated along with f when the returned stream is
+// evaluated, by the routines of the stream if it is parallel, and the returned stream keeps the parallelism of the stream.
+func FlatMap[T any, U any](s Stream[T], f func(x T) []U) Stream[U] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	} else if f == nil {
+		panic(errIllegalArgument("FlatMap", "nil"))
+	}
+	return multiMapElements(source, func(x T, emit func(U)) {
+		for _, y := range f(x) {
+			emit(y)
+		}
+	})
+}
+
+// ForkJoin evaluates the stream once and runs each of the given terminals concurrently on a stream of its own copy of the resulting elements, i.e to
+// compute many aggregates of the same data, and returns the results of the terminals in the order they were given. The streams passed to terminals
+// keep the parallelism of the stream. If any terminal panics, the first panic is raised once all terminals have returned.
+func ForkJoin[T any](s Stream[T], terminals ...func(s Stream[T]) any) []any {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	for _, terminal := range terminals {
+		if terminal == nil {
+			panic(errIllegalArgument("ForkJoin", "nil"))
+		}
+	}
+	data := source.Collect()
+	results := make([]any, len(terminals))
+	g := group{operation: "ForkJoin"}
+	for i := range terminals {
+		i := i
+		g.spawn(func() {
+			elements := make([]T, len(data))
+			copy(elements, data)
+			results[i] = terminals[i](&stream[T]{
+				supplier:   func() []T { return elements },
+				operations: make([]operator[T], 0),
+				parallel:   source.parallel,
+				executor:   source.executor,
+				origin:     source.origin,
+			})
+		})
+	}
+	g.wait()
+	return results
+}
+
+// FoldRight returns the result of accumulating the elements of the stream from the last to the first, starting with init, i.e f(x0, f(x1, init)) for
+// a stream of elements x0, x1, so that f need not be commutative. The stream is evaluated sequentially, even if it is parallel, so that the order of
+// its elements is that of its source.
+func FoldRight[T any, U any](s Stream[T], init U, f func(x T, acc U) U) U {
+	if f == nil {
+		panic(errIllegalArgument("FoldRight", "nil"))
+	}
+	data := s.Sequential().Collect()
+	acc := init
+	for i := len(data) - 1; i >= 0; i-- {
+		acc = f(data[i], acc)
+	}
+	return acc
+}
+
+// ReduceRight returns the result of reducing the elements of the stream from the last to the first, i.e f(x0, f(x1, x2)) for a stream of elements x0,
+// x1, x2. The zero value is returned if there are no elements. Like FoldRight the stream is evaluated sequentially.
+func ReduceRight[T any](s Stream[T], f func(x T, acc T) T) T {
+	if f == nil {
+		panic(errIllegalArgument("ReduceRight", "nil"))
+	}
+	data := s.Sequential().Collect()
+	if len(data) == 0 {
+		var zero T
+		return zero
+	}
+	acc := data[len(data)-1]
+	for i := len(data) - 2; i >= 0; i-- {
+		acc = f(data[i], acc)
+	}
+	return acc
+}
+
+// Pair a pair of values, see Zip.
+type Pair[A any, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip returns a stream consisting of pairs of the elements of the given streams at the same position, the returned stream has as many elements as
+// the shorter of the streams. See ZipWith.
+func Zip[A any, B any](a Stream[A], b Stream[B]) Stream[Pair[A, B]] {
+	return ZipWith(a, b, func(x A, y B) Pair[A, B] { return Pair[A, B]{First: x, Second: y} })
+}
+
+// ZipWith returns a stream consisting of the results of applying the given function to the elements of the given streams at the same position, the
+// returned stream has as many elements as the shorter of the streams. Elements of a parallel stream are paired in their source order, the returned
+// stream keeps the parallelism of the first stream. A stream created by FromChannel or FromSeq is pulled a page at a time, so the returned stream
+// ends with the shorter stream even if the other one is unbounded.
+func ZipWith[A any, B any, C any](a Stream[A], b Stream[B], f func(x A, y B) C) Stream[C] {
+	left, right := a.(*stream[A]), b.(*stream[B])
+	if f == nil {
+		panic(errIllegalArgument("ZipWith", "nil"))
+	} else if ok, err := left.valid(); !ok {
+		panic(err)
+	} else if ok, err := right.valid(); !ok {
+		panic(err)
+	} else if err := left.close(); err != nil {
+		panic(err)
+	} else if err := right.close(); err != nil {
+		panic(err)
+	}
+	nextLeft, nextRight := orderedPages(left), orderedPages(right)
+	var x []A
+	var y []B
+	moreLeft, moreRight := true, true
+	pull := func() ([]C, bool) {
+		if len(x) == 0 && moreLeft {
+			x, moreLeft = nextLeft()
+		}
+		if len(y) == 0 && moreRight {
+			y, moreRight = nextRight()
+		}
+		n := len(x)
+		if len(y) < n {
+			n = len(y)
+		}
+		results := make([]C, n)
+		for i := range results {
+			results[i] = f(x[i], y[i])
+		}
+		x, y = x[n:], y[n:]
+		return results, (len(x) > 0 || moreLeft) && (len(y) > 0 || moreRight)
+	}
+	result := &stream[C]{
+		operations: make([]operator[C], 0),
+		parallel:   left.parallel,
+		executor:   left.executor,
+		origin:     left.origin,
+		interrupt: func() {
+			if left.interrupt != nil {
+				left.interrupt()
+			}
+			if right.interrupt != nil {
+				right.interrupt()
+			}
+		},
+	}
+	if left.appended == nil && right.appended == nil {
+		result.supplier = func() []C {
+			results, _ := pull()
+			return results
+		}
+		return result
+	}
+	// A paged stream is pulled along with the other one, so it is stopped if the other one ends first.
+	result.appended = paged(pull).evaluatedBy(left.parallel, left.executor)
+	result.supplier = result.appended.supply
+	result.release = func(early bool) {
+		if left.appended != nil && left.release != nil {
+			left.release(early || moreLeft)
+		}
+		if right.appended != nil && right.release != nil {
+			right.release(early || moreRight)
+		}
+	}
+	return result
+}
+
+// orderedPages returns a function that returns the next page of the elements of the stream in the order of the source elements they result from,
+// along with an indication of whether more pages may follow. The elements of a stream whose source is not paged are returned in a single page.
+func orderedPages[T any](s *stream[T]) func() ([]T, bool) {
+	if s.appended == nil {
+		supplier := orderedElementsSupplier(s)
+		return func() ([]T, bool) { return supplier(), false }
+	}
+	next, operations, e := s.appended.pull(), s.operations, s.executor
+	return func() ([]T, bool) {
+		data, ok := next()
+		if s.parallel {
+			data = parallelCollectOrdered(data, operations, e)
+		} else {
+			data = collect(data, operations)
+		}
+		return data, ok && !exhausted(operations)
+	}
+}
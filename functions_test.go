@@ -0,0 +1,369 @@
+package streams
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+
+	type duplicateKeysTest struct {
+		data     []string
+		expected []int
+	}
+
+	var duplicateKeysTests = []duplicateKeysTest{
+		{data: []string{}, expected: []int{}},
+		{data: []string{"a", "bb", "ccc"}, expected: []int{}},
+		{data: []string{"a", "bb", "c", "dd", "eee", "f"}, expected: []int{1, 2}},
+	}
+
+	key := func(x string) int { return len(x) }
+
+	for _, test := range duplicateKeysTests {
+		s1, s2 := New(func() []string { return test.data }),
+			New(func() []string { return test.data }).Parallelize(2)
+		assert.Equal(t, test.expected, DuplicateKeys(s1, key))
+		assert.ElementsMatch(t, test.expected, DuplicateKeys(s2, key))
+		assert.True(t, s1.Closed())
+		assert.True(t, s1.Terminated())
+		assert.True(t, s2.Closed())
+		assert.True(t, s2.Terminated())
+	}
+
+}
+
+func TestInternStrings(t *testing.T) {
+
+	// Build equal strings that do not share memory.
+	data := make([]string, 0)
+	for i := 0; i < 6; i++ {
+		data = append(data, strings.Repeat("ab", i%2+1))
+	}
+
+	pointer := func(x string) uintptr { return (*reflect.StringHeader)(unsafe.Pointer(&x)).Data }
+
+	for _, s := range []Stream[string]{New(func() []string { return data }), New(func() []string { return data }).Parallelize(2)} {
+		results := InternStrings(s).Collect()
+		assert.ElementsMatch(t, data, results)
+		pointers := make(map[string]map[uintptr]struct{})
+		for _, x := range results {
+			if _, ok := pointers[x]; !ok {
+				pointers[x] = make(map[uintptr]struct{})
+			}
+			pointers[x][pointer(x)] = struct{}{}
+		}
+		assert.Len(t, pointers["ab"], 1)
+		assert.Len(t, pointers["abab"], 1)
+	}
+
+	type record struct {
+		key   string
+		value int
+	}
+
+	records := []record{{key: strings.Repeat("k", 2), value: 1}, {key: strings.Repeat("k", 2), value: 2}}
+	results := InternStringsBy(New(func() []record { return records }),
+		func(x record) string { return x.key },
+		func(x record, shared string) record { x.key = shared; return x }).Collect()
+	assert.Equal(t, pointer(results[0].key), pointer(results[1].key))
+	assert.Equal(t, []int{1, 2}, []int{results[0].value, results[1].value})
+
+}
+
+func TestKeyOf(t *testing.T) {
+
+	type address struct {
+		City string `stream:"city"`
+		Zip  int
+	}
+
+	type person struct {
+		Name    string
+		Age     int
+		Address *address
+	}
+
+	people := []person{
+		{Name: "a", Age: 30, Address: &address{City: "x", Zip: 1}},
+		{Name: "b", Age: 40, Address: &address{City: "y", Zip: 2}},
+		{Name: "c", Age: 30, Address: &address{City: "x", Zip: 1}},
+		{Name: "d", Age: 50},
+	}
+
+	type keyOfTest struct {
+		path     string
+		expected map[string]int
+	}
+
+	keyOfTests := []keyOfTest{
+		{path: "Name", expected: map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}},
+		{path: "Age", expected: map[string]int{"30": 2, "40": 1, "50": 1}},
+		{path: "Address.city", expected: map[string]int{"x": 2, "y": 1, "": 1}},
+		{path: "Address.Zip", expected: map[string]int{"1": 2, "2": 1, "": 1}},
+	}
+
+	for _, test := range keyOfTests {
+		a := New(func() []person { return people }).GroupBy(KeyOf[person](test.path)).Count()
+		b := New(func() []person { return people }).Parallelize(2).GroupBy(KeyOf[person](test.path)).Count()
+		assert.Equal(t, test.expected, a)
+		assert.Equal(t, test.expected, b)
+	}
+
+	assert.Equal(t, "a", KeyOf[*person]("Name")(&people[0]))
+	assert.Equal(t, "a", KeyOf[any]("Name")(people[0]))
+	assert.Panics(t, func() { KeyOf[person]("Missing") })
+	assert.Panics(t, func() { KeyOf[person]("Name.First") })
+	assert.Panics(t, func() { KeyOf[any]("Missing")(people[0]) })
+
+}
+
+func TestMapAccum(t *testing.T) {
+
+	supplier := func() []string { return []string{"a", "b", "c", "d", "e", "f"} }
+	number := func(n int, x string) (int, string) { return n + 1, fmt.Sprintf("%d:%s", n, x) }
+
+	type mapAccumTest struct {
+		s        Stream[string]
+		expected []string
+	}
+
+	mapAccumTests := []mapAccumTest{
+		{s: MapAccum(New(supplier), 1, number), expected: []string{"1:a", "2:b", "3:c", "4:d", "5:e", "6:f"}},
+		{s: MapAccum(New(supplier).Parallelize(3), 1, number), expected: []string{"1:a", "2:b", "3:c", "4:d", "5:e", "6:f"}},
+		{s: MapAccum(New(supplier).Filter(func(x string) bool { return x != "c" }).Parallelize(2), 0, number), expected: []string{"0:a", "1:b", "2:d", "3:e", "4:f"}},
+		{s: MapAccum(New[string](nil), 0, number), expected: []string{}},
+	}
+
+	// Elements of a parallel stream are collected in any order, the state is still accumulated in the order of the source.
+	for _, test := range mapAccumTests {
+		assert.ElementsMatch(t, test.expected, test.s.Collect())
+	}
+
+	// Running totals, the resulting stream stays parallel.
+	totals := MapAccum(New(func() []int { return []int{1, 2, 3, 4} }).Parallelize(2), 0, func(sum int, x int) (int, int) { return sum + x, sum + x })
+	assert.True(t, totals.Parallel())
+	assert.ElementsMatch(t, []int{1, 3, 6, 10}, totals.Collect())
+
+	assert.Panics(t, func() { MapAccum[int, string, string](New(supplier), 0, nil) })
+
+}
+
+func TestMapFunc(t *testing.T) {
+
+	supplier := func() []string { return []string{"1", "22", "333", "4444"} }
+	length := func(x string) int { return len(x) }
+
+	type mapTest struct {
+		s        Stream[int]
+		expected []int
+	}
+
+	mapTests := []mapTest{
+		{s: Map(New(supplier), length), expected: []int{1, 2, 3, 4}},
+		{s: Map(New(supplier).Parallelize(2), length), expected: []int{1, 2, 3, 4}},
+		{s: Map(New(supplier).Filter(func(x string) bool { return len(x) > 2 }), length), expected: []int{3, 4}},
+		{s: Map(New(supplier).Limit(2), length).Parallelize(3).Map(func(x int) int { return x * 10 }), expected: []int{10, 20}},
+		{s: Map(New[string](nil), length), expected: []int{}},
+	}
+
+	for _, test := range mapTests {
+		assert.ElementsMatch(t, test.expected, test.s.Collect())
+	}
+
+	// The stream is only evaluated when the mapped stream is.
+	var calls int32
+	s := Map(New(supplier).Peek(func(x string) { atomic.AddInt32(&calls, 1) }), length)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	assert.Equal(t, 4, s.Count())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	assert.True(t, Map(New(supplier).Parallelize(2), length).Parallel())
+	assert.Panics(t, func() { Map[string, int](New(supplier), nil) })
+
+	// The settings of the stream are kept.
+	numbers := make([]int, 100)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	double := func(x int) int { return 2 * x }
+	expected := Map(New(func() []int { return numbers }), double).Collect()
+	assert.Equal(t, expected, Map(New(func() []int { return numbers }).Parallelize(4).Ordered(), double).Collect())
+	assert.True(t, Map(New(func() []int { return numbers }).ParallelizeAuto(), double).ExecutionInfo().Auto)
+	assert.True(t, Map(New(func() []int { return numbers }).FailFast(), double).(*stream[int]).failFast)
+	assert.Equal(t, 2, Map(New(func() []int { return numbers }).WithCapture(2), double).(*stream[int]).capture)
+
+}
+
+func TestFlatMapFunc(t *testing.T) {
+
+	supplier := func() []string { return []string{"a b", "", "c", "d e f"} }
+	words := func(x string) []string { return strings.Fields(x) }
+
+	type flatMapTest struct {
+		s        Stream[string]
+		expected []string
+	}
+
+	flatMapTests := []flatMapTest{
+		{s: FlatMap(New(supplier), words), expected: []string{"a", "b", "c", "d", "e", "f"}},
+		{s: FlatMap(New(supplier).Parallelize(2), words), expected: []string{"a", "b", "c", "d", "e", "f"}},
+		{s: FlatMap(New(supplier).Skip(2), words).Limit(2), expected: []string{"c", "d"}},
+		{s: FlatMap(New(supplier).Parallelize(3).Filter(func(x string) bool { return len(x) > 1 }), words).Map(strings.ToUpper),
+			expected: []string{"A", "B", "D", "E", "F"}},
+		{s: FlatMap(New[string](nil), words), expected: []string{}},
+	}
+
+	for _, test := range flatMapTests {
+		assert.ElementsMatch(t, test.expected, test.s.Collect())
+	}
+
+	lengths := FlatMap(New(supplier), func(x string) []int { return []int{len(x)} })
+	assert.Equal(t, []int{3, 0, 1, 5}, lengths.Collect())
+	assert.True(t, FlatMap(New(supplier).Parallelize(2), words).Parallel())
+	many := make([]string, 100)
+	for i := range many {
+		many[i] = fmt.Sprintf("%d %d", i, i)
+	}
+	assert.Equal(t, FlatMap(New(func() []string { return many }), words).Collect(),
+		FlatMap(New(func() []string { return many }).Parallelize(4).Ordered(), words).Collect())
+	assert.Panics(t, func() { FlatMap[string, string](New(supplier), nil) })
+
+}
+
+func TestForkJoin(t *testing.T) {
+
+	supplier := func() []int { return []int{5, 3, 8, 1} }
+	sum := func(s Stream[int]) any { return s.Reduce(func(x, y int) int { return x + y }) }
+	count := func(s Stream[int]) any { return s.Count() }
+	evens := func(s Stream[int]) any { return s.Filter(func(x int) bool { return x%2 == 0 }).Count() }
+	sorted := func(s Stream[int]) any { return s.Sorted(func(x, y int) bool { return x < y }).Collect() }
+
+	for _, s := range []func() Stream[int]{func() Stream[int] { return New(supplier) }, func() Stream[int] { return New(supplier).Parallelize(2) }} {
+		var calls int32
+		source := s().Peek(func(x int) { atomic.AddInt32(&calls, 1) })
+		results := ForkJoin(source, sum, count, evens, sorted, sum)
+		assert.Equal(t, []any{17, 4, 1, []int{1, 3, 5, 8}, 17}, results)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+		assert.True(t, source.Terminated())
+	}
+
+	assert.Equal(t, []any{}, ForkJoin(New(supplier)))
+	assert.Panics(t, func() { ForkJoin(New(supplier), sum, nil) })
+	assert.PanicsWithValue(t, "failed", func() {
+		ForkJoin(New(supplier), sum, func(s Stream[int]) any { panic("failed") })
+	})
+
+}
+
+// node an element of a linked list built by FoldRight.
+type node struct {
+	value int
+	next  *node
+}
+
+func TestFoldRight(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5, 6} }
+	subtract := func(x int, acc int) int { return x - acc }
+
+	for _, s := range []func() Stream[int]{func() Stream[int] { return New(supplier) }, func() Stream[int] { return New(supplier).Parallelize(3) }} {
+		assert.Equal(t, -3, FoldRight(s(), 0, subtract))
+		assert.Equal(t, "123456", FoldRight(s(), "", func(x int, acc string) string { return fmt.Sprint(x) + acc }))
+		assert.Equal(t, "246", FoldRight(s().Filter(func(x int) bool { return x%2 == 0 }), "", func(x int, acc string) string { return fmt.Sprint(x) + acc }))
+
+		list := FoldRight(s(), (*node)(nil), func(x int, acc *node) *node { return &node{value: x, next: acc} })
+		values := make([]int, 0)
+		for n := list; n != nil; n = n.next {
+			values = append(values, n.value)
+		}
+		assert.Equal(t, supplier(), values)
+	}
+
+	assert.Equal(t, "init", FoldRight(New[int](nil), "init", func(x int, acc string) string { return acc + "!" }))
+	assert.Panics(t, func() { FoldRight[int, int](New(supplier), 0, nil) })
+
+}
+
+func TestReduceRight(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5, 6} }
+	subtract := func(x int, acc int) int { return x - acc }
+
+	assert.Equal(t, -3, ReduceRight(New(supplier), subtract))
+	assert.Equal(t, -3, ReduceRight(New(supplier).Parallelize(2), subtract))
+	assert.Equal(t, 6, ReduceRight(New(supplier).Skip(5), subtract))
+	assert.Equal(t, 0, ReduceRight(New[int](nil), subtract))
+	assert.Panics(t, func() { ReduceRight[int](New(supplier), nil) })
+
+}
+
+func TestZip(t *testing.T) {
+
+	type zipTest struct {
+		a        []int
+		b        []string
+		expected []Pair[int, string]
+	}
+
+	zipTests := []zipTest{
+		{a: []int{}, b: []string{"a"}, expected: []Pair[int, string]{}},
+		{a: []int{1, 2, 3}, b: []string{"a", "b", "c"}, expected: []Pair[int, string]{{1, "a"}, {2, "b"}, {3, "c"}}},
+		{a: []int{1, 2, 3, 4, 5}, b: []string{"a", "b"}, expected: []Pair[int, string]{{1, "a"}, {2, "b"}}},
+		{a: []int{1}, b: []string{"a", "b", "c"}, expected: []Pair[int, string]{{1, "a"}}},
+	}
+
+	for _, test := range zipTests {
+		a := Zip(New(func() []int { return test.a }), New(func() []string { return test.b })).Collect()
+		b := Zip(New(func() []int { return test.a }).Parallelize(2), New(func() []string { return test.b })).Collect()
+		c := Zip(New(func() []int { return test.a }).Parallelize(2), New(func() []string { return test.b }).Parallelize(3)).Ordered().Collect()
+		assert.Equal(t, test.expected, a)
+		assert.ElementsMatch(t, test.expected, b)
+		assert.Equal(t, test.expected, c)
+	}
+
+	// Elements of parallel streams are paired in their source order.
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+	even := func(x int) bool { return x%2 == 0 }
+	sums := ZipWith(New(func() []int { return data }).Parallelize(4).Filter(even), New(func() []int { return data }).Parallelize(3).Filter(even),
+		func(x, y int) int { return x - y }).Collect()
+	assert.Len(t, sums, 50)
+	for _, x := range sums {
+		assert.Equal(t, 0, x)
+	}
+
+	// An open channel is pulled along with the other stream and stopped once the other stream ends.
+	for _, parallel := range []bool{false, true} {
+		channel := make(chan int, 3)
+		channel <- 1
+		channel <- 2
+		channel <- 3
+		open, source := FromChannel(channel)
+		if parallel {
+			open = open.Parallelize(2)
+		}
+		pairs := Zip(New(func() []string { return []string{"a", "b"} }), open).Collect()
+		assert.Equal(t, []Pair[string, int]{{"a", 1}, {"b", 2}}, pairs)
+		select {
+		case <-source.stop:
+		default:
+			assert.Fail(t, "source not stopped")
+		}
+	}
+
+	s := New(func() []int { return []int{} })
+	s.Count()
+	assert.Panics(t, func() { Zip(New(func() []int { return []int{} }), s) })
+	assert.Panics(t, func() {
+		ZipWith[int, int, int](New(func() []int { return []int{} }), New(func() []int { return []int{} }), nil)
+	})
+}
@@ -0,0 +1,41 @@
+// Package gen provides sources of generated data for benchmarks and tests of stream pipelines. The data is pseudo random but reproducible, every
+// invocation of a source supplies the same elements for the same Seed, so that runs of a benchmark can be compared.
+package gen
+
+import "math/rand"
+
+// Seed seed of the pseudo random data supplied by sources, sources read it when they are created.
+var Seed int64 = 1
+
+// letters characters of generated strings.
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// Ints returns a source of n integers uniformly distributed in [0, n), so that the integers contain duplicates.
+func Ints(n int) func() []int {
+	return Structs(n, func(r *rand.Rand, i int) int { return r.Intn(n) })
+}
+
+// Strings returns a source of n strings of the given length consisting of lowercase letters.
+func Strings(n int, length int) func() []string {
+	return Structs(n, func(r *rand.Rand, i int) string {
+		b := make([]byte, length)
+		for j := range b {
+			b[j] = letters[r.Intn(len(letters))]
+		}
+		return string(b)
+	})
+}
+
+// Structs returns a source of n elements created by fill, which is given the index of the element and a random number generator to fill its fields
+// with. The generator is not safe for use from multiple routines.
+func Structs[T any](n int, fill func(r *rand.Rand, i int) T) func() []T {
+	seed := Seed
+	return func() []T {
+		r := rand.New(rand.NewSource(seed))
+		data := make([]T, n)
+		for i := range data {
+			data[i] = fill(r, i)
+		}
+		return data
+	}
+}
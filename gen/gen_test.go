@@ -0,0 +1,55 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/phantom820/streams"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInts(t *testing.T) {
+
+	for _, n := range []int{0, 1, 100} {
+		data := Ints(n)()
+		assert.Len(t, data, n)
+		for _, x := range data {
+			assert.True(t, x >= 0 && x < n)
+		}
+		assert.Equal(t, data, Ints(n)())
+	}
+
+	assert.Equal(t, 100, streams.New(Ints(100)).Parallelize(2).Count())
+
+}
+
+func TestStrings(t *testing.T) {
+
+	data := Strings(50, 8)()
+	assert.Len(t, data, 50)
+	for _, x := range data {
+		assert.Regexp(t, "^[a-z]{8}$", x)
+	}
+	assert.Equal(t, data, Strings(50, 8)())
+	assert.Equal(t, []string{"", ""}, Strings(2, 0)())
+
+}
+
+func TestStructs(t *testing.T) {
+
+	type point struct{ x, y int }
+	fill := func(r *rand.Rand, i int) point { return point{x: i, y: r.Intn(10)} }
+
+	data := Structs(10, fill)()
+	assert.Len(t, data, 10)
+	for i, p := range data {
+		assert.Equal(t, i, p.x)
+	}
+	assert.Equal(t, data, Structs(10, fill)())
+
+	// Sources created with another seed supply other data.
+	defer func(seed int64) { Seed = seed }(Seed)
+	Seed = 2
+	assert.NotEqual(t, data, Structs(10, fill)())
+
+}
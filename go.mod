@@ -5,10 +5,11 @@ go 1.18
 require (
 	github.com/phantom820/collections v0.3.0-alpha.2.7
 	github.com/stretchr/testify v1.7.1
+	golang.org/x/text v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
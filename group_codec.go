@@ -0,0 +1,96 @@
+package streams
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// groupsHeader identifies groups written by CollectTo along with the version of their format.
+const groupsHeader = "streams.groups/1"
+
+// errGroupsFormat is returned by LoadGroups for data that was not written by CollectTo.
+var errGroupsFormat = errors.New("streams: data is not in the format written by CollectTo")
+
+// LoadGroups reads groups written by CollectTo from the given reader, decoding their members using the codec, and returns a grouped stream of them.
+// The groups are read before returning so that an error reading or decoding them is returned instead of failing the evaluation of the stream.
+func LoadGroups[T any](r io.Reader, codec Codec[T]) (GroupedStream[T], error) {
+	if codec == nil {
+		panic(errIllegalArgument("LoadGroups", "nil"))
+	}
+	groups, err := readGroups(bufio.NewReader(r), codec)
+	if err != nil {
+		return nil, err
+	}
+	return &groupedStream[T]{
+		supplier:   func() []Group[T] { return groups },
+		operations: make([]operator[Group[T]], 0),
+	}, nil
+}
+
+// writeGroups writes the header followed by each group as its name, its number of members and the encoded members, all as frames.
+func writeGroups[T any](w io.Writer, groups []Group[T], codec Codec[T]) error {
+	buffer := bufio.NewWriter(w)
+	if err := writeFrame(buffer, []byte(groupsHeader)); err != nil {
+		return err
+	}
+	var count [binary.MaxVarintLen64]byte
+	for _, g := range groups {
+		if err := writeFrame(buffer, []byte(g.name)); err != nil {
+			return err
+		} else if _, err := buffer.Write(count[:binary.PutUvarint(count[:], uint64(len(g.data)))]); err != nil {
+			return err
+		}
+		for _, x := range g.data {
+			data, err := codec.Encode(x)
+			if err != nil {
+				return err
+			} else if err := writeFrame(buffer, data); err != nil {
+				return err
+			}
+		}
+	}
+	return buffer.Flush()
+}
+
+// readGroups reads groups written by writeGroups until the end of the reader.
+func readGroups[T any](r *bufio.Reader, codec Codec[T]) ([]Group[T], error) {
+	header, err := readFrame(r)
+	if err != nil || string(header) != groupsHeader {
+		return nil, errGroupsFormat
+	}
+	groups := make([]Group[T], 0)
+	for {
+		name, err := readFrame(r)
+		if err == io.EOF {
+			return groups, nil
+		} else if err != nil {
+			return nil, err
+		}
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		// The number of members is not trusted for preallocating, a corrupt count would otherwise allocate an arbitrary amount of memory.
+		capacity := n
+		if capacity > 1024 {
+			capacity = 1024
+		}
+		g := Group[T]{name: string(name), data: make([]T, 0, capacity)}
+		for i := uint64(0); i < n; i++ {
+			data, err := readFrame(r)
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			} else if err != nil {
+				return nil, err
+			}
+			x, err := codec.Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			g.data = append(g.data, x)
+		}
+		groups = append(groups, g)
+	}
+}
@@ -0,0 +1,95 @@
+package streams
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// elementFailingCodec a codec which fails to encode or decode the given element.
+type elementFailingCodec struct {
+	fail int
+}
+
+func (c elementFailingCodec) Encode(x int) ([]byte, error) {
+	if x == c.fail {
+		return nil, errors.New("encode failed")
+	}
+	return JSONCodec[int]().Encode(x)
+}
+
+func (c elementFailingCodec) Decode(data []byte) (int, error) {
+	x, err := JSONCodec[int]().Decode(data)
+	if x == c.fail {
+		return 0, errors.New("decode failed")
+	}
+	return x, err
+}
+
+func TestCollectTo(t *testing.T) {
+
+	data := []int{1, 2, 3, 4, 5, 6, 7}
+	key := func(x int) string { return fmt.Sprint(x % 3) }
+	codec := JSONCodec[int]()
+
+	type collectToTest struct {
+		s        GroupedStream[int]
+		expected map[string][]int
+	}
+
+	collectToTests := []collectToTest{
+		{s: New(func() []int { return data }).GroupBy(key), expected: map[string][]int{"0": {3, 6}, "1": {1, 4, 7}, "2": {2, 5}}},
+		{s: New(func() []int { return data }).GroupBy(key).Parallelize(2), expected: map[string][]int{"0": {3, 6}, "1": {1, 4, 7}, "2": {2, 5}}},
+		{s: New(func() []int { return data }).GroupBy(key).Filter(func(g Group[int]) bool { return g.Name() == "0" }), expected: map[string][]int{"0": {3, 6}}},
+		{s: New(func() []int { return []int{} }).GroupBy(key), expected: map[string][]int{}},
+	}
+
+	for _, test := range collectToTests {
+		var buffer bytes.Buffer
+		assert.Nil(t, test.s.CollectTo(&buffer, codec))
+		loaded, err := LoadGroups(&buffer, codec)
+		assert.Nil(t, err)
+		groups := make(map[string][]int)
+		for _, g := range loaded.Collect() {
+			groups[g.Name()] = g.Data()
+		}
+		assert.Equal(t, test.expected, groups)
+	}
+
+	// Loaded groups can be aggregated like any grouped stream.
+	var buffer bytes.Buffer
+	New(func() []int { return data }).GroupBy(key).CollectTo(&buffer, codec)
+	loaded, _ := LoadGroups(&buffer, codec)
+	assert.Equal(t, map[string]int{"0": 9, "1": 12, "2": 7}, loaded.Parallelize(2).Reduce(func(x, y int) int { return x + y }))
+
+	// Errors of the codec.
+	assert.NotNil(t, New(func() []int { return data }).GroupBy(key).CollectTo(io.Discard, elementFailingCodec{fail: 4}))
+	buffer.Reset()
+	New(func() []int { return data }).GroupBy(key).CollectTo(&buffer, codec)
+	_, err := LoadGroups[int](&buffer, elementFailingCodec{fail: 4})
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() { New(func() []int { return data }).GroupBy(key).CollectTo(io.Discard, nil) })
+
+}
+
+func TestLoadGroupsErr(t *testing.T) {
+
+	codec := JSONCodec[int]()
+	var buffer bytes.Buffer
+	New(func() []int { return []int{1, 2, 3} }).GroupBy(func(x int) string { return "a" }).CollectTo(&buffer, codec)
+	written := buffer.Bytes()
+
+	_, err := LoadGroups(bytes.NewReader([]byte("not groups")), codec)
+	assert.Equal(t, errGroupsFormat, err)
+	_, err = LoadGroups(bytes.NewReader(nil), codec)
+	assert.Equal(t, errGroupsFormat, err)
+	_, err = LoadGroups(bytes.NewReader(written[:len(written)-1]), codec)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Panics(t, func() { LoadGroups[int](bytes.NewReader(written), nil) })
+
+}
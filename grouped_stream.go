@@ -2,7 +2,9 @@ package streams
 
 import (
 	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
 )
 
 // GroupedStream a stream in which source elements are grouped.
@@ -16,14 +18,22 @@ type GroupedStream[T any] interface {
 
 	ForEach(f func(x Group[T]))                // Performs an action specified by the function f for each group of the stream.
 	Count() map[string]int                     // Returns a count of the number of elements in each group of the stream.
+	CountLarge() map[string]int64              // Returns a count of the number of elements in each group of the stream as 64 bit integers that saturate instead of overflowing.
 	Aggregate(f func(Group[T]) T) map[string]T // Returns result of aggregating each group in the stream.
+	ToMultiMap() map[string][]T                // Returns a map from the name of each group in the stream to the members of the group.
 	Reduce(f func(x, y T) T) map[string]T      // Returns result of performing reduction on the elements of the groups in the stream, using associative accumulation function, and returns the reduced value.
 	// The zero value is returned if there are no elements.
 
-	Collect() []Group[T]              // Returns a slice containing the elements from the stream.
-	Parallel() bool                   // Returns an indication of whether the stream is parallel.
-	Parallelize(int) GroupedStream[T] // Returns a parallel stream with the given level of parallelism.
+	Collect() []Group[T]                         // Returns a slice containing the elements from the stream.
+	CollectTo(w io.Writer, codec Codec[T]) error // Writes the groups of the stream to the writer in a compact binary format, see LoadGroups.
+	Parallel() bool                              // Returns an indication of whether the stream is parallel.
+	ExecutionInfo() ExecutionInfo                // Returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+	Parallelize(int) GroupedStream[T]            // Returns a parallel stream with the given level of parallelism.
+	ParallelizeWith(p Profile) GroupedStream[T]  // Returns a parallel stream using the given execution profile.
+	Sequential() GroupedStream[T]                // Returns a sequential stream.
+	Rebalance() GroupedStream[T]                 // Returns a stream whose parallel reduction spreads large groups across routines using two phase aggregation.
 
+	DryRun() error    // Checks that the stream can be evaluated without pulling any data from its source.
 	Terminated() bool // Checks if a terminal operation has been invoked on the stream.
 	Closed() bool     // Checks if a stream has been closed. A stream is closed either when a new stream is created from it using intermediate
 	// operations, terminated streams are also closed.
@@ -32,13 +42,14 @@ type GroupedStream[T any] interface {
 
 // groupedStream concrete type for grouped stream/
 type groupedStream[T any] struct {
-	supplier    func() []Group[T]
-	operations  []operator[Group[T]]
-	parallel    bool
-	maxRoutines int
-	distinct    bool
-	terminated  bool
-	closed      bool
+	supplier   func() []Group[T]
+	operations []operator[Group[T]]
+	parallel   bool
+	executor   executor
+	distinct   bool
+	rebalance  bool
+	terminated int32
+	closed     int32
 }
 
 // Group a collection of values with the same name/key identifier.
@@ -47,6 +58,11 @@ type Group[T any] struct {
 	data []T
 }
 
+// NewGroup creates a group with the given name/key and members.
+func NewGroup[T any](name string, data []T) Group[T] {
+	return Group[T]{name: name, data: data}
+}
+
 // Name returns the name/key of the group.
 func (g Group[T]) Name() string {
 	return g.name
@@ -64,34 +80,45 @@ func (g Group[T]) Len() int {
 
 // Closed returns an indication of whether the stream has been closed or not.
 func (s *groupedStream[T]) Closed() bool {
-	return s.closed
+	return atomic.LoadInt32(&s.closed) == 1
 }
 
-// close closes the stream.
-func (s *groupedStream[T]) close() {
-	s.closed = true
+// close closes the stream, an error is returned if the stream has already been closed. Closing is atomic so that only one of many routines
+// deriving streams from the same stream concurrently succeeds.
+func (s *groupedStream[T]) close() *streamError {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		_, err := s.valid()
+		return err
+	}
+	return nil
 }
 
 // Terminated returns an indication of whether the stream has been closed by invoking a terminal operation.
 func (s *groupedStream[T]) Terminated() bool {
-	return s.terminated
+	return atomic.LoadInt32(&s.terminated) == 1
 }
 
-// terminate terminate the stream.
-func (s *groupedStream[T]) terminate() {
-	s.terminated = true
-	s.closed = true
+// terminate terminates the stream, an error is returned if the stream has already been closed.
+func (s *groupedStream[T]) terminate() *streamError {
+	if err := s.close(); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&s.terminated, 1)
+	return nil
 }
 
 // newGroupedStream creates a new stream which adds the given operation.
 func newGroupedStream[T any](s *groupedStream[T], operator operator[Group[T]]) *groupedStream[T] {
-	defer s.close()
+	if err := s.close(); err != nil {
+		panic(err)
+	}
 	return &groupedStream[T]{
-		supplier:    s.supplier,
-		operations:  append(s.operations, operator),
-		parallel:    s.parallel,
-		distinct:    s.distinct,
-		maxRoutines: s.maxRoutines,
+		supplier:   s.supplier,
+		operations: appendOperation(s.operations, operator),
+		parallel:   s.parallel,
+		distinct:   s.distinct,
+		rebalance:  s.rebalance,
+		executor:   s.executor,
 	}
 }
 
@@ -112,54 +139,119 @@ func (s groupedStream[T]) Parallel() bool {
 	return s.parallel
 }
 
-// Parallelize returns a parallel stream with the given level of parallelism
+// ExecutionInfo returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+func (s *groupedStream[T]) ExecutionInfo() ExecutionInfo {
+	return executionInfo(s.parallel, false, s.executor)
+}
+
+// Parallelize returns a parallel stream with the given level of parallelism. Parallelizing a stream that is already parallel replaces its level
+// of parallelism and profile, the last call wins, use Sequential to switch back to sequential evaluation.
 func (s *groupedStream[T]) Parallelize(n int) GroupedStream[T] {
-	if n <= 1 {
-		panic(errIllegalConfig("Parallelism", fmt.Sprint(n)))
+	if err := checkParallelism(n); err != nil {
+		panic(err)
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &groupedStream[T]{
+		supplier:   s.supplier,
+		operations: parallelOperations(s.operations),
+		parallel:   true,
+		distinct:   s.distinct,
+		rebalance:  s.rebalance,
+		executor:   s.executor.configure(executor{maxRoutines: n}),
+	}
+}
+
+// Sequential returns a sequential stream consisting of the groups of this stream.
+func (s *groupedStream[T]) Sequential() GroupedStream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
 	}
 	return &groupedStream[T]{
-		supplier:    s.supplier,
-		operations:  s.operations,
-		parallel:    true,
-		maxRoutines: n,
+		supplier:   s.supplier,
+		operations: s.operations,
+		distinct:   s.distinct,
+		rebalance:  s.rebalance,
+		executor:   s.executor,
+	}
+}
+
+// ParallelizeWith returns a parallel stream whose number of routines and dispatching of elements to them is given by the profile.
+func (s *groupedStream[T]) ParallelizeWith(p Profile) GroupedStream[T] {
+	if p.executor.maxRoutines < 1 {
+		panic(errIllegalConfig("Profile", fmt.Sprint(p)))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &groupedStream[T]{
+		supplier:   s.supplier,
+		operations: parallelOperations(s.operations),
+		parallel:   true,
+		distinct:   s.distinct,
+		rebalance:  s.rebalance,
+		executor:   s.executor.configure(p.executor),
 	}
 }
 
 // Collect returns a slice containing the elements from the stream.
 func (s *groupedStream[T]) Collect() []Group[T] {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	if s.parallel {
-		return parallelCollect(s.supplier(), s.operations, s.maxRoutines)
+		return parallelCollect(s.supplier(), s.operations, s.executor)
 	}
 	return collect(s.supplier(), s.operations)
 }
 
+// CollectTo writes the groups of the stream to the given writer with their members encoded using the codec, so that the intermediate results of a
+// job can be persisted or shared with another process and loaded with LoadGroups. The first error returned by the codec or the writer is returned.
+func (s *groupedStream[T]) CollectTo(w io.Writer, codec Codec[T]) error {
+	if codec == nil {
+		panic(errIllegalArgument("CollectTo", "nil"))
+	}
+	return writeGroups(w, s.Collect(), codec)
+}
+
 // Count returns the count of elements in this stream.
 func (s *groupedStream[T]) Count() map[string]int {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	if s.parallel {
-		return groupParallelCount(s.supplier(), s.maxRoutines)
+		return groupParallelCount(parallelCollect(s.supplier(), s.operations, s.executor), s.executor)
 	}
-	return groupCount(s.supplier())
+	return groupCount(collect(s.supplier(), s.operations))
 
 }
 
+// CountLarge returns the count of elements in each group of this stream as 64 bit integers, i.e for pipelines counting events in the billions. Groups
+// with the same key are added up and a count that would overflow saturates at math.MaxInt64 instead of wrapping around.
+func (s *groupedStream[T]) CountLarge() map[string]int64 {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	if s.parallel {
+		results := make(map[string]int64)
+		for _, partial := range run(parallelCollect(s.supplier(), s.operations, s.executor), s.executor, groupCountLarge[T]) {
+			for key, val := range partial {
+				results[key] = saturatingAdd(results[key], val)
+			}
+		}
+		return results
+	}
+	return groupCountLarge(collect(s.supplier(), s.operations))
+}
+
 // ForEach performs an action for each group of this stream.
 func (s *groupedStream[T]) ForEach(f func(Group[T])) {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	data := s.supplier()
 	operations := s.operations
 	if s.parallel {
-		parallelForEach(data, operations, f, s.maxRoutines)
+		parallelForEach(data, operations, f, s.executor)
 		return
 	}
 	forEach(data, operations, f)
@@ -175,48 +267,106 @@ func (s *groupedStream[T]) Filter(f func(Group[T]) bool) GroupedStream[T] {
 
 // Reduce performs reduction on each group.
 func (s *groupedStream[T]) Reduce(f func(x, y T) T) map[string]T {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
-	if s.parallel {
+	if s.parallel && s.rebalance {
+		return rebalancedReduce(collect(s.supplier(), s.operations), f, s.executor)
+	} else if s.parallel {
 		var mux sync.Mutex
 		results := make(map[string]T)
-		s.ForEach(func(g Group[T]) {
+		parallelForEach(s.supplier(), s.operations, func(g Group[T]) {
+			result, _ := reduce(g.data, make([]operator[T], 0), f)
 			mux.Lock()
 			defer mux.Unlock()
-			result, _ := reduce(g.data, make([]operator[T], 0), f)
 			results[g.name] = result
-		})
+		}, s.executor)
 		return results
 	}
 	results := make(map[string]T)
-	s.ForEach(func(g Group[T]) {
+	forEach(s.supplier(), s.operations, func(g Group[T]) {
 		result, _ := reduce(g.data, make([]operator[T], 0), f)
 		results[g.name] = result
 	})
 	return results
 }
 
+// Rebalance returns a stream whose parallel reduction spreads the elements of large groups across routines, so that a stream in which most elements
+// share a key is still reduced in parallel. Groups are split into salted partitions which are reduced by the routines and the partial results of
+// each group are then combined, this requires the reduction function to be associative. It has no effect on sequential streams or on Aggregate.
+func (s *groupedStream[T]) Rebalance() GroupedStream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &groupedStream[T]{
+		supplier:   s.supplier,
+		operations: s.operations,
+		parallel:   s.parallel,
+		distinct:   s.distinct,
+		rebalance:  true,
+		executor:   s.executor,
+	}
+}
+
 // Aggregate aggregates the data in the group and returns a result.
 func (s *groupedStream[T]) Aggregate(f func(Group[T]) T) map[string]T {
-	if ok, err := s.valid(); !ok {
+	return ToMap[T](s, f)
+}
+
+// ToMultiMap returns a map from the name of each group of the stream to its members.
+func (s *groupedStream[T]) ToMultiMap() map[string][]T {
+	return ToMap(GroupedStream[T](s), Group[T].Data)
+}
+
+// ToMap returns a map from the name of each group of the stream to the result of applying f to the group, the groups of a parallel stream are
+// mapped by its routines.
+func ToMap[T any, V any](s GroupedStream[T], f func(g Group[T]) V) map[string]V {
+	source := s.(*groupedStream[T])
+	if err := source.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
-	if s.parallel {
+	if source.parallel {
 		var mux sync.Mutex
-		results := make(map[string]T)
-		s.ForEach(func(g Group[T]) {
+		results := make(map[string]V)
+		parallelForEach(source.supplier(), source.operations, func(g Group[T]) {
+			result := f(g)
 			mux.Lock()
 			defer mux.Unlock()
-			results[g.name] = f(g)
-		})
+			results[g.name] = result
+		}, source.executor)
 		return results
 	}
-	results := make(map[string]T)
-	s.ForEach(func(g Group[T]) {
+	results := make(map[string]V)
+	forEach(source.supplier(), source.operations, func(g Group[T]) {
 		results[g.name] = f(g)
 	})
 	return results
 }
+
+// Enrich returns a grouped stream in which each group that has an entry in the lookup (by group name) is replaced with the result of merging
+// the group with its entry. Groups without an entry are left as they are.
+func Enrich[T any, E any](s GroupedStream[T], lookup map[string]E, merge func(g Group[T], e E) Group[T]) GroupedStream[T] {
+	source := s.(*groupedStream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return newGroupedStream(source, uniformMap(func(g Group[T]) Group[T] {
+		if e, ok := lookup[g.name]; ok {
+			return merge(g, e)
+		}
+		return g
+	}))
+}
+
+// DryRun checks that the stream can be evaluated without pulling any data from its source, returns an error describing the first problem found
+// i.e an operation created with a nil function.
+func (s *groupedStream[T]) DryRun() error {
+	if ok, err := s.valid(); !ok {
+		return err
+	} else if s.supplier == nil {
+		return errIllegalPlan("nil supplier", "SOURCE", 0)
+	} else if err := plan(s.operations, s.parallel); err != nil {
+		return err
+	}
+	return nil
+}
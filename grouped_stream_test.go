@@ -1,6 +1,8 @@
 package streams
 
 import (
+	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"testing"
@@ -172,3 +174,200 @@ func TestGroupyFilter(t *testing.T) {
 
 	}
 }
+
+func TestGroupByEnrich(t *testing.T) {
+
+	type enrichTest struct {
+		data     []string
+		expected map[string]int
+	}
+
+	enrichTests := []enrichTest{
+		{data: []string{}, expected: map[string]int{}},
+		{data: []string{"c1", "c2", "c1", "c3"}, expected: map[string]int{"alice": 2, "bob": 1, "c3": 1}},
+	}
+
+	customers := map[string]string{"c1": "alice", "c2": "bob"}
+	merge := func(g Group[string], name string) Group[string] {
+		return NewGroup(name, g.Data())
+	}
+
+	for _, test := range enrichTests {
+		a := Enrich(New(func() []string { return test.data }).GroupBy(func(x string) string { return x }), customers, merge).Count()
+		b := Enrich(New(func() []string { return test.data }).GroupBy(func(x string) string { return x }).Parallelize(2), customers, merge).Count()
+
+		assert.Equal(t, test.expected, a)
+		assert.Equal(t, test.expected, b)
+	}
+}
+
+func TestGroupByRebalance(t *testing.T) {
+
+	type rebalanceTest struct {
+		data     []int
+		expected map[string]int
+	}
+
+	skewed := make([]int, 1000)
+	for i := range skewed {
+		skewed[i] = 1
+	}
+	skewed = append(skewed, 2, 3)
+
+	rebalanceTests := []rebalanceTest{
+		{data: []int{}, expected: map[string]int{}},
+		{data: []int{1, 2, 3}, expected: map[string]int{"1": 1, "2": 2, "3": 3}},
+		{data: skewed, expected: map[string]int{"1": 1000, "2": 2, "3": 3}},
+	}
+
+	key := func(x int) string { return fmt.Sprint(x) }
+	sum := func(x, y int) int { return x + y }
+
+	for _, test := range rebalanceTests {
+		a := New(func() []int { return test.data }).GroupBy(key).Rebalance().Reduce(sum)
+		b := New(func() []int { return test.data }).GroupBy(key).Parallelize(4).Rebalance().Reduce(sum)
+		c := New(func() []int { return test.data }).GroupBy(key).Rebalance().Parallelize(4).Reduce(sum)
+
+		assert.Equal(t, test.expected, a)
+		assert.Equal(t, test.expected, b)
+		assert.Equal(t, test.expected, c)
+	}
+
+	// Every routine is given part of the dominant group.
+	var mux sync.Mutex
+	partitions := 0
+	New(func() []int { return skewed }).GroupBy(key).Parallelize(4).Rebalance().Reduce(func(x, y int) int {
+		if x == 1 {
+			mux.Lock()
+			partitions++
+			mux.Unlock()
+		}
+		return x + y
+	})
+	assert.GreaterOrEqual(t, partitions, 4)
+
+}
+
+func TestGroupBySorted(t *testing.T) {
+
+	type groupBySortedTest struct {
+		data     []string
+		expected []Group[string]
+	}
+
+	groupBySortedTests := []groupBySortedTest{
+		{data: []string{}, expected: []Group[string]{}},
+		{data: []string{"a1", "a2", "b1", "c1", "c2", "c3"}, expected: []Group[string]{
+			{name: "a", data: []string{"a1", "a2"}}, {name: "b", data: []string{"b1"}}, {name: "c", data: []string{"c1", "c2", "c3"}}}},
+	}
+
+	key := func(x string) string { return x[:1] }
+
+	for _, test := range groupBySortedTests {
+		a := New(func() []string { return test.data }).GroupBySorted(key).Collect()
+		b := New(func() []string { return test.data }).Parallelize(2).GroupBySorted(key).Collect()
+
+		assert.Equal(t, test.expected, a)
+		assert.ElementsMatch(t, test.expected, b)
+	}
+
+	// A key in runs that are not adjacent is rejected by every terminal operation.
+	unsorted := func() []string { return []string{"a1", "b1", "a2"} }
+	for _, parallel := range []bool{false, true} {
+		s := func() GroupedStream[string] {
+			if parallel {
+				return New(unsorted).Parallelize(2).GroupBySorted(key)
+			}
+			return New(unsorted).GroupBySorted(key)
+		}
+		err := errUnsortedStream("GroupBySorted", "a").Error()
+		assert.PanicsWithError(t, err, func() { s().Collect() })
+		assert.PanicsWithError(t, err, func() { s().Count() })
+		assert.PanicsWithError(t, err, func() { s().CountLarge() })
+		assert.PanicsWithError(t, err, func() { s().ToMultiMap() })
+		assert.PanicsWithError(t, err, func() { s().Reduce(func(x, y string) string { return x + y }) })
+	}
+	assert.Equal(t, "ErrUnsortedStream: The key a appears in runs that are not adjacent for operation GroupBySorted, the elements must be ordered by their key.",
+		errUnsortedStream("GroupBySorted", "a").Error())
+
+	// Runs are not split across the routines of a parallel stream.
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	counts := New(func() []int { return data }).Parallelize(4).GroupBySorted(func(x int) string { return fmt.Sprint(x / 100) }).Count()
+	assert.Equal(t, 10, len(counts))
+	for _, count := range counts {
+		assert.Equal(t, 100, count)
+	}
+
+	// Groups share the memory of the elements.
+	source := []string{"a1", "a2", "b1"}
+	groups := New(func() []string { return source }).GroupBySorted(key).Collect()
+	assert.Equal(t, &source[0], &groups[0].Data()[0])
+
+}
+
+func TestGroupByCountLarge(t *testing.T) {
+
+	type countLargeTest struct {
+		s        GroupedStream[string]
+		expected map[string]int64
+	}
+
+	data := []string{"a1", "b1", "a2", "c1", "a3", "b2"}
+	sorted := []string{"a1", "a2", "a3", "b1", "b2", "c1"}
+	key := func(x string) string { return x[:1] }
+
+	countLargeTests := []countLargeTest{
+		{s: New(func() []string { return []string{} }).GroupBy(key), expected: map[string]int64{}},
+		{s: New(func() []string { return data }).GroupBy(key), expected: map[string]int64{"a": 3, "b": 2, "c": 1}},
+		{s: New(func() []string { return data }).GroupBy(key).Parallelize(2), expected: map[string]int64{"a": 3, "b": 2, "c": 1}},
+		{s: New(func() []string { return sorted }).GroupBySorted(key), expected: map[string]int64{"a": 3, "b": 2, "c": 1}},
+		{s: New(func() []string { return sorted }).Parallelize(3).GroupBySorted(key).Parallelize(2), expected: map[string]int64{"a": 3, "b": 2, "c": 1}},
+	}
+
+	for _, test := range countLargeTests {
+		assert.Equal(t, test.expected, test.s.CountLarge())
+	}
+
+	assert.Equal(t, int64(math.MaxInt64), saturatingAdd(math.MaxInt64-1, 2))
+	assert.Equal(t, int64(math.MaxInt64), saturatingAdd(math.MaxInt64, math.MaxInt64))
+	assert.Equal(t, int64(5), saturatingAdd(2, 3))
+
+}
+
+func TestGroupByToMap(t *testing.T) {
+
+	type toMapTest struct {
+		data     []string
+		expected map[string]int
+		multi    map[string][]string
+	}
+
+	toMapTests := []toMapTest{
+		{data: []string{}, expected: map[string]int{}, multi: map[string][]string{}},
+		{data: []string{"a", "bb", "cc", "d"}, expected: map[string]int{"1": 2, "2": 4},
+			multi: map[string][]string{"1": {"a", "d"}, "2": {"bb", "cc"}}},
+	}
+
+	length := func(x string) string { return fmt.Sprint(len(x)) }
+	letters := func(g Group[string]) int { return len(strings.Join(g.Data(), "")) }
+
+	for _, test := range toMapTests {
+		a := ToMap(New(func() []string { return test.data }).GroupBy(length), letters)
+		b := ToMap(New(func() []string { return test.data }).GroupBy(length).Parallelize(2), letters)
+		c := New(func() []string { return test.data }).GroupBy(length).ToMultiMap()
+		d := New(func() []string { return test.data }).GroupBy(length).Parallelize(2).ToMultiMap()
+
+		assert.Equal(t, test.expected, a)
+		assert.Equal(t, test.expected, b)
+		assert.Equal(t, test.multi, c)
+		assert.Equal(t, test.multi, d)
+	}
+
+	s := New(func() []string { return []string{"a"} }).GroupBy(length)
+	s.ToMultiMap()
+	assert.True(t, s.Terminated())
+	assert.Panics(t, func() { ToMap(s, letters) })
+}
@@ -0,0 +1,77 @@
+package streams
+
+import "hash/maphash"
+
+// Hasher hashes and compares keys, used by hash based operations in place of the built in map hashing.
+type Hasher[K any] interface {
+	Hash(k K) uint64   // Returns the hash of the key, equal keys must have equal hashes.
+	Equal(x, y K) bool // Checks if the given keys are equal.
+}
+
+// maphashHasher a hasher for strings using hash/maphash.
+type maphashHasher struct {
+	seed maphash.Seed
+}
+
+// MaphashHasher returns a hasher for strings using hash/maphash with a random seed.
+func MaphashHasher() Hasher[string] {
+	return maphashHasher{seed: maphash.MakeSeed()}
+}
+
+// Hash returns the hash of the string.
+func (h maphashHasher) Hash(k string) uint64 {
+	var hash maphash.Hash
+	hash.SetSeed(h.seed)
+	hash.WriteString(k)
+	return hash.Sum64()
+}
+
+// Equal checks if the given strings are equal.
+func (h maphashHasher) Equal(x, y string) bool {
+	return x == y
+}
+
+// keyIndex maps keys to indices, the built in map is used if there is no hasher otherwise keys are placed in buckets by their hash.
+type keyIndex struct {
+	hasher  Hasher[string]
+	indices map[string]int
+	buckets map[uint64][]keyIndexEntry
+}
+
+// keyIndexEntry an entry in a bucket of a key index.
+type keyIndexEntry struct {
+	key   string
+	index int
+}
+
+// newKeyIndex creates an empty key index using the given hasher, which may be nil.
+func newKeyIndex(hasher Hasher[string]) *keyIndex {
+	if hasher == nil {
+		return &keyIndex{indices: make(map[string]int)}
+	}
+	return &keyIndex{hasher: hasher, buckets: make(map[uint64][]keyIndexEntry)}
+}
+
+// get returns the index of the given key along with an indication of whether the key is present.
+func (k *keyIndex) get(key string) (int, bool) {
+	if k.hasher == nil {
+		i, ok := k.indices[key]
+		return i, ok
+	}
+	for _, entry := range k.buckets[k.hasher.Hash(key)] {
+		if k.hasher.Equal(entry.key, key) {
+			return entry.index, true
+		}
+	}
+	return 0, false
+}
+
+// put associates the given key with the given index, the key must not already be present.
+func (k *keyIndex) put(key string, index int) {
+	if k.hasher == nil {
+		k.indices[key] = index
+		return
+	}
+	hash := k.hasher.Hash(key)
+	k.buckets[hash] = append(k.buckets[hash], keyIndexEntry{key: key, index: index})
+}
@@ -0,0 +1,63 @@
+package streams
+
+import "container/heap"
+
+// Heap a priority queue of elements in which the least element (according to the given less function) is always at the top. A heap is not safe
+// for concurrent use.
+type Heap[T any] struct {
+	elements *heapElements[T]
+}
+
+// heapElements implements heap.Interface for a slice of elements ordered by less.
+type heapElements[T any] struct {
+	data []T
+	less func(x, y T) bool
+}
+
+func (h *heapElements[T]) Len() int           { return len(h.data) }
+func (h *heapElements[T]) Less(i, j int) bool { return h.less(h.data[i], h.data[j]) }
+func (h *heapElements[T]) Swap(i, j int)      { h.data[i], h.data[j] = h.data[j], h.data[i] }
+func (h *heapElements[T]) Push(x any)         { h.data = append(h.data, x.(T)) }
+func (h *heapElements[T]) Pop() any {
+	n := len(h.data)
+	x := h.data[n-1]
+	var zero T
+	h.data[n-1] = zero
+	h.data = h.data[:n-1]
+	return x
+}
+
+// newHeap creates a heap from the given elements in linear time, the slice is owned by the heap afterwards.
+func newHeap[T any](data []T, less func(x, y T) bool) *Heap[T] {
+	elements := &heapElements[T]{data: data, less: less}
+	heap.Init(elements)
+	return &Heap[T]{elements: elements}
+}
+
+// Len returns the number of elements in the heap.
+func (h *Heap[T]) Len() int {
+	return h.elements.Len()
+}
+
+// Push adds the given element to the heap.
+func (h *Heap[T]) Push(x T) {
+	heap.Push(h.elements, x)
+}
+
+// Peek returns the least element of the heap without removing it, false is returned if the heap is empty.
+func (h *Heap[T]) Peek() (T, bool) {
+	if h.elements.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.elements.data[0], true
+}
+
+// Pop removes and returns the least element of the heap, false is returned if the heap is empty.
+func (h *Heap[T]) Pop() (T, bool) {
+	if h.elements.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(h.elements).(T), true
+}
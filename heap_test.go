@@ -0,0 +1,49 @@
+package streams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHeap(t *testing.T) {
+
+	supplier := func() []int { return []int{5, 3, 8, 1, 9, 2, 7} }
+	less := func(x, y int) bool { return x < y }
+
+	type toHeapTest struct {
+		s        Stream[int]
+		expected []int
+	}
+
+	toHeapTests := []toHeapTest{
+		{s: New(supplier), expected: []int{1, 2, 3, 5, 7, 8, 9}},
+		{s: New(supplier).Parallelize(2), expected: []int{1, 2, 3, 5, 7, 8, 9}},
+		{s: New(supplier).Filter(func(x int) bool { return x > 4 }).Parallelize(3), expected: []int{5, 7, 8, 9}},
+		{s: New(func() []int { return []int{} }), expected: []int{}},
+	}
+
+	for _, test := range toHeapTests {
+		h := test.s.ToHeap(less)
+		assert.Equal(t, len(test.expected), h.Len())
+		results := make([]int, 0)
+		for h.Len() > 0 {
+			top, _ := h.Peek()
+			x, ok := h.Pop()
+			assert.True(t, ok)
+			assert.Equal(t, top, x)
+			results = append(results, x)
+		}
+		assert.Equal(t, test.expected, results)
+		_, ok := h.Pop()
+		assert.False(t, ok)
+	}
+
+	h := New(supplier).ToHeap(func(x, y int) bool { return x > y })
+	h.Push(100)
+	x, _ := h.Peek()
+	assert.Equal(t, 100, x)
+
+	assert.Panics(t, func() { New(supplier).ToHeap(nil) })
+
+}
@@ -0,0 +1,52 @@
+package streams
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Kinds of sources of streams reported by String.
+const (
+	supplierSourceName    = "SUPPLIER"
+	channelSourceName     = "CHANNEL"
+	stoppableSourceName   = "STOPPABLE"
+	listSourceName        = "LIST"
+	heapSourceName        = "HEAP"
+	syncMapSourceName     = "SYNC_MAP"
+	partitionedSourceName = "PARTITIONED"
+	deltaSourceName       = "DELTA"
+	seqSourceName         = "SEQ"
+)
+
+// streamIDs the last identifier assigned to a stream.
+var streamIDs uint64
+
+// withSource returns the given stream with its kind of source set to the given kind.
+func withSource[T any](s Stream[T], kind string) Stream[T] {
+	s.(*stream[T]).origin = kind
+	return s
+}
+
+// ID returns the identifier of the stream, unique amongst the streams of the process. Identifiers are assigned in the order in which they are first
+// requested, a stream derived from this stream has its own identifier.
+func (s *stream[T]) ID() uint64 {
+	if id := atomic.LoadUint64(&s.id); id != 0 {
+		return id
+	}
+	atomic.CompareAndSwapUint64(&s.id, 0, atomic.AddUint64(&streamIDs, 1))
+	return atomic.LoadUint64(&s.id)
+}
+
+// String returns a description of the stream consisting of its identifier, the kind of its source, its operations in order and its parallel settings,
+// i.e Stream[id=3 source=CHANNEL operations=[FILTER LIMIT] parallel=true workers=4].
+func (s *stream[T]) String() string {
+	operations := s.declared()
+	names := make([]string, len(operations))
+	for i, operation := range operations {
+		names[i] = operation.name
+	}
+	info := s.ExecutionInfo()
+	return fmt.Sprintf("Stream[id=%d source=%s operations=[%s] parallel=%t auto=%t workers=%d]", s.ID(), s.origin, strings.Join(names, " "),
+		info.Parallel, info.Auto, info.Workers)
+}
@@ -0,0 +1,53 @@
+package streams
+
+import (
+	"container/list"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+
+	s1 := New(func() []int { return []int{1, 2, 3} })
+	s2 := New(func() []int { return []int{1, 2, 3} })
+	assert.NotZero(t, s1.ID())
+	assert.Equal(t, s1.ID(), s1.ID())
+	assert.NotEqual(t, s1.ID(), s2.ID())
+
+	s3 := s1.Filter(func(x int) bool { return x > 1 })
+	assert.NotEqual(t, s1.ID(), s3.ID())
+
+}
+
+func TestString(t *testing.T) {
+
+	type stringTest struct {
+		s        Stream[int]
+		expected string
+	}
+
+	supplier := func() []int { return []int{1, 2, 3} }
+	channel := make(chan int)
+	close(channel)
+	fromChannel, _ := FromChannel(channel)
+
+	stringTests := []stringTest{
+		{s: New(supplier), expected: "source=SUPPLIER operations=[] parallel=false auto=false workers=1]"},
+		{s: New(supplier).Filter(func(x int) bool { return true }).Limit(2),
+			expected: "source=SUPPLIER operations=[FILTER LIMIT] parallel=false auto=false workers=1]"},
+		{s: New(supplier).Parallelize(4).Map(func(x int) int { return x }),
+			expected: "source=SUPPLIER operations=[MAP] parallel=true auto=false workers=4]"},
+		{s: fromChannel.Skip(1), expected: "source=CHANNEL operations=[SKIP] parallel=false auto=false workers=1]"},
+		{s: FromList[int](list.New()).Sequential(), expected: "source=LIST operations=[] parallel=false auto=false workers=1]"},
+		{s: Map(NewPartitioned(func() <-chan []int { return nil }).FlatMap(), func(x int) int { return x }),
+			expected: "source=PARTITIONED operations=[] parallel=false auto=false workers=1]"},
+	}
+
+	for _, test := range stringTests {
+		assert.Contains(t, test.s.String(), test.expected)
+		assert.Contains(t, test.s.String(), "Stream[id=")
+		assert.False(t, test.s.Closed())
+	}
+
+}
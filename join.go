@@ -0,0 +1,10 @@
+//go:build go1.20
+
+package streams
+
+import "errors"
+
+// joinErrors joins the given errors into one error that wraps all of them, see errors.Join.
+func joinErrors(errs []error) error {
+	return errors.Join(errs...)
+}
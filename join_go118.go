@@ -0,0 +1,32 @@
+//go:build !go1.20
+
+package streams
+
+import "bytes"
+
+// joinedError errors joined into one, its message is the messages of the errors separated by newlines as for errors.Join of Go 1.20.
+type joinedError struct {
+	errs []error
+}
+
+// joinErrors joins the given errors into one error that wraps all of them.
+func joinErrors(errs []error) error {
+	return &joinedError{errs: errs}
+}
+
+// Error returns the error message.
+func (err *joinedError) Error() string {
+	var buffer bytes.Buffer
+	for i, e := range err.errs {
+		if i > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString(e.Error())
+	}
+	return buffer.String()
+}
+
+// Unwrap returns the joined errors.
+func (err *joinedError) Unwrap() []error {
+	return err.errs
+}
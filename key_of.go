@@ -0,0 +1,101 @@
+package streams
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// keyOfFields caches the field indices resolved for a field path of a type.
+var keyOfFields sync.Map
+
+// keyOfField identifies a field path of a type.
+type keyOfField struct {
+	t    reflect.Type
+	path string
+}
+
+// KeyOf returns a key function which reads the field at the given dot separated path (i.e "Address.City") of elements, for use with GroupBy and
+// Distinct. A segment of the path matches a field by its name or by the value of its `stream` struct tag, pointers are followed and a nil pointer
+// results in an empty key. Fields are resolved using reflection once per type. KeyOf panics if the path does not exist on T, or on the element
+// type in the case of interfaces once the key function is invoked.
+func KeyOf[T any](fieldPath string) func(x T) string {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Interface {
+		if _, err := resolveFieldPath(t, fieldPath); err != nil {
+			panic(err)
+		}
+	}
+	return func(x T) string {
+		v := reflect.ValueOf(x)
+		if !v.IsValid() {
+			return ""
+		}
+		indices, err := resolveFieldPath(v.Type(), fieldPath)
+		if err != nil {
+			panic(err)
+		}
+		for _, i := range indices {
+			for v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return ""
+				}
+				v = v.Elem()
+			}
+			v = v.Field(i)
+		}
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.String {
+			return v.String()
+		}
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// resolveFieldPath returns the indices of the fields along the given path of the given type.
+func resolveFieldPath(t reflect.Type, path string) ([]int, *streamError) {
+	key := keyOfField{t: t, path: path}
+	if indices, ok := keyOfFields.Load(key); ok {
+		return indices.([]int), nil
+	}
+	indices := make([]int, 0)
+	for _, segment := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, errIllegalArgument(path, "KeyOf")
+		}
+		i := fieldIndex(t, segment)
+		if i < 0 {
+			return nil, errIllegalArgument(path, "KeyOf")
+		}
+		indices = append(indices, i)
+		t = t.Field(i).Type
+	}
+	keyOfFields.Store(key, indices)
+	return indices, nil
+}
+
+// fieldIndex returns the index of the exported field of the struct type with the given name or stream tag, -1 is returned if there is none.
+func fieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		} else if field.Tag.Get("stream") == name {
+			return i
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.PkgPath == "" && field.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,222 @@
+package streams
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// GroupedStream2 a stream in which source elements are grouped by keys of any comparable type, see GroupBy.
+type GroupedStream2[K comparable, T any] interface {
+	Filter(f func(x KeyedGroup[K, T]) bool) GroupedStream2[K, T] // Returns a stream consisting of the groups of this stream that satisfy the given predicate.
+
+	ForEach(f func(x KeyedGroup[K, T]))             // Performs an action specified by the function f for each group of the stream.
+	Count() map[K]int                               // Returns a count of the number of elements in each group of the stream.
+	Aggregate(f func(x KeyedGroup[K, T]) T) map[K]T // Returns result of aggregating each group in the stream.
+	Reduce(f func(x, y T) T) map[K]T                // Returns result of performing reduction on the elements of each group in the stream.
+	Collect() []KeyedGroup[K, T]                    // Returns a slice containing the groups of the stream.
+	Parallel() bool                                 // Returns an indication of whether the stream is parallel.
+	Parallelize(n int) GroupedStream2[K, T]         // Returns a parallel stream with the given level of parallelism.
+	Sequential() GroupedStream2[K, T]               // Returns a sequential stream.
+	Terminated() bool                               // Checks if a terminal operation has been invoked on the stream.
+	Closed() bool                                   // Checks if a stream has been closed. A stream is closed either when a new stream is created from it using intermediate
+	// operations, terminated streams are also closed.
+}
+
+// KeyedGroup a collection of values with the same key.
+type KeyedGroup[K comparable, T any] struct {
+	key  K
+	data []T
+}
+
+// Key returns the key of the group.
+func (g KeyedGroup[K, T]) Key() K {
+	return g.key
+}
+
+// Data returns all members of the group.
+func (g KeyedGroup[K, T]) Data() []T {
+	return g.data
+}
+
+// Len returns the size of the group.
+func (g KeyedGroup[K, T]) Len() int {
+	return len(g.data)
+}
+
+// keyedGroupedStream concrete type for a grouped stream with typed keys.
+type keyedGroupedStream[K comparable, T any] struct {
+	supplier   func() []KeyedGroup[K, T]
+	operations []operator[KeyedGroup[K, T]]
+	parallel   bool
+	executor   executor
+	terminated int32
+	closed     int32
+}
+
+// GroupBy transforms the stream to a grouped stream using the given key function to assign an element to a group, unlike the GroupBy method of a
+// stream keys may be of any comparable type. Groups are in the order of the first element of each group for a sequential stream, the routines of a
+// parallel stream group the elements of their partitions and the groups of the partitions are then merged.
+func GroupBy[T any, K comparable](s Stream[T], key func(x T) K) GroupedStream2[K, T] {
+	source := s.(*stream[T])
+	if key == nil {
+		panic(errIllegalArgument("GroupBy", "nil"))
+	} else if err := source.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, e := source.source(), source.operations, source.executor
+	if source.parallel {
+		return &keyedGroupedStream[K, T]{
+			supplier:   func() []KeyedGroup[K, T] { return parallelGroupByKey(supplier(), operations, key, e) },
+			operations: make([]operator[KeyedGroup[K, T]], 0),
+			parallel:   true,
+			executor:   e,
+		}
+	}
+	return &keyedGroupedStream[K, T]{
+		supplier:   func() []KeyedGroup[K, T] { return groupByKey(supplier(), operations, key) },
+		operations: make([]operator[KeyedGroup[K, T]], 0),
+		executor:   e,
+	}
+}
+
+// Closed returns an indication of whether the stream has been closed or not.
+func (s *keyedGroupedStream[K, T]) Closed() bool {
+	return atomic.LoadInt32(&s.closed) == 1
+}
+
+// close closes the stream, an error is returned if the stream has already been closed.
+func (s *keyedGroupedStream[K, T]) close() *streamError {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		_, err := s.valid()
+		return err
+	}
+	return nil
+}
+
+// Terminated returns an indication of whether the stream has been closed by invoking a terminal operation.
+func (s *keyedGroupedStream[K, T]) Terminated() bool {
+	return atomic.LoadInt32(&s.terminated) == 1
+}
+
+// terminate terminates the stream, an error is returned if the stream has already been closed.
+func (s *keyedGroupedStream[K, T]) terminate() *streamError {
+	if err := s.close(); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&s.terminated, 1)
+	return nil
+}
+
+// valid checks if a stream is valid before performing any type of operation.
+func (s *keyedGroupedStream[K, T]) valid() (bool, *streamError) {
+	if s.Terminated() {
+		err := errStreamTerminated()
+		return false, &err
+	} else if s.Closed() {
+		err := errStreamClosed()
+		return false, &err
+	}
+	return true, nil
+}
+
+// Parallel returns an indication of whether the stream is parallel.
+func (s *keyedGroupedStream[K, T]) Parallel() bool {
+	return s.parallel
+}
+
+// Parallelize returns a parallel stream with the given level of parallelism, the groups of the stream are evaluated by the given number of routines.
+func (s *keyedGroupedStream[K, T]) Parallelize(n int) GroupedStream2[K, T] {
+	if err := checkParallelism(n); err != nil {
+		panic(err)
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &keyedGroupedStream[K, T]{
+		supplier:   s.supplier,
+		operations: parallelOperations(s.operations),
+		parallel:   true,
+		executor:   s.executor.configure(executor{maxRoutines: n}),
+	}
+}
+
+// Sequential returns a sequential stream consisting of the groups of this stream.
+func (s *keyedGroupedStream[K, T]) Sequential() GroupedStream2[K, T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &keyedGroupedStream[K, T]{
+		supplier:   s.supplier,
+		operations: s.operations,
+		executor:   s.executor,
+	}
+}
+
+// Filter returns a stream consisting of the groups of this stream that match the given predicate.
+func (s *keyedGroupedStream[K, T]) Filter(f func(x KeyedGroup[K, T]) bool) GroupedStream2[K, T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &keyedGroupedStream[K, T]{
+		supplier:   s.supplier,
+		operations: appendOperation(s.operations, filter(f)),
+		parallel:   s.parallel,
+		executor:   s.executor,
+	}
+}
+
+// Collect returns a slice containing the groups of the stream.
+func (s *keyedGroupedStream[K, T]) Collect() []KeyedGroup[K, T] {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	if s.parallel {
+		return parallelCollect(s.supplier(), s.operations, s.executor)
+	}
+	return collect(s.supplier(), s.operations)
+}
+
+// ForEach performs an action for each group of this stream.
+func (s *keyedGroupedStream[K, T]) ForEach(f func(x KeyedGroup[K, T])) {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	if s.parallel {
+		parallelForEach(s.supplier(), s.operations, f, s.executor)
+		return
+	}
+	forEach(s.supplier(), s.operations, f)
+}
+
+// Count returns the number of elements in each group of this stream.
+func (s *keyedGroupedStream[K, T]) Count() map[K]int {
+	return aggregateGroups(s, func(g KeyedGroup[K, T]) int { return g.Len() })
+}
+
+// Aggregate aggregates the elements of each group of this stream using f.
+func (s *keyedGroupedStream[K, T]) Aggregate(f func(x KeyedGroup[K, T]) T) map[K]T {
+	return aggregateGroups(s, f)
+}
+
+// Reduce performs reduction on the elements of each group of this stream.
+func (s *keyedGroupedStream[K, T]) Reduce(f func(x, y T) T) map[K]T {
+	return aggregateGroups(s, func(g KeyedGroup[K, T]) T {
+		result, _ := reduce(g.data, make([]operator[T], 0), f)
+		return result
+	})
+}
+
+// aggregateGroups terminates the stream and returns the result of f for each group keyed by the key of the group, groups are aggregated by the
+// routines of a parallel stream.
+func aggregateGroups[K comparable, T any, R any](s *keyedGroupedStream[K, T], f func(g KeyedGroup[K, T]) R) map[K]R {
+	var mux sync.Mutex
+	results := make(map[K]R)
+	s.ForEach(func(g KeyedGroup[K, T]) {
+		result := f(g)
+		mux.Lock()
+		defer mux.Unlock()
+		results[g.key] = result
+	})
+	return results
+}
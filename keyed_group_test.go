@@ -0,0 +1,68 @@
+package streams
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByKey(t *testing.T) {
+
+	type groupByKeyTest struct {
+		data      []int
+		collected []KeyedGroup[int, int]
+		counts    map[int]int
+		sums      map[int]int
+	}
+
+	groupByKeyTests := []groupByKeyTest{
+		{data: []int{}, collected: []KeyedGroup[int, int]{}, counts: map[int]int{}, sums: map[int]int{}},
+		{
+			data:      []int{1, 2, 3, 4, 5, 6, 7},
+			collected: []KeyedGroup[int, int]{{key: 1, data: []int{1, 4, 7}}, {key: 2, data: []int{2, 5}}, {key: 0, data: []int{3, 6}}},
+			counts:    map[int]int{0: 2, 1: 3, 2: 2},
+			sums:      map[int]int{0: 9, 1: 12, 2: 7},
+		},
+	}
+
+	mod := func(x int) int { return x % 3 }
+	sum := func(x, y int) int { return x + y }
+	sorted := func(groups []KeyedGroup[int, int]) []KeyedGroup[int, int] {
+		for _, g := range groups {
+			sort.Ints(g.data)
+		}
+		return groups
+	}
+
+	for _, test := range groupByKeyTests {
+		assert.Equal(t, test.collected, GroupBy(New(func() []int { return test.data }), mod).Collect())
+		assert.ElementsMatch(t, test.collected, sorted(GroupBy(New(func() []int { return test.data }).Parallelize(2), mod).Collect()))
+		assert.ElementsMatch(t, test.collected, sorted(GroupBy(New(func() []int { return test.data }), mod).Parallelize(2).Collect()))
+
+		assert.Equal(t, test.counts, GroupBy(New(func() []int { return test.data }), mod).Count())
+		assert.Equal(t, test.counts, GroupBy(New(func() []int { return test.data }).Parallelize(2), mod).Count())
+
+		assert.Equal(t, test.sums, GroupBy(New(func() []int { return test.data }), mod).Reduce(sum))
+		assert.Equal(t, test.sums, GroupBy(New(func() []int { return test.data }).Parallelize(2), mod).Reduce(sum))
+		assert.Equal(t, test.counts, GroupBy(New(func() []int { return test.data }).Parallelize(2), mod).Sequential().Aggregate(
+			func(g KeyedGroup[int, int]) int { return g.Len() }))
+	}
+
+	s := GroupBy(New(func() []int { return []int{1, 2, 3, 4, 5, 6, 7} }).Filter(func(x int) bool { return x > 1 }), mod)
+	filtered := s.Filter(func(g KeyedGroup[int, int]) bool { return g.Key() != 0 })
+	assert.True(t, s.Closed())
+	assert.Equal(t, []KeyedGroup[int, int]{{key: 2, data: []int{2, 5}}, {key: 1, data: []int{4, 7}}}, filtered.Collect())
+	assert.True(t, filtered.Terminated())
+	assert.Panics(t, func() { filtered.Count() })
+	assert.Panics(t, func() { GroupBy[int, int](New(func() []int { return []int{} }), nil) })
+
+	type user struct {
+		name string
+		age  int
+	}
+	users := []user{{"a", 30}, {"b", 40}, {"c", 30}}
+	byAge := GroupBy(New(func() []user { return users }), func(x user) int { return x.age }).Collect()
+	assert.Equal(t, 30, byAge[0].Key())
+	assert.Equal(t, []user{{"a", 30}, {"c", 30}}, byAge[0].Data())
+}
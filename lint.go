@@ -0,0 +1,78 @@
+package streams
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// Warning an operation of a stream that is obviously redundant or discards all elements, reported by Lint.
+type Warning struct {
+	Operation string // Name of the operation, i.e LIMIT.
+	Position  int    // Position of the operation amongst the operations of the stream, starting from 0.
+	Reason    string // Description of the problem.
+}
+
+// String returns a description of the warning.
+func (w Warning) String() string {
+	return fmt.Sprintf("%s at position %d: %s", w.Operation, w.Position, w.Reason)
+}
+
+// Identity returns the given element, a Map with Identity leaves every element unchanged and is reported by Lint.
+func Identity[T any](x T) T {
+	return x
+}
+
+// identityName the name of Identity, which is the same for all its instantiations.
+var identityName = functionName(Identity[struct{}])
+
+// functionName returns the name of the given function, functions created from a function literal are named after the enclosing function.
+func functionName(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// closure returns the address of the closure of the given function. Functions with the same address are provably the same function with the same
+// captured variables, functions created from the same function literal with different captured variables have different addresses.
+func closure[F any](f F) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f))
+}
+
+// lint returns warnings for the operations that are redundant (a Distinct following a Distinct with the same hash function or a Map with Identity)
+// or that discard every element (Limit(0) or a Skip following a Limit that it skips entirely). Hash functions are only reported as the same if they
+// are provably the same, see closure.
+func lint[T any](operations []operator[T]) []Warning {
+	warnings := make([]Warning, 0)
+	hashes := make(map[unsafe.Pointer]bool)
+	limited := -1
+	for i, operation := range operations {
+		switch operation.name {
+		case distinctOperatorName:
+			if operation.hash == nil {
+				continue
+			}
+			hash := closure(operation.hash)
+			if hashes[hash] {
+				warnings = append(warnings, Warning{Operation: operation.name, Position: i, Reason: "the stream is already distinct by the same hash function"})
+			}
+			hashes[hash] = true
+		case mapOperatorName:
+			if operation.mapping != nil && functionName(operation.mapping) == identityName {
+				warnings = append(warnings, Warning{Operation: operation.name, Position: i, Reason: "Map(Identity) leaves every element unchanged"})
+			}
+		case limitOperatorName:
+			if operation.arg == 0 {
+				warnings = append(warnings, Warning{Operation: operation.name, Position: i, Reason: "Limit(0) discards every element"})
+			}
+			if limited == -1 || operation.arg < limited {
+				limited = operation.arg
+			}
+		case skipOperatorName:
+			if limited != -1 && operation.arg >= limited && limited > 0 {
+				warnings = append(warnings, Warning{Operation: operation.name, Position: i,
+					Reason: fmt.Sprintf("Skip(%d) after Limit(%d) discards every element", operation.arg, limited)})
+			}
+		}
+	}
+	return warnings
+}
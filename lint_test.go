@@ -0,0 +1,48 @@
+package streams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+
+	supplier := func() []string { return []string{"a", "b", "a"} }
+	hash := func(x string) string { return x }
+	upper := func(x string) string { return x + "!" }
+	suffix := func(s string) func(x string) string { return func(x string) string { return x + s } }
+	byA := suffix("a")
+
+	type lintTest struct {
+		s        Stream[string]
+		expected []Warning
+	}
+
+	lintTests := []lintTest{
+		{s: New(supplier), expected: []Warning{}},
+		{s: New(supplier).Distinct(hash).Limit(2).Skip(1), expected: []Warning{}},
+		{s: New(supplier).Distinct(hash).Map(upper).Distinct(hash), expected: []Warning{
+			{Operation: distinctOperatorName, Position: 2, Reason: "the stream is already distinct by the same hash function"}}},
+		{s: New(supplier).Distinct(hash).Distinct(upper), expected: []Warning{}},
+		{s: New(supplier).Parallelize(2).Limit(0), expected: []Warning{
+			{Operation: limitOperatorName, Position: 0, Reason: "Limit(0) discards every element"}}},
+		{s: New(supplier).Limit(2).Filter(func(x string) bool { return true }).Skip(2), expected: []Warning{
+			{Operation: skipOperatorName, Position: 2, Reason: "Skip(2) after Limit(2) discards every element"}}},
+		{s: New(supplier).Skip(5).Limit(2), expected: []Warning{}},
+		{s: New(supplier).Distinct(suffix("a")).Distinct(suffix("b")), expected: []Warning{}},
+		{s: New(supplier).Distinct(byA).Distinct(byA), expected: []Warning{
+			{Operation: distinctOperatorName, Position: 1, Reason: "the stream is already distinct by the same hash function"}}},
+		{s: New(supplier).Filter(func(x string) bool { return true }).Map(Identity[string]), expected: []Warning{
+			{Operation: mapOperatorName, Position: 1, Reason: "Map(Identity) leaves every element unchanged"}}},
+		{s: New(supplier).Map(func(x string) string { return x }).Map(upper), expected: []Warning{}},
+	}
+
+	for _, test := range lintTests {
+		assert.Equal(t, test.expected, test.s.Lint())
+		assert.False(t, test.s.Closed())
+	}
+
+	assert.Equal(t, "LIMIT at position 0: Limit(0) discards every element", New(supplier).Limit(0).Lint()[0].String())
+
+}
@@ -0,0 +1,6 @@
+//go:build !go1.23
+
+package streams
+
+// sequenced the methods of a stream that rely on range-over-func iteration, which requires Go 1.23 (see FromSeq).
+type sequenced[T any] interface{}
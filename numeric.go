@@ -0,0 +1,116 @@
+package streams
+
+import "math"
+
+// Float a floating point type whose streams can be summed with Sum, Average and Stats.
+type Float interface {
+	~float32 | ~float64
+}
+
+// numericOptions options for summarizing the elements of a stream of floating point numbers.
+type numericOptions struct {
+	kahan      bool
+	ignoreNaN  bool
+	errOnEmpty bool
+}
+
+// NumericOption an option for summarizing the elements of a stream of floating point numbers with Sum, Average or Stats.
+type NumericOption func(o *numericOptions)
+
+// Kahan returns an option which sums elements using Kahan compensated summation, so that the error of the sum does not grow with the number of
+// elements, at the cost of a few more operations per element.
+func Kahan() NumericOption {
+	return func(o *numericOptions) {
+		o.kahan = true
+	}
+}
+
+// IgnoreNaN returns an option which leaves NaN elements out, by default a NaN element makes the result NaN.
+func IgnoreNaN() NumericOption {
+	return func(o *numericOptions) {
+		o.ignoreNaN = true
+	}
+}
+
+// ErrorOnEmpty returns an option which returns an error with code EmptyStream for a stream without elements (after NaN elements have been left out
+// if IgnoreNaN is given), by default the result of an empty stream is zero.
+func ErrorOnEmpty() NumericOption {
+	return func(o *numericOptions) {
+		o.errOnEmpty = true
+	}
+}
+
+// FloatStats statistics of a stream of floating point numbers.
+type FloatStats struct {
+	Count int     // Number of elements summarized.
+	Sum   float64 // Sum of the elements.
+	Mean  float64 // Arithmetic mean of the elements.
+	Min   float64 // Least element.
+	Max   float64 // Greatest element.
+}
+
+// Sum returns the sum of the elements of the stream. The elements are accumulated as float64 and converted back to T.
+func Sum[T Float](s Stream[T], opts ...NumericOption) (T, error) {
+	stats, err := summarize(s, "Sum", opts)
+	return T(stats.Sum), err
+}
+
+// Average returns the arithmetic mean of the elements of the stream. The elements are accumulated as float64 and converted back to T.
+func Average[T Float](s Stream[T], opts ...NumericOption) (T, error) {
+	stats, err := summarize(s, "Average", opts)
+	return T(stats.Mean), err
+}
+
+// Stats returns the number, sum, mean, least and greatest of the elements of the stream. The elements of a parallel stream are evaluated by its
+// routines and summed in the order in which they are collected, which varies between evaluations, use Kahan for the sum to be accurate regardless.
+// The statistics of an empty stream are zero.
+func Stats[T Float](s Stream[T], opts ...NumericOption) (FloatStats, error) {
+	return summarize(s, "Stats", opts)
+}
+
+// summarize returns the statistics of the elements of the stream, the error for an empty stream names the given operation.
+func summarize[T Float](s Stream[T], operation string, opts []NumericOption) (FloatStats, error) {
+	o := numericOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	a := accumulator{min: math.Inf(1), max: math.Inf(-1)}
+	for _, x := range s.Collect() {
+		if f := float64(x); !math.IsNaN(f) || !o.ignoreNaN {
+			a.add(f, o.kahan)
+		}
+	}
+	if a.count == 0 {
+		if o.errOnEmpty {
+			return FloatStats{}, errEmptyStream(operation)
+		}
+		return FloatStats{}, nil
+	}
+	return FloatStats{Count: a.count, Sum: a.sum, Mean: a.sum / float64(a.count), Min: a.min, Max: a.max}, nil
+}
+
+// accumulator accumulates the statistics of floating point numbers.
+type accumulator struct {
+	count        int
+	sum          float64
+	compensation float64 // Low order bits lost by the sum, for Kahan summation.
+	min, max     float64
+}
+
+// add adds the given number to the statistics, using Kahan summation if kahan is true. A NaN makes the sum, least and greatest NaN.
+func (a *accumulator) add(x float64, kahan bool) {
+	a.count++
+	if math.IsNaN(x) || math.IsNaN(a.sum) {
+		a.sum, a.min, a.max = math.NaN(), math.NaN(), math.NaN()
+		return
+	} else if kahan && !math.IsInf(x, 0) && !math.IsInf(a.sum, 0) {
+		y := x - a.compensation
+		t := a.sum + y
+		a.compensation = (t - a.sum) - y
+		a.sum = t
+	} else {
+		a.sum += x
+	}
+	a.min = math.Min(a.min, x)
+	a.max = math.Max(a.max, x)
+}
@@ -0,0 +1,90 @@
+package streams
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+
+	type statsTest struct {
+		data     []float64
+		opts     []NumericOption
+		expected FloatStats
+	}
+
+	var statsTests = []statsTest{
+		{data: []float64{}, expected: FloatStats{}},
+		{data: []float64{1, 2, 3, 6}, expected: FloatStats{Count: 4, Sum: 12, Mean: 3, Min: 1, Max: 6}},
+		{data: []float64{-1.5, 0.5}, opts: []NumericOption{Kahan()}, expected: FloatStats{Count: 2, Sum: -1, Mean: -0.5, Min: -1.5, Max: 0.5}},
+		{data: []float64{1, math.NaN(), 3}, opts: []NumericOption{IgnoreNaN()}, expected: FloatStats{Count: 2, Sum: 4, Mean: 2, Min: 1, Max: 3}},
+		{data: []float64{math.NaN()}, opts: []NumericOption{IgnoreNaN()}, expected: FloatStats{}},
+	}
+
+	for _, test := range statsTests {
+		s1, s2 := New(func() []float64 { return test.data }), New(func() []float64 { return test.data }).Parallelize(2)
+		stats, err := Stats(s1, test.opts...)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, stats)
+		stats, err = Stats(s2, test.opts...)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, stats)
+	}
+
+	// A NaN element makes the result NaN unless NaN elements are ignored.
+	stats, err := Stats(New(func() []float64 { return []float64{1, math.NaN(), 3} }))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, stats.Count)
+	assert.True(t, math.IsNaN(stats.Sum))
+	assert.True(t, math.IsNaN(stats.Mean))
+	assert.True(t, math.IsNaN(stats.Min))
+
+	// An empty stream is an error if requested.
+	_, err = Stats(New(func() []float64 { return []float64{math.NaN()} }), IgnoreNaN(), ErrorOnEmpty())
+	assert.Equal(t, "ErrEmptyStream: The stream has no elements for operation Stats.", err.Error())
+	assert.Equal(t, EmptyStream, err.(Error).Code())
+
+}
+
+func TestSum(t *testing.T) {
+
+	// Kahan summation keeps the low order bits that naive summation loses.
+	data := make([]float64, 0, 10001)
+	data = append(data, 1)
+	for i := 0; i < 10000; i++ {
+		data = append(data, 1e-16)
+	}
+	naive, err := Sum(New(func() []float64 { return data }))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, naive)
+	compensated, err := Sum(New(func() []float64 { return data }), Kahan())
+	assert.Nil(t, err)
+	assert.InDelta(t, 1+1e-12, compensated, 1e-15)
+
+	type celsius float32
+	sum, err := Sum(New(func() []celsius { return []celsius{20.5, 21.5} }).Parallelize(2))
+	assert.Nil(t, err)
+	assert.Equal(t, celsius(42), sum)
+
+	sum, err = Sum(New[celsius](nil), ErrorOnEmpty())
+	assert.Equal(t, celsius(0), sum)
+	assert.Equal(t, "ErrEmptyStream: The stream has no elements for operation Sum.", err.Error())
+
+}
+
+func TestAverage(t *testing.T) {
+
+	average, err := Average(New(func() []float64 { return []float64{1, 2, 3, 4} }).Parallelize(2))
+	assert.Nil(t, err)
+	assert.Equal(t, 2.5, average)
+
+	average, err = Average(New[float64](nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, average)
+
+	_, err = Average(New[float64](nil), ErrorOnEmpty())
+	assert.Equal(t, "ErrEmptyStream: The stream has no elements for operation Average.", err.Error())
+
+}
@@ -2,29 +2,55 @@ package streams
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	filterOperatorName   = "FILTER"
-	peekOperatorName     = "PEEK"
-	mapOperatorName      = "MAP"
-	skipOperatorName     = "SKIP"
-	limitOperatorName    = "LIMIT"
-	distinctOperatorName = "DISTINCT"
+	filterOperatorName        = "FILTER"
+	peekOperatorName          = "PEEK"
+	mapOperatorName           = "MAP"
+	skipOperatorName          = "SKIP"
+	limitOperatorName         = "LIMIT"
+	distinctOperatorName      = "DISTINCT"
+	progressOperatorName      = "PROGRESS"
+	internOperatorName        = "INTERN"
+	limitUntilOperatorName    = "LIMIT_UNTIL"
+	limitDurationOperatorName = "LIMIT_DURATION"
+	tryMapOperatorName        = "TRY_MAP"
+	tryFilterOperatorName     = "TRY_FILTER"
 )
 
 // operator type to represent an intermediate stream operation.
 type operator[T any] struct {
-	apply    func(x T) (T, bool)
-	name     string
-	stateful bool
+	apply       func(x T) (T, bool)
+	name        string
+	stateful    bool
+	concurrent  bool               // Indicates whether the state of a stateful operator is safe for access from multiple routines.
+	undefined   bool               // Indicates the operator was created with a nil function.
+	parallelize func() operator[T] // Returns an equivalent operator that is safe for access from multiple routines, set on stateful operators created for a sequential stream.
+	hash        func(x T) string   // Hash function of a distinct operator, used to fuse it with a following sort.
+	exhausted   func() bool        // Reports whether no more elements can pass the operator, nil if the operator never stops passing elements.
+	arg         int                // Number of elements of a Limit or Skip operator, reported by Lint.
+	mapping     func(x T) T        // Function of a Map operator, used by Lint to report a Map with Identity.
+	internal    bool               // Indicates the operator is added by the evaluation of a stream, i.e to collect statistics, so it has no position.
+	start       func()             // Invoked when the evaluation of the stream starts, nil if the operator does not depend on it.
 }
 
 // extendOperator extends an operator from acting on a single element to a slice of elements.
 func extendOperator[T any](f operator[T]) operator[[]T] {
+	var parallelize func() operator[[]T]
+	if f.parallelize != nil {
+		parallelize = func() operator[[]T] { return extendOperator(f.parallelize()) }
+	}
 	return operator[[]T]{
-		name:     f.name,
-		stateful: f.stateful,
+		parallelize: parallelize,
+		name:        f.name,
+		stateful:    f.stateful,
+		concurrent:  f.concurrent,
+		undefined:   f.undefined,
+		exhausted:   f.exhausted,
+		start:       f.start,
 		apply: func(values []T) ([]T, bool) {
 			results := make([]T, 0)
 			for _, val := range values {
@@ -41,8 +67,9 @@ func extendOperator[T any](f operator[T]) operator[[]T] {
 // filter returnf filter operator with the given predicate.
 func filter[T any](f func(T) bool) operator[T] {
 	return operator[T]{
-		apply: func(x T) (T, bool) { return x, f(x) },
-		name:  filterOperatorName,
+		apply:     func(x T) (T, bool) { return x, f(x) },
+		name:      filterOperatorName,
+		undefined: f == nil,
 	}
 }
 
@@ -53,7 +80,35 @@ func peek[T any](f func(T)) operator[T] {
 			f(x)
 			return x, true
 		},
-		name: peekOperatorName,
+		name:      peekOperatorName,
+		undefined: f == nil,
+	}
+}
+
+// progress returns progress operator which reports the number of elements that have reached it so far, counts are shared across routines.
+func progress[T any](f func(done int)) operator[T] {
+	var done int64
+	return operator[T]{
+		apply: func(x T) (T, bool) {
+			f(int(atomic.AddInt64(&done, 1)))
+			return x, true
+		},
+		name:      progressOperatorName,
+		undefined: f == nil,
+	}
+}
+
+// intern returns intern operator which replaces the string of each element (read using get and replaced using set) with an equal instance
+// shared through a pool that is safe for concurrent use.
+func intern[T any](get func(T) string, set func(T, string) T) operator[T] {
+	var pool sync.Map
+	return operator[T]{
+		apply: func(x T) (T, bool) {
+			shared, _ := pool.LoadOrStore(get(x), get(x))
+			return set(x, shared.(string)), true
+		},
+		name:      internOperatorName,
+		undefined: get == nil || set == nil,
 	}
 }
 
@@ -63,15 +118,61 @@ func uniformMap[T any](f func(T) T) operator[T] {
 		apply: func(x T) (T, bool) {
 			return f(x), true
 		},
-		name: mapOperatorName,
+		name:      mapOperatorName,
+		undefined: f == nil,
+		mapping:   f,
 	}
 }
 
+// tryMap returns map operator with the given mapping function, the evaluation of the stream is stopped by the first error returned by the function.
+func tryMap[T any](f func(T) (T, error)) operator[T] {
+	return operator[T]{
+		apply: func(x T) (T, bool) {
+			y, err := f(x)
+			if err != nil {
+				panic(errOperationFailed(tryMapOperatorName, err))
+			}
+			return y, true
+		},
+		name:      tryMapOperatorName,
+		undefined: f == nil,
+	}
+}
+
+// tryFilter returns filter operator with the given predicate, the evaluation of the stream is stopped by the first error returned by the predicate.
+func tryFilter[T any](f func(T) (bool, error)) operator[T] {
+	return operator[T]{
+		apply: func(x T) (T, bool) {
+			ok, err := f(x)
+			if err != nil {
+				panic(errOperationFailed(tryFilterOperatorName, err))
+			}
+			return x, ok
+		},
+		name:      tryFilterOperatorName,
+		undefined: f == nil,
+	}
+}
+
+// capConcurrency returns the given operator with at most n routines applying it at the same time, routines wait for their turn.
+func capConcurrency[T any](n int, f operator[T]) operator[T] {
+	apply := f.apply
+	tokens := make(chan struct{}, n)
+	f.apply = func(x T) (T, bool) {
+		tokens <- struct{}{}
+		defer func() { <-tokens }()
+		return apply(x)
+	}
+	return f
+}
+
 // limit returns limit operator with given limit.
 func limit[T any](multipleRoutineAccess bool, n int) operator[T] {
 	// If its a parallel stream we use atomic to avoid race conditions.
+	// Once the limit is reached the routines abandon the remaining elements, see applyOperations.
 	if multipleRoutineAccess {
 		var mux sync.Mutex
+		var reached int32
 		counter := 0
 		return operator[T]{
 			apply: func(x T) (T, bool) {
@@ -82,10 +183,16 @@ func limit[T any](multipleRoutineAccess bool, n int) operator[T] {
 					return ref, false
 				}
 				counter++
+				if counter == n {
+					atomic.StoreInt32(&reached, 1)
+				}
 				return x, true
 			},
-			name:     limitOperatorName,
-			stateful: true,
+			name:       limitOperatorName,
+			stateful:   true,
+			concurrent: true,
+			arg:        n,
+			exhausted:  func() bool { return n == 0 || atomic.LoadInt32(&reached) == 1 },
 		}
 	}
 	// Sequential stream no need for atomic.
@@ -99,12 +206,84 @@ func limit[T any](multipleRoutineAccess bool, n int) operator[T] {
 			counter++
 			return x, true
 		},
-		name:     limitOperatorName,
-		stateful: true,
+		name:        limitOperatorName,
+		stateful:    true,
+		arg:         n,
+		parallelize: func() operator[T] { return limit[T](true, n) },
+		exhausted:   func() bool { return counter >= n },
 	}
 
 }
 
+// limitUntil returns an operator which keeps elements until the first element that satisfies the given predicate, which is dropped along with all
+// elements after it.
+func limitUntil[T any](multipleRoutineAccess bool, f func(x T) bool) operator[T] {
+	if multipleRoutineAccess {
+		var mux sync.Mutex
+		var reached int32
+		stopped := false
+		return operator[T]{
+			apply: func(x T) (T, bool) {
+				mux.Lock()
+				defer mux.Unlock()
+				if stopped || f(x) {
+					stopped = true
+					atomic.StoreInt32(&reached, 1)
+					var ref T
+					return ref, false
+				}
+				return x, true
+			},
+			name:       limitUntilOperatorName,
+			stateful:   true,
+			concurrent: true,
+			undefined:  f == nil,
+			exhausted:  func() bool { return atomic.LoadInt32(&reached) == 1 },
+		}
+	}
+	stopped := false
+	return operator[T]{
+		apply: func(x T) (T, bool) {
+			if stopped || f(x) {
+				stopped = true
+				var ref T
+				return ref, false
+			}
+			return x, true
+		},
+		name:        limitUntilOperatorName,
+		stateful:    true,
+		undefined:   f == nil,
+		parallelize: func() operator[T] { return limitUntil(true, f) },
+		exhausted:   func() bool { return stopped },
+	}
+}
+
+// limitDuration returns an operator which keeps elements until the given duration has elapsed since the evaluation of the stream started, elements
+// after that are dropped and the operator is exhausted. The clock starts when the operator is first applied if the evaluation did not start it. The
+// deadline is shared by routines so it is safe for concurrent use.
+func limitDuration[T any](d time.Duration) operator[T] {
+	var once sync.Once
+	var deadline int64
+	start := func() { once.Do(func() { atomic.StoreInt64(&deadline, time.Now().Add(d).UnixNano()) }) }
+	expired := func() bool { return time.Now().UnixNano() >= atomic.LoadInt64(&deadline) }
+	return operator[T]{
+		apply: func(x T) (T, bool) {
+			start()
+			if expired() {
+				var ref T
+				return ref, false
+			}
+			return x, true
+		},
+		name:       limitDurationOperatorName,
+		stateful:   true,
+		concurrent: true,
+		start:      start,
+		exhausted:  func() bool { return atomic.LoadInt64(&deadline) != 0 && expired() },
+	}
+}
+
 // skip returns skip operator with given skip number.
 func skip[T any](multipleRoutineAccess bool, n int) operator[T] {
 	// If its a parallel stream we use atomic to avoid race conditions.
@@ -122,8 +301,10 @@ func skip[T any](multipleRoutineAccess bool, n int) operator[T] {
 				}
 				return x, true
 			},
-			name:     skipOperatorName,
-			stateful: true,
+			name:       skipOperatorName,
+			stateful:   true,
+			concurrent: true,
+			arg:        n,
 		}
 	}
 	// Sequential stream no need for atomic.
@@ -137,52 +318,89 @@ func skip[T any](multipleRoutineAccess bool, n int) operator[T] {
 			}
 			return x, true
 		},
-		name:     skipOperatorName,
-		stateful: true,
+		name:        skipOperatorName,
+		stateful:    true,
+		arg:         n,
+		parallelize: func() operator[T] { return skip[T](true, n) },
 	}
 
 }
 
-// distinct returns distinct operator with hiven hash functions for map keys.
-func distinct[T any](multipleRoutineAccess bool, alreadyDistinct bool, hash func(T) string) operator[T] {
+// distinct returns distinct operator with given hash function for keys, the keys are hashed using the given hasher if it is not nil.
+func distinct[T any](multipleRoutineAccess bool, alreadyDistinct bool, hash func(T) string, hasher Hasher[string]) operator[T] {
 	if alreadyDistinct { // if the stream is already distinct then just use an identity func.
 		return operator[T]{
 			apply: func(x T) (T, bool) {
 				return x, true
 			},
-			name:     distinctOperatorName,
-			stateful: true,
+			name:       distinctOperatorName,
+			stateful:   true,
+			concurrent: true,
+			hash:       hash,
 		}
 	} else if multipleRoutineAccess { // If its a parallel stream we use mutex lock to synchronize things.
-		elements := make(map[string]struct{})
+		elements := newKeyIndex(hasher)
 		var mutex sync.Mutex
 		return operator[T]{
 			apply: func(x T) (T, bool) {
+				key := hash(x)
 				mutex.Lock()
 				defer mutex.Unlock()
-				if _, ok := elements[hash(x)]; ok {
+				if _, ok := elements.get(key); ok {
 					var zero T
 					return zero, false
 				}
-				elements[hash(x)] = struct{}{}
+				elements.put(key, 0)
 				return x, true
 			},
-			name:     distinctOperatorName,
-			stateful: true,
+			name:       distinctOperatorName,
+			stateful:   true,
+			concurrent: true,
+			undefined:  hash == nil,
+			hash:       hash,
 		}
 	}
 	// If its a sequential stream no need for mutex.
-	elements := make(map[string]struct{})
+	elements := newKeyIndex(hasher)
 	return operator[T]{
 		apply: func(x T) (T, bool) {
-			if _, ok := elements[hash(x)]; ok {
+			key := hash(x)
+			if _, ok := elements.get(key); ok {
 				var zero T
 				return zero, false
 			}
-			elements[hash(x)] = struct{}{}
+			elements.put(key, 0)
 			return x, true
 		},
-		name:     distinctOperatorName,
-		stateful: true,
+		name:        distinctOperatorName,
+		stateful:    true,
+		undefined:   hash == nil,
+		parallelize: func() operator[T] { return distinct(true, false, hash, hasher) },
+		hash:        hash,
+	}
+}
+
+// parallelOperations returns the given operations with stateful operations created for a sequential stream replaced by equivalents that are safe
+// for access from multiple routines, so that a stream can be parallelized regardless of the operations added before.
+func parallelOperations[T any](operations []operator[T]) []operator[T] {
+	result := make([]operator[T], len(operations))
+	for i, operation := range operations {
+		if operation.stateful && !operation.concurrent && operation.parallelize != nil {
+			operation = operation.parallelize()
+		}
+		result[i] = operation
+	}
+	return result
+}
+
+// plan checks that the given operations can be executed without pulling any data, returns an error for the first operation that cannot be.
+func plan[T any](operations []operator[T], parallel bool) *streamError {
+	for i, operation := range operations {
+		if operation.undefined {
+			return errIllegalPlan("nil function", operation.name, i)
+		} else if parallel && operation.stateful && !operation.concurrent {
+			return errIllegalPlan("stateful operation created for a sequential stream cannot be executed in parallel", operation.name, i)
+		}
 	}
+	return nil
 }
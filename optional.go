@@ -0,0 +1,89 @@
+package streams
+
+import "sync"
+
+// Optional a value that may or may not be present, used for pipelines over partially populated data.
+type Optional[T any] struct {
+	value   T
+	present bool
+}
+
+// Some creates an optional holding the given value.
+func Some[T any](x T) Optional[T] {
+	return Optional[T]{value: x, present: true}
+}
+
+// None creates an empty optional.
+func None[T any]() Optional[T] {
+	return Optional[T]{}
+}
+
+// Get returns the value of the optional along with an indication of whether it is present.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.present
+}
+
+// IsPresent checks if the optional holds a value.
+func (o Optional[T]) IsPresent() bool {
+	return o.present
+}
+
+// OrElse returns the value of the optional if it is present otherwise the given value.
+func (o Optional[T]) OrElse(x T) T {
+	if o.present {
+		return o.value
+	}
+	return x
+}
+
+// MapOptional returns a stream consisting of the results of applying the given function to the elements of the stream, an element for which the
+// function reports no value results in an empty optional.
+func MapOptional[T any, U any](s Stream[T], f func(x T) (U, bool)) Stream[Optional[U]] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return mapElements(source, func(x T) Optional[U] {
+		val, ok := f(x)
+		if !ok {
+			return None[U]()
+		}
+		return Some(val)
+	})
+}
+
+// FlattenOptional returns a stream consisting of the values of the present optionals of the stream, empty optionals are dropped.
+func FlattenOptional[T any](s Stream[Optional[T]]) Stream[T] {
+	source := s.(*stream[Optional[T]])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return transform(source, func(data []Optional[T]) []T {
+		values := make([]T, 0, len(data))
+		for i := range data {
+			if data[i].present {
+				values = append(values, data[i].value)
+			}
+		}
+		return values
+	})
+}
+
+// FirstPresent returns the first present optional of the stream, an empty optional is returned if there is none. A sequential stream stops consuming
+// elements at the first present optional, for a parallel stream the returned optional is any of the present optionals.
+func FirstPresent[T any](s Stream[Optional[T]]) Optional[T] {
+	var mux sync.Mutex
+	first := None[T]()
+	s.ForEachWhile(func(optional Optional[T]) bool {
+		if !optional.present {
+			return true
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		if !first.present {
+			first = optional
+		}
+		return false
+	})
+	return first
+}
@@ -0,0 +1,58 @@
+package streams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptional(t *testing.T) {
+
+	type optionalTest struct {
+		data     []map[string]int
+		expected []int
+		first    Optional[int]
+	}
+
+	lookup := func(x map[string]int) (int, bool) {
+		val, ok := x["age"]
+		return val, ok
+	}
+
+	optionalTests := []optionalTest{
+		{data: []map[string]int{}, expected: []int{}, first: None[int]()},
+		{data: []map[string]int{{"id": 1}, {"id": 2}}, expected: []int{}, first: None[int]()},
+		{data: []map[string]int{{"id": 1}, {"age": 30}, {"age": 40}}, expected: []int{30, 40}, first: Some(30)},
+	}
+
+	for _, test := range optionalTests {
+		a := FlattenOptional(MapOptional(New(func() []map[string]int { return test.data }), lookup)).Collect()
+		b := FlattenOptional(MapOptional(New(func() []map[string]int { return test.data }).Parallelize(2), lookup)).Collect()
+		assert.ElementsMatch(t, test.expected, a)
+		assert.ElementsMatch(t, test.expected, b)
+
+		c := FirstPresent(MapOptional(New(func() []map[string]int { return test.data }), lookup))
+		d := FirstPresent(MapOptional(New(func() []map[string]int { return test.data }).Parallelize(2), lookup))
+		assert.Equal(t, test.first, c)
+		assert.Equal(t, test.first.IsPresent(), d.IsPresent())
+	}
+
+	// The first present optional is returned as soon as it is pulled from an open channel.
+	for _, parallel := range []bool{false, true} {
+		channel := make(chan map[string]int, 3)
+		channel <- map[string]int{"id": 1}
+		channel <- map[string]int{"age": 30}
+		s, _ := FromChannel(channel)
+		if parallel {
+			s = s.Parallelize(2)
+		}
+		assert.Equal(t, Some(30), FirstPresent(MapOptional(s, lookup)))
+	}
+
+	x, present := Some(1).Get()
+	assert.Equal(t, 1, x)
+	assert.True(t, present)
+	assert.Equal(t, 2, None[int]().OrElse(2))
+	assert.Equal(t, 1, Some(1).OrElse(2))
+
+}
@@ -1,6 +1,9 @@
 package streams
 
-import "fmt"
+import (
+	"fmt"
+	"sync/atomic"
+)
 
 // PartitionedStream a stream in which source elements are slices.
 type PartitionedStream[T any] interface {
@@ -18,10 +21,14 @@ type PartitionedStream[T any] interface {
 	Reduce(f func(x, y []T) []T) []T // Returns result of performing reduction on the elements of the stream, using ssociative accumulation function, and returns the reduced value.
 	// The zero value is returned if there are no elements.
 
-	Collect() [][]T                       // Returns a slice containing the elements from the stream.
-	Parallel() bool                       // Returns an indication of whether the stream is parallel.
-	Parallelize(int) PartitionedStream[T] // Returns a parallel stream with the given level of parallelism.
+	Collect() [][]T                                 // Returns a slice containing the elements from the stream.
+	Parallel() bool                                 // Returns an indication of whether the stream is parallel.
+	ExecutionInfo() ExecutionInfo                   // Returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+	Parallelize(int) PartitionedStream[T]           // Returns a parallel stream with the given level of parallelism.
+	ParallelizeWith(p Profile) PartitionedStream[T] // Returns a parallel stream using the given execution profile.
+	Sequential() PartitionedStream[T]               // Returns a sequential stream.
 
+	DryRun() error    // Checks that the stream can be evaluated without pulling any data from its source.
 	Terminated() bool // Checks if a terminal operation has been invoked on the stream.
 	Closed() bool     // Checks if a stream has been closed. A stream is closed either when a new stream is created from it using intermediate
 	// operations, terminated streams are also closed.
@@ -30,46 +37,79 @@ type PartitionedStream[T any] interface {
 
 // stream underlying concrete type, keeps track of operations.
 type partitionedStream[T any] struct {
-	supplier    func() [][]T
-	operations  []operator[[]T]
-	parallel    bool
-	maxRoutines int
-	distinct    bool
-	terminated  bool
-	closed      bool
+	supplier   func() [][]T
+	operations []operator[[]T]
+	parallel   bool
+	executor   executor
+	distinct   bool
+	terminated int32
+	closed     int32
+}
+
+// NewPartitioned creates a new partitioned stream whose elements are the batches received from the channel returned by the supplier. The channel
+// is drained until it is closed, so the producer must close it once all batches have been sent. A nil channel has no batches, NewPartitioned panics
+// if the supplier is nil.
+func NewPartitioned[T any](supplier func() <-chan []T) PartitionedStream[T] {
+	if supplier == nil {
+		panic(errIllegalArgument("NewPartitioned", "nil"))
+	}
+	return &partitionedStream[T]{
+		supplier: func() [][]T {
+			partitions := make([][]T, 0)
+			channel := supplier()
+			if channel == nil {
+				return partitions
+			}
+			for partition := range channel {
+				partitions = append(partitions, partition)
+			}
+			return partitions
+		},
+		operations: make([]operator[[]T], 0),
+	}
 }
 
 // newPartitionedStream creates a new stream which adds the given operation.
 func newPartitionedStream[T any](s *partitionedStream[T], operator operator[[]T]) *partitionedStream[T] {
-	defer s.close()
+	if err := s.close(); err != nil {
+		panic(err)
+	}
 	return &partitionedStream[T]{
-		supplier:    s.supplier,
-		operations:  append(s.operations, operator),
-		parallel:    s.parallel,
-		distinct:    s.distinct,
-		maxRoutines: s.maxRoutines,
+		supplier:   s.supplier,
+		operations: appendOperation(s.operations, operator),
+		parallel:   s.parallel,
+		distinct:   s.distinct,
+		executor:   s.executor,
 	}
 }
 
 // Closed returns an indication of whether the stream has been closed or not.
 func (s *partitionedStream[T]) Closed() bool {
-	return s.closed
+	return atomic.LoadInt32(&s.closed) == 1
 }
 
-// close closes the stream.
-func (s *partitionedStream[T]) close() {
-	s.closed = true
+// close closes the stream, an error is returned if the stream has already been closed. Closing is atomic so that only one of many routines
+// deriving streams from the same stream concurrently succeeds.
+func (s *partitionedStream[T]) close() *streamError {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		_, err := s.valid()
+		return err
+	}
+	return nil
 }
 
 // Terminated returns an indication of whether the stream has been closed by invoking a terminal operation.
 func (s *partitionedStream[T]) Terminated() bool {
-	return s.terminated
+	return atomic.LoadInt32(&s.terminated) == 1
 }
 
-// terminate terminate the stream.
-func (s *partitionedStream[T]) terminate() {
-	s.terminated = true
-	s.closed = true
+// terminate terminates the stream, an error is returned if the stream has already been closed.
+func (s *partitionedStream[T]) terminate() *streamError {
+	if err := s.close(); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&s.terminated, 1)
+	return nil
 }
 
 // valid checks if a stream is valid before performing any type of operation.
@@ -89,28 +129,64 @@ func (s partitionedStream[T]) Parallel() bool {
 	return s.parallel
 }
 
-// Parallelize returns a parallel stream with the given level of parallelism
+// ExecutionInfo returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+func (s *partitionedStream[T]) ExecutionInfo() ExecutionInfo {
+	return executionInfo(s.parallel, false, s.executor)
+}
+
+// Parallelize returns a parallel stream with the given level of parallelism. Parallelizing a stream that is already parallel replaces its level
+// of parallelism and profile, the last call wins, use Sequential to switch back to sequential evaluation.
 func (s *partitionedStream[T]) Parallelize(n int) PartitionedStream[T] {
-	if n <= 1 {
-		panic(errIllegalConfig("Parallelism", fmt.Sprint(n)))
+	if err := checkParallelism(n); err != nil {
+		panic(err)
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &partitionedStream[T]{
+		supplier:   s.supplier,
+		operations: parallelOperations(s.operations),
+		distinct:   s.distinct,
+		parallel:   true,
+		executor:   s.executor.configure(executor{maxRoutines: n}),
+	}
+}
+
+// Sequential returns a sequential stream consisting of the elements of this stream.
+func (s *partitionedStream[T]) Sequential() PartitionedStream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
 	}
 	return &partitionedStream[T]{
-		supplier:    s.supplier,
-		operations:  s.operations,
-		distinct:    s.distinct,
-		parallel:    true,
-		maxRoutines: n,
+		supplier:   s.supplier,
+		operations: s.operations,
+		distinct:   s.distinct,
+		executor:   s.executor,
+	}
+}
+
+// ParallelizeWith returns a parallel stream whose number of routines and dispatching of elements to them is given by the profile.
+func (s *partitionedStream[T]) ParallelizeWith(p Profile) PartitionedStream[T] {
+	if p.executor.maxRoutines < 1 {
+		panic(errIllegalConfig("Profile", fmt.Sprint(p)))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	return &partitionedStream[T]{
+		supplier:   s.supplier,
+		operations: parallelOperations(s.operations),
+		distinct:   s.distinct,
+		parallel:   true,
+		executor:   s.executor.configure(p.executor),
 	}
 }
 
 // Collect returns a slice containing the elements from the stream.
 func (s *partitionedStream[T]) Collect() [][]T {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	if s.parallel {
-		return parallelCollect(s.supplier(), s.operations, s.maxRoutines)
+		return parallelCollect(s.supplier(), s.operations, s.executor)
 	}
 	return collect(s.supplier(), s.operations)
 }
@@ -126,24 +202,25 @@ func (s *partitionedStream[T]) Map(f func(T) T) PartitionedStream[T] {
 
 // FlatMap converts the partitioned stream of elements [[]T,[]T,...] to a stream of elements []T.
 func (s *partitionedStream[T]) FlatMap() Stream[T] {
-	defer s.close()
-	if ok, err := s.valid(); !ok {
+	if err := s.close(); err != nil {
 		panic(err)
 	} else if s.parallel {
 		return &stream[T]{
-			supplier:    parallelFlatMapSupplier(s.supplier, s.operations, s.maxRoutines),
-			operations:  make([]operator[T], 0),
-			parallel:    s.parallel,
-			distinct:    s.distinct,
-			maxRoutines: s.maxRoutines,
+			supplier:   parallelFlatMapSupplier(s.supplier, s.operations, s.executor),
+			operations: make([]operator[T], 0),
+			parallel:   s.parallel,
+			distinct:   s.distinct,
+			executor:   s.executor,
+			origin:     partitionedSourceName,
 		}
 	}
 	return &stream[T]{
-		supplier:    flatMapSupplier(s.supplier, s.operations),
-		operations:  make([]operator[T], 0),
-		distinct:    s.distinct,
-		parallel:    s.parallel,
-		maxRoutines: s.maxRoutines,
+		supplier:   flatMapSupplier(s.supplier, s.operations),
+		operations: make([]operator[T], 0),
+		distinct:   s.distinct,
+		parallel:   s.parallel,
+		executor:   s.executor,
+		origin:     partitionedSourceName,
 	}
 }
 
@@ -160,7 +237,7 @@ func (s *partitionedStream[T]) Distinct(hash func(x T) string) PartitionedStream
 	if ok, err := s.valid(); !ok {
 		panic(err)
 	}
-	newPartitionedStream := newPartitionedStream(s, extendOperator(distinct(s.parallel, s.distinct, hash)))
+	newPartitionedStream := newPartitionedStream(s, extendOperator(distinct(s.parallel, s.distinct, hash, nil)))
 	newPartitionedStream.distinct = true
 	return newPartitionedStream
 }
@@ -185,12 +262,11 @@ func (s *partitionedStream[T]) Skip(n int) PartitionedStream[T] {
 
 // Count returns the count of elements in this stream.
 func (s *partitionedStream[T]) Count() int {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	if s.parallel {
-		return parallelCount(s.supplier(), s.operations, s.maxRoutines)
+		return parallelCount(s.supplier(), s.operations, s.executor)
 	}
 	return count(s.supplier(), s.operations)
 
@@ -198,14 +274,13 @@ func (s *partitionedStream[T]) Count() int {
 
 // ForEach performs an action for each element of this stream.
 func (s *partitionedStream[T]) ForEach(f func([]T)) {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	data := s.supplier()
 	operations := s.operations
 	if s.parallel {
-		parallelForEach(data, operations, f, s.maxRoutines)
+		parallelForEach(data, operations, f, s.executor)
 		return
 	}
 	forEach(data, operations, f)
@@ -223,15 +298,27 @@ func (s *partitionedStream[T]) Peek(f func([]T)) PartitionedStream[T] {
 // Reduce performs a reduction on the elements of the stream, using ssociative accumulation function, and returns the reduced value.
 // The zero value is returned if there are no elements.
 func (s *partitionedStream[T]) Reduce(f func(x, y []T) []T) []T {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
 	if s.parallel {
-		val, _ := parallelReduce(s.supplier(), s.operations, f, s.maxRoutines)
+		val, _ := parallelReduce(s.supplier(), s.operations, f, s.executor)
 		return val
 	}
 	val, _ := reduce(s.supplier(), s.operations, f)
 	return val
 
 }
+
+// DryRun checks that the stream can be evaluated without pulling any data from its source, returns an error describing the first problem found
+// i.e an operation created with a nil function.
+func (s *partitionedStream[T]) DryRun() error {
+	if ok, err := s.valid(); !ok {
+		return err
+	} else if s.supplier == nil {
+		return errIllegalPlan("nil supplier", "SOURCE", 0)
+	} else if err := plan(s.operations, s.parallel); err != nil {
+		return err
+	}
+	return nil
+}
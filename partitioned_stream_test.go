@@ -393,3 +393,40 @@ func TestPartitionedPeek(t *testing.T) {
 	}
 
 }
+
+func TestNewPartitioned(t *testing.T) {
+
+	type newPartitionedTest struct {
+		batches  [][]int
+		expected []int
+	}
+
+	var newPartitionedTests = []newPartitionedTest{
+		{batches: [][]int{}, expected: []int{}},
+		{batches: [][]int{{1, 2}, {3}, {}, {4, 5, 6}}, expected: []int{2, 4, 6}},
+	}
+
+	for _, test := range newPartitionedTests {
+		supplier := func() <-chan []int {
+			channel := make(chan []int)
+			go func(batches [][]int) {
+				defer close(channel)
+				for _, batch := range batches {
+					channel <- batch
+				}
+			}(test.batches)
+			return channel
+		}
+		even := func(x int) bool { return x%2 == 0 }
+		s1, s2 := NewPartitioned(supplier), NewPartitioned(supplier).Parallelize(2)
+		assert.Equal(t, len(test.batches), NewPartitioned(supplier).Count())
+		assert.ElementsMatch(t, test.expected, s1.Filter(even).FlatMap().Collect())
+		assert.ElementsMatch(t, test.expected, s2.Filter(even).FlatMap().Collect())
+		assert.True(t, s1.Closed())
+		assert.True(t, s2.Closed())
+	}
+
+	assert.Equal(t, 0, NewPartitioned(func() <-chan []int { return nil }).Count())
+	assert.Panics(t, func() { NewPartitioned[int](nil) })
+
+}
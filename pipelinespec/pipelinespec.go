@@ -0,0 +1,169 @@
+// Package pipelinespec builds stream pipelines from declarative specs written in JSON or YAML, so that the filters, limits and parallelism of a
+// pipeline can be changed through configuration without recompiling. Operators of a spec refer to functions by name, which are registered in a
+// Functions table.
+//
+// A spec in YAML looks like
+//
+//	parallelism: 4
+//	operators:
+//	  - op: filter
+//	    func: adult
+//	  - op: map
+//	    func: normalize
+//	  - op: limit
+//	    n: 100
+package pipelinespec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/phantom820/streams"
+	"gopkg.in/yaml.v3"
+)
+
+// Operators supported in a spec.
+const (
+	FilterOp   = "filter"   // Keeps the elements satisfying the named predicate.
+	MapOp      = "map"      // Transforms elements using the named function.
+	PeekOp     = "peek"     // Performs the named action on elements as they are consumed.
+	DistinctOp = "distinct" // Keeps distinct elements according to the named hash function.
+	LimitOp    = "limit"    // Keeps the first n elements.
+	SkipOp     = "skip"     // Discards the first n elements.
+)
+
+// Spec a declarative description of a pipeline.
+type Spec struct {
+	Parallelism int        `json:"parallelism" yaml:"parallelism"` // Level of parallelism, the pipeline is sequential if it is less than 2.
+	Operators   []Operator `json:"operators" yaml:"operators"`     // Operators applied in order.
+}
+
+// Operator an operator of a spec along with its parameters.
+type Operator struct {
+	Op   string `json:"op" yaml:"op"`                         // Name of the operator.
+	Func string `json:"func,omitempty" yaml:"func,omitempty"` // Name of the function for filter, map, peek and distinct.
+	N    int    `json:"n,omitempty" yaml:"n,omitempty"`       // Count for limit and skip.
+}
+
+// Functions named functions that operators of specs can refer to.
+type Functions[T any] struct {
+	filters map[string]func(x T) bool
+	maps    map[string]func(x T) T
+	peeks   map[string]func(x T)
+	hashes  map[string]func(x T) string
+}
+
+// NewFunctions creates an empty table of functions.
+func NewFunctions[T any]() *Functions[T] {
+	return &Functions[T]{
+		filters: make(map[string]func(x T) bool),
+		maps:    make(map[string]func(x T) T),
+		peeks:   make(map[string]func(x T)),
+		hashes:  make(map[string]func(x T) string),
+	}
+}
+
+// Filter registers a predicate for use by filter operators.
+func (f *Functions[T]) Filter(name string, predicate func(x T) bool) *Functions[T] {
+	f.filters[name] = predicate
+	return f
+}
+
+// Map registers a transformation for use by map operators.
+func (f *Functions[T]) Map(name string, transform func(x T) T) *Functions[T] {
+	f.maps[name] = transform
+	return f
+}
+
+// Peek registers an action for use by peek operators.
+func (f *Functions[T]) Peek(name string, action func(x T)) *Functions[T] {
+	f.peeks[name] = action
+	return f
+}
+
+// Hash registers a hash function for use by distinct operators.
+func (f *Functions[T]) Hash(name string, hash func(x T) string) *Functions[T] {
+	f.hashes[name] = hash
+	return f
+}
+
+// ParseJSON parses a spec written in JSON.
+func ParseJSON(data []byte) (Spec, error) {
+	var spec Spec
+	err := json.Unmarshal(data, &spec)
+	return spec, err
+}
+
+// ParseYAML parses a spec written in YAML.
+func ParseYAML(data []byte) (Spec, error) {
+	var spec Spec
+	err := yaml.Unmarshal(data, &spec)
+	return spec, err
+}
+
+// Build returns the pipeline described by the spec using the given functions, an error is returned if the spec refers to an unknown operator or
+// function or has an illegal parameter. The returned pipeline can be registered with streams.Register to be run by name.
+func Build[T any](spec Spec, functions *Functions[T]) (streams.Pipeline[T], error) {
+	steps := make([]func(s streams.Stream[T]) streams.Stream[T], 0, len(spec.Operators)+1)
+	if spec.Parallelism > 1 {
+		n := spec.Parallelism
+		steps = append(steps, func(s streams.Stream[T]) streams.Stream[T] { return s.Parallelize(n) })
+	} else if spec.Parallelism < 0 {
+		return nil, fmt.Errorf("pipelinespec: illegal parallelism %d", spec.Parallelism)
+	}
+
+	for i, operator := range spec.Operators {
+		step, err := build(operator, functions)
+		if err != nil {
+			return nil, fmt.Errorf("pipelinespec: operator %d: %w", i, err)
+		}
+		steps = append(steps, step)
+	}
+
+	return func(s streams.Stream[T]) streams.Stream[T] {
+		for _, step := range steps {
+			s = step(s)
+		}
+		return s
+	}, nil
+}
+
+// build returns the step applying the given operator.
+func build[T any](operator Operator, functions *Functions[T]) (func(s streams.Stream[T]) streams.Stream[T], error) {
+	switch operator.Op {
+	case FilterOp:
+		f, ok := functions.filters[operator.Func]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q", operator.Func)
+		}
+		return func(s streams.Stream[T]) streams.Stream[T] { return s.Filter(f) }, nil
+	case MapOp:
+		f, ok := functions.maps[operator.Func]
+		if !ok {
+			return nil, fmt.Errorf("unknown map %q", operator.Func)
+		}
+		return func(s streams.Stream[T]) streams.Stream[T] { return s.Map(f) }, nil
+	case PeekOp:
+		f, ok := functions.peeks[operator.Func]
+		if !ok {
+			return nil, fmt.Errorf("unknown peek %q", operator.Func)
+		}
+		return func(s streams.Stream[T]) streams.Stream[T] { return s.Peek(f) }, nil
+	case DistinctOp:
+		f, ok := functions.hashes[operator.Func]
+		if !ok {
+			return nil, fmt.Errorf("unknown hash %q", operator.Func)
+		}
+		return func(s streams.Stream[T]) streams.Stream[T] { return s.Distinct(f) }, nil
+	case LimitOp, SkipOp:
+		if operator.N < 0 {
+			return nil, fmt.Errorf("illegal %s %d", operator.Op, operator.N)
+		}
+		n := operator.N
+		if operator.Op == LimitOp {
+			return func(s streams.Stream[T]) streams.Stream[T] { return s.Limit(n) }, nil
+		}
+		return func(s streams.Stream[T]) streams.Stream[T] { return s.Skip(n) }, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", operator.Op)
+}
@@ -0,0 +1,76 @@
+package pipelinespec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phantom820/streams"
+	"github.com/stretchr/testify/assert"
+)
+
+func functions() *Functions[string] {
+	return NewFunctions[string]().
+		Filter("nonEmpty", func(x string) bool { return x != "" }).
+		Map("upper", strings.ToUpper).
+		Peek("noop", func(x string) {}).
+		Hash("identity", func(x string) string { return x })
+}
+
+func TestBuild(t *testing.T) {
+
+	type buildTest struct {
+		spec     string
+		yaml     bool
+		expected []string
+	}
+
+	data := []string{"a", "", "b", "a", "c", "d"}
+
+	buildTests := []buildTest{
+		{spec: `{"operators": []}`, expected: data},
+		{spec: `{"operators": [{"op": "filter", "func": "nonEmpty"}, {"op": "map", "func": "upper"}]}`, expected: []string{"A", "B", "A", "C", "D"}},
+		{spec: `{"parallelism": 2, "operators": [{"op": "filter", "func": "nonEmpty"}, {"op": "distinct", "func": "identity"}]}`, expected: []string{"a", "b", "c", "d"}},
+		{spec: "operators:\n  - op: skip\n    n: 2\n  - op: limit\n    n: 2\n  - op: peek\n    func: noop\n", yaml: true, expected: []string{"b", "a"}},
+	}
+
+	for _, test := range buildTests {
+		var spec Spec
+		var err error
+		if test.yaml {
+			spec, err = ParseYAML([]byte(test.spec))
+		} else {
+			spec, err = ParseJSON([]byte(test.spec))
+		}
+		assert.Nil(t, err)
+
+		pipeline, err := Build(spec, functions())
+		assert.Nil(t, err)
+		s := pipeline(streams.New(func() []string { return data }))
+		assert.Equal(t, spec.Parallelism > 1, s.Parallel())
+		assert.ElementsMatch(t, test.expected, s.Collect())
+	}
+
+}
+
+func TestBuildErr(t *testing.T) {
+
+	specs := []Spec{
+		{Parallelism: -1},
+		{Operators: []Operator{{Op: "sort"}}},
+		{Operators: []Operator{{Op: FilterOp, Func: "missing"}}},
+		{Operators: []Operator{{Op: MapOp, Func: "nonEmpty"}}},
+		{Operators: []Operator{{Op: PeekOp}}},
+		{Operators: []Operator{{Op: DistinctOp, Func: "upper"}}},
+		{Operators: []Operator{{Op: LimitOp, N: -1}}},
+	}
+
+	for _, spec := range specs {
+		pipeline, err := Build(spec, functions())
+		assert.Nil(t, pipeline)
+		assert.NotNil(t, err)
+	}
+
+	_, err := ParseYAML([]byte("operators: ["))
+	assert.NotNil(t, err)
+
+}
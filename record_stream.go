@@ -0,0 +1,250 @@
+package streams
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const (
+	UnpivotVariableField = "variable" // Field of an unpivoted record that holds the name of the field the value came from.
+	UnpivotValueField    = "value"    // Field of an unpivoted record that holds the value.
+)
+
+// Record an element with named fields of arbitrary values, i.e a decoded JSON object or a CSV row.
+type Record = map[string]any
+
+// RecordStream a stream whose elements are records, it adds field oriented operations to those of a stream so that ingestion pipelines can be
+// written without defining structs.
+type RecordStream interface {
+	Filter(f func(x Record) bool) RecordStream        // Returns a stream consisting of the records of this stream that satisfy the given predicate.
+	Map(f func(x Record) Record) RecordStream         // Returns a stream consisting of the results of applying the given transformation to the records of the stream.
+	Limit(n int) RecordStream                         // Returns a stream consisting of the records of this stream, truncated to be no longer than given length.
+	Skip(n int) RecordStream                          // Returns a stream consisting of the remaining records of this stream after discarding the first n records of the stream.
+	Distinct(hash func(x Record) string) RecordStream // Returns a stream consisting of the distinct records (according to the given hash of records) of this stream.
+	Peek(f func(x Record)) RecordStream               // Returns a stream consisting of the records of this stream.
+	// additionally the provided action on each record as records are consumed.
+	Select(fields ...string) RecordStream                    // Returns a stream consisting of the records of this stream restricted to the given fields.
+	Rename(from, to string) RecordStream                     // Returns a stream consisting of the records of this stream with the given field renamed.
+	WhereField(name string, f func(x any) bool) RecordStream // Returns a stream consisting of the records of this stream which have the given field and its value satisfies the predicate.
+	FilterExpr(expression string) RecordStream               // Returns a stream consisting of the records of this stream that satisfy the given boolean expression.
+	MapExpr(field, expression string) RecordStream           // Returns a stream consisting of the records of this stream with the field set to the value of the expression.
+	Unpivot(fields ...string) RecordStream                   // Returns a stream in which each record is split into one record per given field, holding the field name and its value.
+
+	ForEach(f func(x Record))                 // Performs an action specified by the function f for each record of the stream.
+	Count() int                               // Returns a count of records in the stream.
+	Reduce(f func(x, y Record) Record) Record // Returns result of performing reduction on the records of the stream, using associative accumulation function.
+	Collect() []Record                        // Returns a slice containing the records from the stream.
+	Pivot(rowKey, colKey, value string, agg func(values []any) any) map[string]map[string]any
+	// Returns a table of the aggregated values of the value field for each pair of row and column key values.
+
+	Parallel() bool               // Returns an indication of whether the stream is parallel.
+	ExecutionInfo() ExecutionInfo // Returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+	Parallelize(int) RecordStream // Returns a parallel stream with the given level of parallelism.
+	Stream() Stream[Record]       // Returns the records as a plain stream.
+
+	Terminated() bool // Checks if a terminal operation has been invoked on the stream.
+	Closed() bool     // Checks if a stream has been closed. A stream is closed either when a new stream is created from it using intermediate
+	// operations, terminated streams are also closed.
+	Summary() Summary // Returns statistics of the evaluation of the stream by its terminal operation.
+}
+
+// recordStream concrete type for a record stream, terminal operations are those of the underlying stream.
+type recordStream struct {
+	*stream[Record]
+}
+
+// NewRecordStream creates a new record stream with the given supplier for records.
+func NewRecordStream(supplier func() []Record) RecordStream {
+	return &recordStream{stream: New(supplier).(*stream[Record])}
+}
+
+// Records returns a record stream view of the given stream, both share the same state.
+func Records(s Stream[Record]) RecordStream {
+	return &recordStream{stream: s.(*stream[Record])}
+}
+
+// Stream returns a plain stream view of this stream, both share the same state.
+func (s *recordStream) Stream() Stream[Record] {
+	return s.stream
+}
+
+// Parallelize returns a parallel stream with the given level of parallelism
+func (s *recordStream) Parallelize(n int) RecordStream {
+	return &recordStream{stream: s.stream.Parallelize(n).(*stream[Record])}
+}
+
+// Filter returns a stream consisting of the records of this stream that match the given predicate.
+func (s *recordStream) Filter(f func(Record) bool) RecordStream {
+	return &recordStream{stream: s.stream.Filter(f).(*stream[Record])}
+}
+
+// Map returns a stream consisting of the results of applying the given mapping function to the records of this stream.
+func (s *recordStream) Map(f func(Record) Record) RecordStream {
+	return &recordStream{stream: s.stream.Map(f).(*stream[Record])}
+}
+
+// Limit returns a stream consisting of the records of this stream, truncated to be no longer than given length.
+func (s *recordStream) Limit(n int) RecordStream {
+	return &recordStream{stream: s.stream.Limit(n).(*stream[Record])}
+}
+
+// Skip returns a stream consisting of the remaining records of this stream after discarding the first n records of the stream.
+func (s *recordStream) Skip(n int) RecordStream {
+	return &recordStream{stream: s.stream.Skip(n).(*stream[Record])}
+}
+
+// Distinct returns a stream consisting of the distinct records (according to the given hash of records) of this stream.
+func (s *recordStream) Distinct(hash func(Record) string) RecordStream {
+	return &recordStream{stream: s.stream.Distinct(hash).(*stream[Record])}
+}
+
+// Peek returns a stream consisting of the records of this stream,
+// additionally the provided action on each record as records are consumed.
+func (s *recordStream) Peek(f func(Record)) RecordStream {
+	return &recordStream{stream: s.stream.Peek(f).(*stream[Record])}
+}
+
+// Select returns a stream consisting of the records of this stream restricted to the given fields, fields that a record does not have are omitted.
+func (s *recordStream) Select(fields ...string) RecordStream {
+	return s.Map(func(x Record) Record {
+		result := make(Record, len(fields))
+		for _, field := range fields {
+			if val, ok := x[field]; ok {
+				result[field] = val
+			}
+		}
+		return result
+	})
+}
+
+// Rename returns a stream consisting of the records of this stream with the field from renamed to the field to. Records are copied so that the
+// source is not modified.
+func (s *recordStream) Rename(from, to string) RecordStream {
+	return s.Map(func(x Record) Record {
+		val, ok := x[from]
+		if !ok {
+			return x
+		}
+		result := make(Record, len(x))
+		for field := range x {
+			if field != from {
+				result[field] = x[field]
+			}
+		}
+		result[to] = val
+		return result
+	})
+}
+
+// FilterExpr returns a stream consisting of the records of this stream that satisfy the given boolean expression over their fields, i.e
+// "age > 18 && country == 'ZA'". FilterExpr panics if the expression is malformed, or once evaluated if it does not evaluate to a bool for a record.
+func (s *recordStream) FilterExpr(expression string) RecordStream {
+	e := compileExpr(expression)
+	return s.Filter(func(x Record) bool {
+		result, ok := evalExpr(expression, e, x).(bool)
+		if !ok {
+			panic(errIllegalExpression(expression, "not a boolean expression"))
+		}
+		return result
+	})
+}
+
+// MapExpr returns a stream consisting of the records of this stream with the given field set to the value of the given expression over their fields,
+// i.e "price * quantity". Numeric values are evaluated as float64. Records are copied so that the source is not modified.
+func (s *recordStream) MapExpr(field, expression string) RecordStream {
+	e := compileExpr(expression)
+	return s.Map(func(x Record) Record {
+		result := make(Record, len(x)+1)
+		for name := range x {
+			result[name] = x[name]
+		}
+		result[field] = evalExpr(expression, e, x)
+		return result
+	})
+}
+
+// WhereField returns a stream consisting of the records of this stream that have the given field and whose value for it satisfies the given predicate.
+func (s *recordStream) WhereField(name string, f func(x any) bool) RecordStream {
+	return s.Filter(func(x Record) bool {
+		val, ok := x[name]
+		return ok && f(val)
+	})
+}
+
+// TypedColumn returns a stream consisting of the values of the given field of the records in the stream. Records without the field are skipped
+// and a record whose value for the field is not of type T results in a panic once the stream is evaluated.
+func TypedColumn[T any](s RecordStream, name string) Stream[T] {
+	source := s.(*recordStream).stream
+	return transform(source, func(data []Record) []T {
+		column := make([]T, 0, len(data))
+		for _, x := range data {
+			val, ok := x[name]
+			if !ok {
+				continue
+			}
+			typed, ok := val.(T)
+			if !ok {
+				panic(errIllegalStreamMapping(reflect.TypeOf((*T)(nil)).Elem().String()))
+			}
+			column = append(column, typed)
+		}
+		return column
+	})
+}
+
+// Unpivot returns a stream in which each record of this stream is split into one record per given field that it has. Each resulting record has the
+// fields of the original record that are not being unpivoted, along with the name of the unpivoted field (UnpivotVariableField) and its value (UnpivotValueField).
+func (s *recordStream) Unpivot(fields ...string) RecordStream {
+	unpivoted := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		unpivoted[field] = struct{}{}
+	}
+	return &recordStream{stream: transform(s.stream, func(data []Record) []Record {
+		results := make([]Record, 0, len(data)*len(fields))
+		for _, x := range data {
+			for _, field := range fields {
+				val, ok := x[field]
+				if !ok {
+					continue
+				}
+				result := make(Record, len(x)+2)
+				for k := range x {
+					if _, ok := unpivoted[k]; !ok {
+						result[k] = x[k]
+					}
+				}
+				result[UnpivotVariableField] = field
+				result[UnpivotValueField] = val
+				results = append(results, result)
+			}
+		}
+		return results
+	})}
+}
+
+// Pivot returns a table whose rows are given by the values of the rowKey field and columns by the values of the colKey field, each cell is the result
+// of aggregating the values of the value field of records falling in the cell. Records which do not have all three fields are skipped.
+func (s *recordStream) Pivot(rowKey, colKey, value string, agg func(values []any) any) map[string]map[string]any {
+	cells := make(map[string]map[string][]any)
+	for _, x := range s.Collect() {
+		row, ok1 := x[rowKey]
+		col, ok2 := x[colKey]
+		val, ok3 := x[value]
+		if !ok1 || !ok2 || !ok3 {
+			continue
+		}
+		r, c := fmt.Sprint(row), fmt.Sprint(col)
+		if _, ok := cells[r]; !ok {
+			cells[r] = make(map[string][]any)
+		}
+		cells[r][c] = append(cells[r][c], val)
+	}
+	table := make(map[string]map[string]any, len(cells))
+	for r := range cells {
+		table[r] = make(map[string]any, len(cells[r]))
+		for c := range cells[r] {
+			table[r][c] = agg(cells[r][c])
+		}
+	}
+	return table
+}
@@ -0,0 +1,168 @@
+package streams
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordStream(t *testing.T) {
+
+	people := func() []Record {
+		return []Record{
+			{"name": "thabo", "age": 31, "country": "ZA"},
+			{"name": "anna", "age": 17, "country": "ZA"},
+			{"name": "john", "age": 45, "country": "UK"},
+			{"name": "lerato", "country": "ZA"},
+		}
+	}
+
+	adult := func(x any) bool { return x.(int) >= 18 }
+
+	s1, s2 := NewRecordStream(people), NewRecordStream(people).Parallelize(2)
+	expected := []Record{{"first_name": "thabo", "age": 31}, {"first_name": "john", "age": 45}}
+	assert.ElementsMatch(t, expected, s1.WhereField("age", adult).Select("name", "age").Rename("name", "first_name").Collect())
+	assert.ElementsMatch(t, expected, s2.WhereField("age", adult).Select("name", "age").Rename("name", "first_name").Collect())
+	assert.True(t, s1.Closed())
+	assert.True(t, s2.Closed())
+
+	// The source records should not be modified.
+	source := people()
+	NewRecordStream(func() []Record { return source }).Rename("name", "first_name").Collect()
+	assert.Equal(t, "thabo", source[0]["name"])
+
+	names := TypedColumn[string](NewRecordStream(people).WhereField("country", func(x any) bool { return x == "ZA" }), "name")
+	assert.ElementsMatch(t, []string{"thabo", "anna", "lerato"}, names.Collect())
+	ages := TypedColumn[int](NewRecordStream(people).Parallelize(2), "age")
+	assert.ElementsMatch(t, []int{31, 17, 45}, ages.Collect())
+
+	// The type of an interface column is named in the error.
+	assert.PanicsWithError(t, errIllegalStreamMapping("fmt.Stringer").Error(), func() {
+		TypedColumn[fmt.Stringer](NewRecordStream(people), "age").Collect()
+	})
+
+	defer func() {
+		r := recover()
+		assert.NotNil(t, r)
+		assert.Equal(t, IllegalStreamMapping, r.(*streamError).Code())
+	}()
+	TypedColumn[string](NewRecordStream(people), "age").Collect()
+
+}
+
+func TestRecordStreamPivot(t *testing.T) {
+
+	sales := func() []Record {
+		return []Record{
+			{"region": "north", "quarter": "Q1", "amount": 10},
+			{"region": "north", "quarter": "Q1", "amount": 5},
+			{"region": "north", "quarter": "Q2", "amount": 7},
+			{"region": "south", "quarter": "Q1", "amount": 3},
+			{"region": "south", "amount": 100},
+		}
+	}
+
+	sum := func(values []any) any {
+		total := 0
+		for _, val := range values {
+			total += val.(int)
+		}
+		return total
+	}
+
+	expected := map[string]map[string]any{
+		"north": {"Q1": 15, "Q2": 7},
+		"south": {"Q1": 3},
+	}
+	assert.Equal(t, expected, NewRecordStream(sales).Pivot("region", "quarter", "amount", sum))
+	assert.Equal(t, expected, NewRecordStream(sales).Parallelize(2).Pivot("region", "quarter", "amount", sum))
+
+}
+
+func TestRecordStreamUnpivot(t *testing.T) {
+
+	wide := func() []Record {
+		return []Record{
+			{"region": "north", "Q1": 15, "Q2": 7},
+			{"region": "south", "Q1": 3},
+		}
+	}
+
+	expected := []Record{
+		{"region": "north", UnpivotVariableField: "Q1", UnpivotValueField: 15},
+		{"region": "north", UnpivotVariableField: "Q2", UnpivotValueField: 7},
+		{"region": "south", UnpivotVariableField: "Q1", UnpivotValueField: 3},
+	}
+	assert.ElementsMatch(t, expected, NewRecordStream(wide).Unpivot("Q1", "Q2").Collect())
+	assert.ElementsMatch(t, expected, NewRecordStream(wide).Parallelize(2).Unpivot("Q1", "Q2").Collect())
+
+}
+
+func TestRecordStreamFilterExpr(t *testing.T) {
+
+	people := []Record{
+		{"name": "a", "age": 20, "country": "ZA"},
+		{"name": "b", "age": 17, "country": "ZA"},
+		{"name": "c", "age": 30.5, "country": "US"},
+		{"name": "d", "country": "ZA"},
+	}
+
+	type filterExprTest struct {
+		expr     string
+		expected []string
+	}
+
+	filterExprTests := []filterExprTest{
+		{expr: "age > 18 && country == 'ZA'", expected: []string{"a"}},
+		{expr: "age >= 17 || name == \"d\"", expected: []string{"a", "b", "c", "d"}},
+		{expr: "!(country == 'ZA') ", expected: []string{"c"}},
+		{expr: "age == null", expected: []string{"d"}},
+		{expr: "age != null && age % 2 == 0 && -age < -18", expected: []string{"a"}},
+		{expr: "name < 'c' && (age + 3) * 2 > 40", expected: []string{"a"}},
+	}
+
+	names := func(records []Record) []string {
+		result := make([]string, 0, len(records))
+		for _, r := range records {
+			result = append(result, r["name"].(string))
+		}
+		return result
+	}
+
+	for _, test := range filterExprTests {
+		a := NewRecordStream(func() []Record { return people }).FilterExpr(test.expr).Collect()
+		b := NewRecordStream(func() []Record { return people }).Parallelize(2).FilterExpr(test.expr).Collect()
+		assert.Equal(t, test.expected, names(a), test.expr)
+		assert.ElementsMatch(t, test.expected, names(b), test.expr)
+	}
+
+	code := func(f func()) (code int) {
+		defer func() { code = recover().(*streamError).Code() }()
+		f()
+		return 0
+	}
+	for _, expr := range []string{"age >", "(age > 1", "age > 1)", "age $ 1", "'open", "1.2.3 > 1"} {
+		assert.Equal(t, IllegalExpression, code(func() { NewRecordStream(func() []Record { return people }).FilterExpr(expr) }), expr)
+	}
+	for _, expr := range []string{"age", "name - 1", "!name", "age > 18 && name"} {
+		assert.Equal(t, IllegalExpression, code(func() { NewRecordStream(func() []Record { return people[:1] }).FilterExpr(expr).Collect() }), expr)
+	}
+
+}
+
+func TestRecordStreamMapExpr(t *testing.T) {
+
+	orders := []Record{
+		{"id": "1", "price": 2.5, "quantity": 4},
+		{"id": "2", "price": 10, "quantity": 1},
+	}
+
+	a := NewRecordStream(func() []Record { return orders }).MapExpr("total", "price * quantity").MapExpr("label", "id + '-order'").Collect()
+	assert.Equal(t, []Record{
+		{"id": "1", "price": 2.5, "quantity": 4, "total": 10.0, "label": "1-order"},
+		{"id": "2", "price": 10, "quantity": 1, "total": 10.0, "label": "2-order"},
+	}, a)
+	assert.NotContains(t, orders[0], "total")
+
+}
@@ -0,0 +1,63 @@
+package streams
+
+import (
+	"sort"
+	"sync"
+)
+
+// Pipeline a reusable chain of operations which derives a stream from a given stream.
+type Pipeline[T any] func(s Stream[T]) Stream[T]
+
+// registry pipelines registered by name.
+var registry = struct {
+	mux       sync.RWMutex
+	pipelines map[string]any
+}{pipelines: make(map[string]any)}
+
+// Register registers the pipeline under the given name so that it can be looked up and run by name, i.e for pipelines selected through configuration.
+// Register panics if a pipeline has already been registered under the name.
+func Register[T any](name string, p Pipeline[T]) {
+	registry.mux.Lock()
+	defer registry.mux.Unlock()
+	if _, ok := registry.pipelines[name]; ok || p == nil {
+		panic(errIllegalArgument(name, "Register"))
+	}
+	registry.pipelines[name] = p
+}
+
+// Unregister removes the pipeline registered under the given name, if any.
+func Unregister(name string) {
+	registry.mux.Lock()
+	defer registry.mux.Unlock()
+	delete(registry.pipelines, name)
+}
+
+// Lookup returns the pipeline registered under the given name, false is returned if there is no such pipeline or it is not a pipeline of T.
+func Lookup[T any](name string) (Pipeline[T], bool) {
+	registry.mux.RLock()
+	defer registry.mux.RUnlock()
+	p, ok := registry.pipelines[name].(Pipeline[T])
+	return p, ok
+}
+
+// Registered returns the names of the registered pipelines in sorted order.
+func Registered() []string {
+	registry.mux.RLock()
+	defer registry.mux.RUnlock()
+	names := make([]string, 0, len(registry.pipelines))
+	for name := range registry.pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunNamed runs the pipeline registered under the given name over the elements of the given supplier and returns the resulting elements. An error
+// is returned if there is no pipeline of T registered under the name.
+func RunNamed[T any](name string, supplier func() []T) ([]T, error) {
+	p, ok := Lookup[T](name)
+	if !ok {
+		return nil, errIllegalArgument(name, "RunNamed")
+	}
+	return p(New(supplier)).Collect(), nil
+}
@@ -0,0 +1,43 @@
+package streams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+
+	Register("evens", Pipeline[int](func(s Stream[int]) Stream[int] { return s.Filter(func(x int) bool { return x%2 == 0 }) }))
+	Register("first", Pipeline[int](func(s Stream[int]) Stream[int] { return s.Limit(1) }))
+	defer Unregister("evens")
+	defer Unregister("first")
+
+	type runNamedTest struct {
+		name     string
+		data     []int
+		expected []int
+		ok       bool
+	}
+
+	runNamedTests := []runNamedTest{
+		{name: "evens", data: []int{}, expected: []int{}, ok: true},
+		{name: "evens", data: []int{1, 2, 3, 4}, expected: []int{2, 4}, ok: true},
+		{name: "first", data: []int{1, 2, 3, 4}, expected: []int{1}, ok: true},
+		{name: "missing", data: []int{1, 2, 3, 4}, expected: nil, ok: false},
+	}
+
+	for _, test := range runNamedTests {
+		result, err := RunNamed(test.name, func() []int { return test.data })
+		assert.Equal(t, test.expected, result)
+		assert.Equal(t, test.ok, err == nil)
+	}
+
+	assert.Equal(t, []string{"evens", "first"}, Registered())
+	_, ok := Lookup[string]("evens")
+	assert.False(t, ok)
+	_, err := RunNamed("evens", func() []string { return []string{} })
+	assert.NotNil(t, err)
+	assert.Panics(t, func() { Register("evens", Pipeline[int](func(s Stream[int]) Stream[int] { return s })) })
+
+}
@@ -0,0 +1,101 @@
+package streams
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// circuitBreaker keeps track of consecutive failures of a function.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	mux       sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// CircuitBreaker returns a function which calls f until it fails threshold times in a row, the circuit then trips and calls fail fast with an error
+// with code CircuitOpen for the cooldown period without calling f. Once the cooldown has passed f is called again, a success closes the circuit while
+// a failure trips it for another cooldown period. The returned function is safe for use by multiple routines, i.e with MapResult on a parallel stream.
+func CircuitBreaker[T any, U any](threshold int, cooldown time.Duration, f func(x T) (U, error)) func(x T) (U, error) {
+	return circuitBreakerWithClock(threshold, cooldown, f, time.Now)
+}
+
+// circuitBreakerWithClock returns a circuit breaker which reads the current time using the given clock.
+func circuitBreakerWithClock[T any, U any](threshold int, cooldown time.Duration, f func(x T) (U, error), now func() time.Time) func(x T) (U, error) {
+	if threshold < 1 {
+		panic(errIllegalConfig("Threshold", fmt.Sprint(threshold)))
+	} else if cooldown < 0 {
+		panic(errIllegalConfig("Cooldown", fmt.Sprint(cooldown)))
+	}
+	b := &circuitBreaker{threshold: threshold, cooldown: cooldown, now: now}
+	return func(x T) (U, error) {
+		if err := b.allow(); err != nil {
+			var zero U
+			return zero, err
+		}
+		val, err := f(x)
+		b.record(err)
+		return val, err
+	}
+}
+
+// allow returns an error if the circuit is open.
+func (b *circuitBreaker) allow() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.failures >= b.threshold && b.now().Before(b.openUntil) {
+		return errCircuitOpen(b.failures, b.openUntil)
+	}
+	return nil
+}
+
+// record records the outcome of a call, tripping the circuit once the threshold of consecutive failures is reached.
+func (b *circuitBreaker) record(err error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// Timeout returns a function which calls f and returns an error with code OperationTimeout if f does not return within the given duration. The call to f
+// is not interrupted, it keeps running in its own routine and its result is discarded. A panic of f within the duration is raised again by the returned
+// function as if f had been called directly, i.e reported as an OperatorPanic by the operation calling it, a later panic is discarded.
+func Timeout[T any, U any](d time.Duration, f func(x T) (U, error)) func(x T) (U, error) {
+	if d <= 0 {
+		panic(errIllegalConfig("Timeout", fmt.Sprint(d)))
+	}
+	return func(x T) (U, error) {
+		results := make(chan Result[U], 1)
+		panics := make(chan any, 1)
+		go func() {
+			defer trackRoutine("Timeout")()
+			defer func() {
+				if r := recover(); r != nil {
+					panics <- r
+				}
+			}()
+			val, err := f(x)
+			results <- Result[U]{value: val, err: err}
+		}()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case r := <-results:
+			return r.value, r.err
+		case r := <-panics:
+			panic(r)
+		case <-timer.C:
+			var zero U
+			return zero, errTimeout(d)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package streams
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	calls := 0
+	failing := true
+	f := func(x int) (int, error) {
+		calls++
+		if failing {
+			return 0, errors.New("unavailable")
+		}
+		return x, nil
+	}
+
+	guarded := circuitBreakerWithClock(2, time.Second, f, clock)
+	code := func(err error) int {
+		var e *streamError
+		if errors.As(err, &e) {
+			return e.Code()
+		}
+		return 0
+	}
+
+	// The circuit trips after two consecutive failures and fails fast during the cooldown.
+	_, err := guarded(1)
+	assert.EqualError(t, err, "unavailable")
+	_, err = guarded(1)
+	assert.EqualError(t, err, "unavailable")
+	_, err = guarded(1)
+	assert.Equal(t, CircuitOpen, code(err))
+	assert.Equal(t, 2, calls)
+
+	// A failure after the cooldown trips the circuit again.
+	now = now.Add(time.Second)
+	_, err = guarded(1)
+	assert.EqualError(t, err, "unavailable")
+	_, err = guarded(1)
+	assert.Equal(t, CircuitOpen, code(err))
+	assert.Equal(t, 3, calls)
+
+	// A success after the cooldown closes the circuit.
+	now = now.Add(time.Second)
+	failing = false
+	x, err := guarded(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, x)
+	failing = true
+	_, err = guarded(1)
+	assert.EqualError(t, err, "unavailable")
+
+	// Used with the error aware pipeline.
+	var attempts int32
+	parse := CircuitBreaker(3, time.Hour, func(x string) (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return strconv.Atoi(x)
+	})
+	oks, errs := CollectOks(MapResult(New(func() []string { return []string{"x", "x", "x", "x", "x", "1"} }), parse))
+	assert.Empty(t, oks)
+	assert.Len(t, errs, 6)
+	assert.Equal(t, int32(3), attempts)
+
+	assert.Panics(t, func() { CircuitBreaker(0, time.Second, f) })
+
+}
+
+func TestTimeout(t *testing.T) {
+
+	release := make(chan struct{})
+	defer close(release)
+	slow := Timeout(10*time.Millisecond, func(x int) (int, error) {
+		if x > 0 {
+			<-release
+		}
+		return x, nil
+	})
+
+	x, err := slow(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, x)
+
+	_, err = slow(1)
+	var e *streamError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, OperationTimeout, e.Code())
+
+	assert.Panics(t, func() { Timeout(0, func(x int) (int, error) { return x, nil }) })
+
+	// A panic of the function is raised again by the caller, within a stream it is reported as the panic of the operation.
+	failing := Timeout(time.Second, func(x int) (int, error) { panic("boom") })
+	assert.PanicsWithValue(t, "boom", func() { failing(1) })
+	func() {
+		defer func() {
+			err, ok := recover().(Error)
+			assert.True(t, ok)
+			assert.Equal(t, OperatorPanic, err.Code())
+		}()
+		New(func() []int { return []int{1, 2} }).TryMap(failing).Collect()
+	}()
+
+	// A panic after the timeout is discarded.
+	unblock, panicked := make(chan struct{}), make(chan struct{})
+	late := Timeout(10*time.Millisecond, func(x int) (int, error) {
+		defer close(panicked)
+		<-unblock
+		panic("late")
+	})
+	_, err = late(1)
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, OperationTimeout, e.Code())
+	close(unblock)
+	<-panicked
+
+}
@@ -0,0 +1,232 @@
+package streams
+
+import (
+	"fmt"
+	runtimedebug "runtime/debug"
+	"sync"
+)
+
+// Result the outcome of a step that can fail, either a value or an error. Streams of results model failures as data flowing through the pipeline
+// rather than panics.
+type Result[T any] struct {
+	value   T
+	err     error
+	element any
+}
+
+// Ok creates a successful result holding the given value.
+func Ok[T any](x T) Result[T] {
+	return Result[T]{value: x}
+}
+
+// Fail creates a failed result holding the given error.
+func Fail[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// Value returns the value of the result, the zero value is returned for a failed result.
+func (r Result[T]) Value() T {
+	return r.value
+}
+
+// Err returns the error of the result, nil is returned for a successful result.
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Element returns the element whose step failed, nil is returned for a successful result or a result created with Fail.
+func (r Result[T]) Element() any {
+	return r.element
+}
+
+// IsOk checks if the result is successful.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// MapResult returns a stream consisting of the results of applying the given function to the elements of the stream, an error returned by the function
+// is captured in the result of the element instead of stopping the stream.
+func MapResult[T any, U any](s Stream[T], f func(x T) (U, error)) Stream[Result[U]] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return mapElements(source, func(x T) Result[U] {
+		val, err := f(x)
+		if err != nil {
+			return Result[U]{err: err, element: x}
+		}
+		return Ok(val)
+	})
+}
+
+// FilterOk returns a stream consisting of the values of the successful results of the stream, failed results are dropped.
+func FilterOk[T any](s Stream[Result[T]]) Stream[T] {
+	source := s.(*stream[Result[T]])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return transform(source, func(data []Result[T]) []T {
+		values := make([]T, 0, len(data))
+		for i := range data {
+			if data[i].IsOk() {
+				values = append(values, data[i].value)
+			}
+		}
+		return values
+	})
+}
+
+// CollectOks returns the values of the successful results and the errors of the failed results of the stream.
+func CollectOks[T any](s Stream[Result[T]]) ([]T, []error) {
+	values := make([]T, 0)
+	errs := make([]error, 0)
+	for _, result := range s.Collect() {
+		if result.IsOk() {
+			values = append(values, result.value)
+		} else {
+			errs = append(errs, result.err)
+		}
+	}
+	return values, errs
+}
+
+// FirstErr returns the error of the first failed result of the stream, nil is returned if all results are successful. A sequential stream stops
+// consuming elements at the first failed result, for a parallel stream the returned error is from any of the failed results.
+func FirstErr[T any](s Stream[Result[T]]) error {
+	var mux sync.Mutex
+	var err error
+	s.ForEachWhile(func(result Result[T]) bool {
+		if result.IsOk() {
+			return true
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		if err == nil {
+			err = result.err
+		}
+		return false
+	})
+	return err
+}
+
+// ErrorSummary a group of identical errors, i.e errors with the same type and message.
+type ErrorSummary struct {
+	Type    string // The type of the errors.
+	Message string // The message of the errors.
+	Count   int    // The number of errors in the group.
+	Samples []any  // The elements whose steps failed with the error, up to the number of samples requested.
+}
+
+// CollectErrors returns a summary of the errors of the failed results of the stream in which identical errors are grouped with their count and
+// up to the given number of sample elements, groups are in order of their first error.
+func CollectErrors[T any](s Stream[Result[T]], samples int) []ErrorSummary {
+	if samples < 0 {
+		panic(errIllegalArgument("CollectErrors", fmt.Sprint(samples)))
+	}
+	index := make(map[[2]string]int)
+	summaries := make([]ErrorSummary, 0)
+	for _, result := range s.Collect() {
+		if result.IsOk() {
+			continue
+		}
+		key := [2]string{fmt.Sprintf("%T", result.err), result.err.Error()}
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, ErrorSummary{Type: key[0], Message: key[1], Samples: make([]any, 0)})
+		}
+		summaries[i].Count++
+		if len(summaries[i].Samples) < samples {
+			summaries[i].Samples = append(summaries[i].Samples, result.element)
+		}
+	}
+	return summaries
+}
+
+// PanicError an error for a panic raised by the function of an operation while evaluating an element, see RecoverPanics.
+type PanicError struct {
+	Err     error  // The error describing the panic, it has the code OperatorPanic unless the panic was raised by the package.
+	Element any    // The source element being evaluated when the panic was raised.
+	Stack   []byte // The stack trace of the routine that raised the panic.
+}
+
+// Error returns the error message.
+func (err *PanicError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the error describing the panic.
+func (err *PanicError) Unwrap() error {
+	return err.Err
+}
+
+// RecoverPanics returns a stream consisting of the results of evaluating the elements of the stream, a panic raised by the function of an operation
+// of the stream (i.e Map or Filter) while evaluating an element is converted to a failed result with a PanicError instead of stopping the terminal
+// operation. Elements dropped by the operations have no result, panics raised by operations added to the returned stream are not recovered.
+func RecoverPanics[T any](s Stream[T]) Stream[Result[T]] {
+	source := s.(*stream[T])
+	if err := source.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, e := source.supplier, source.operations, source.executor
+	if source.appended != nil {
+		// The operations of a paged source are applied here instead of when pulling its pages, so that their panics are recovered as well.
+		operations = source.declared()
+	}
+	recoverPage := func(data []T) []Result[T] { return recoverOperations(data, operations) }
+	if source.parallel {
+		recoverPage = func(data []T) []Result[T] {
+			results := make([]Result[T], 0)
+			for _, partial := range run(data, e, func(partition []T) []Result[T] { return recoverOperations(partition, operations) }) {
+				results = append(results, partial...)
+			}
+			return results
+		}
+	}
+	result := &stream[Result[T]]{
+		supplier:   func() []Result[T] { return recoverPage(supplier()) },
+		operations: make([]operator[Result[T]], 0),
+		parallel:   source.parallel,
+		executor:   e,
+		release:    source.release,
+		interrupt:  source.interrupt,
+		origin:     source.origin,
+	}
+	if source.appended != nil {
+		raw := *source.appended
+		raw.operations, raw.parallel = nil, false
+		next := raw.pull()
+		a := paged(func() ([]Result[T], bool) {
+			data, ok := next()
+			return recoverPage(data), ok && !exhausted(operations)
+		})
+		result.appended = a.evaluatedBy(source.parallel, e)
+		result.supplier = result.appended.supply
+	}
+	return result
+}
+
+// recoverOperations applies the given operations on each element of the data, a panic raised while applying them is captured in the result of the
+// element.
+func recoverOperations[T any](data []T, operations []operator[T]) []Result[T] {
+	results := make([]Result[T], 0, len(data))
+	for i := range data {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err, ok := r.(error)
+					if !ok {
+						err = fmt.Errorf("%v", r)
+					}
+					results = append(results, Result[T]{err: &PanicError{Err: err, Element: data[i], Stack: runtimedebug.Stack()}, element: data[i]})
+				}
+			}()
+			if val, ok := applyOperations(data[i], operations); ok {
+				results = append(results, Ok(val))
+			}
+		}()
+	}
+	return results
+}
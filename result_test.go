@@ -0,0 +1,186 @@
+package streams
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult(t *testing.T) {
+
+	type resultTest struct {
+		data     []string
+		oks      []int
+		errs     int
+		firstErr bool
+	}
+
+	resultTests := []resultTest{
+		{data: []string{}, oks: []int{}, errs: 0, firstErr: false},
+		{data: []string{"1", "2", "3"}, oks: []int{1, 2, 3}, errs: 0, firstErr: false},
+		{data: []string{"1", "x", "3", "y"}, oks: []int{1, 3}, errs: 2, firstErr: true},
+	}
+
+	for _, test := range resultTests {
+		a, aErrs := CollectOks(MapResult(New(func() []string { return test.data }), strconv.Atoi))
+		b, bErrs := CollectOks(MapResult(New(func() []string { return test.data }).Parallelize(2), strconv.Atoi))
+		assert.ElementsMatch(t, test.oks, a)
+		assert.ElementsMatch(t, test.oks, b)
+		assert.Len(t, aErrs, test.errs)
+		assert.Len(t, bErrs, test.errs)
+
+		c := FilterOk(MapResult(New(func() []string { return test.data }), strconv.Atoi)).Collect()
+		d := FilterOk(MapResult(New(func() []string { return test.data }).Parallelize(2), strconv.Atoi)).Collect()
+		assert.ElementsMatch(t, test.oks, c)
+		assert.ElementsMatch(t, test.oks, d)
+
+		e := FirstErr(MapResult(New(func() []string { return test.data }), strconv.Atoi))
+		f := FirstErr(MapResult(New(func() []string { return test.data }).Parallelize(2), strconv.Atoi))
+		assert.Equal(t, test.firstErr, e != nil)
+		assert.Equal(t, test.firstErr, f != nil)
+	}
+
+	// The first error of a sequential stream is the error of the first failed element.
+	err := FirstErr(MapResult(New(func() []string { return []string{"1", "x", "y"} }), strconv.Atoi))
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	assert.Contains(t, err.Error(), `"x"`)
+
+	// The first error is returned as soon as it is pulled from an open channel.
+	for _, parallel := range []bool{false, true} {
+		channel := make(chan string, 3)
+		channel <- "1"
+		channel <- "x"
+		s, _ := FromChannel(channel)
+		if parallel {
+			s = s.Parallelize(2)
+		}
+		err := FirstErr(MapResult(s, strconv.Atoi))
+		assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	}
+
+	ok, fail := Ok(1), Fail[int](errors.New("failed"))
+	assert.True(t, ok.IsOk())
+	assert.Equal(t, 1, ok.Value())
+	assert.Nil(t, ok.Err())
+	assert.False(t, fail.IsOk())
+	assert.Equal(t, 0, fail.Value())
+	assert.EqualError(t, fail.Err(), "failed")
+
+}
+
+func TestCollectErrors(t *testing.T) {
+
+	errOdd := errors.New("odd")
+	check := func(x int) (int, error) {
+		if x < 0 {
+			return 0, fmt.Errorf("negative: %d", x)
+		} else if x%2 == 1 {
+			return 0, errOdd
+		}
+		return x, nil
+	}
+
+	type collectErrorsTest struct {
+		data     []int
+		samples  int
+		expected []ErrorSummary
+	}
+
+	collectErrorsTests := []collectErrorsTest{
+		{data: []int{}, samples: 1, expected: []ErrorSummary{}},
+		{data: []int{2, 4}, samples: 1, expected: []ErrorSummary{}},
+		{data: []int{1, 2, 3, -1, 5, -1}, samples: 2, expected: []ErrorSummary{
+			{Type: "*errors.errorString", Message: "odd", Count: 3, Samples: []any{1, 3}},
+			{Type: "*errors.errorString", Message: "negative: -1", Count: 2, Samples: []any{-1, -1}},
+		}},
+		{data: []int{1, 3}, samples: 0, expected: []ErrorSummary{{Type: "*errors.errorString", Message: "odd", Count: 2, Samples: []any{}}}},
+	}
+
+	for _, test := range collectErrorsTests {
+		a := CollectErrors(MapResult(New(func() []int { return test.data }), check), test.samples)
+		b := CollectErrors(MapResult(New(func() []int { return test.data }).Parallelize(2), check), test.samples)
+		assert.Equal(t, test.expected, a)
+		counts := func(summaries []ErrorSummary) map[string]int {
+			m := make(map[string]int)
+			for _, summary := range summaries {
+				m[summary.Message] = summary.Count
+			}
+			return m
+		}
+		assert.Equal(t, counts(test.expected), counts(b))
+	}
+
+	assert.Nil(t, Ok(1).Element())
+	assert.Panics(t, func() { CollectErrors(MapResult(New(func() []int { return []int{} }), check), -1) })
+}
+
+func TestRecoverPanics(t *testing.T) {
+
+	inverse := func(x int) int { return 12 / x }
+	even := func(x int) bool { return x%2 == 0 }
+
+	type recoverPanicsTest struct {
+		data   []int
+		oks    []int
+		failed []any
+	}
+
+	recoverPanicsTests := []recoverPanicsTest{
+		{data: []int{}, oks: []int{}, failed: []any{}},
+		{data: []int{1, 2, 3, 6}, oks: []int{6, 2}, failed: []any{}},
+		{data: []int{0, 2, 0, 4}, oks: []int{6, 3}, failed: []any{0, 0}},
+	}
+
+	for _, test := range recoverPanicsTests {
+		a := RecoverPanics(New(func() []int { return test.data }).Filter(even).Map(inverse)).Collect()
+		b := RecoverPanics(New(func() []int { return test.data }).Parallelize(2).Filter(even).Map(inverse)).Collect()
+		for _, results := range [][]Result[int]{a, b} {
+			oks, failed := []int{}, []any{}
+			for _, result := range results {
+				if result.IsOk() {
+					oks = append(oks, result.Value())
+				} else {
+					failed = append(failed, result.Element())
+				}
+			}
+			assert.ElementsMatch(t, test.oks, oks)
+			assert.ElementsMatch(t, test.failed, failed)
+		}
+	}
+
+	results := RecoverPanics(New(func() []int { return []int{1, 0} }).Map(inverse)).Collect()
+	assert.True(t, results[0].IsOk())
+	var panicErr *PanicError
+	assert.True(t, errors.As(results[1].Err(), &panicErr))
+	assert.Equal(t, 0, panicErr.Element)
+	assert.Contains(t, string(panicErr.Stack), "runtime/debug.Stack")
+	var streamErr Error
+	assert.True(t, errors.As(results[1].Err(), &streamErr))
+	assert.Equal(t, OperatorPanic, streamErr.Code())
+
+	summaries := CollectErrors(RecoverPanics(New(func() []int { return []int{0, 1, 0} }).Map(inverse)), 1)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, 2, summaries[0].Count)
+
+	// The pages of an open channel are evaluated as they are pulled, panics of the operations applied to them are recovered.
+	for _, parallel := range []bool{false, true} {
+		channel := make(chan int, 3)
+		channel <- 1
+		channel <- 0
+		channel <- 2
+		source, _ := FromChannel(channel)
+		if parallel {
+			source = source.Parallelize(2)
+		}
+		oks, errs := CollectOks(RecoverPanics(source.Map(inverse)).Limit(3))
+		assert.ElementsMatch(t, []int{12, 6}, oks)
+		assert.Len(t, errs, 1)
+	}
+
+	s := New(func() []int { return []int{} })
+	RecoverPanics(s)
+	assert.Panics(t, func() { RecoverPanics(s) })
+}
@@ -0,0 +1,74 @@
+package streams
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Handler handles an element drained from one of the inputs of Select.
+type Handler func(x any) error
+
+// SelectError the errors returned by handlers of Select, keyed by the name of the input whose elements caused them.
+type SelectError struct {
+	Errs map[string][]error
+}
+
+// Error returns the error message.
+func (err SelectError) Error() string {
+	names := make([]string, 0, len(err.Errs))
+	for name := range err.Errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buffer bytes.Buffer
+	buffer.WriteString("ErrSelect: ")
+	for i, name := range names {
+		if i > 0 {
+			buffer.WriteString("; ")
+		}
+		fmt.Fprintf(&buffer, "%s: %d error(s), first error: %v", name, len(err.Errs[name]), err.Errs[name][0])
+	}
+	return buffer.String()
+}
+
+// Select concurrently drains the given streams and dispatches each element to the handler with the same name as its stream. An error returned by a
+// handler does not stop the draining of its stream, the errors of all handlers are returned together as a SelectError once all streams have been
+// drained, nil is returned if there were none. A handler is called concurrently if its stream is parallel. Select panics if a stream has no handler
+// or has already been closed. A panic raised while draining a stream stops the draining of the other streams and is raised again once their
+// routines have returned.
+func Select(handlers map[string]Handler, inputs map[string]Stream[any]) error {
+	for name, input := range inputs {
+		if handlers[name] == nil {
+			panic(errIllegalArgument("Select", name))
+		} else if ok, err := input.(*stream[any]).valid(); !ok {
+			panic(err)
+		}
+	}
+
+	var mux sync.Mutex
+	errs := make(map[string][]error)
+	g := group{operation: "Select"}
+	for name, input := range inputs {
+		name, input, handler := name, input, handlers[name]
+		g.spawn(func() {
+			input.ForEachWhile(func(x any) bool {
+				if g.done() {
+					return false
+				} else if err := handler(x); err != nil {
+					mux.Lock()
+					defer mux.Unlock()
+					errs[name] = append(errs[name], err)
+				}
+				return true
+			})
+		})
+	}
+	g.wait()
+
+	if len(errs) > 0 {
+		return SelectError{Errs: errs}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package streams
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelect(t *testing.T) {
+
+	numbers := func() []any { return []any{1, 2, 3, 4, 5, 6} }
+	words := func() []any { return []any{"a", "bb", "", "ccc"} }
+
+	var mux sync.Mutex
+	var sum int
+	var letters int
+	handlers := map[string]Handler{
+		"numbers": func(x any) error {
+			mux.Lock()
+			defer mux.Unlock()
+			sum += x.(int)
+			return nil
+		},
+		"words": func(x any) error {
+			if x.(string) == "" {
+				return errors.New("empty word")
+			}
+			mux.Lock()
+			defer mux.Unlock()
+			letters += len(x.(string))
+			return nil
+		},
+	}
+
+	err := Select(handlers, map[string]Stream[any]{"numbers": New(numbers).Parallelize(2), "words": New(words)})
+	assert.Equal(t, 21, sum)
+	assert.Equal(t, 6, letters)
+	assert.Equal(t, SelectError{Errs: map[string][]error{"words": {errors.New("empty word")}}}, err)
+	assert.Equal(t, "ErrSelect: words: 1 error(s), first error: empty word", err.Error())
+
+	sum = 0
+	assert.Nil(t, Select(handlers, map[string]Stream[any]{"numbers": New(numbers)}))
+	assert.Equal(t, 21, sum)
+	assert.Nil(t, Select(handlers, map[string]Stream[any]{}))
+
+	failing := func(x any) error { return fmt.Errorf("failed %v", x) }
+	err = Select(map[string]Handler{"a": failing, "b": failing}, map[string]Stream[any]{"b": New(numbers), "a": New(words)})
+	assert.Equal(t, "ErrSelect: a: 4 error(s), first error: failed a; b: 6 error(s), first error: failed 1", err.Error())
+
+	assert.Panics(t, func() { Select(handlers, map[string]Stream[any]{"missing": New(numbers)}) })
+	closed := New(numbers)
+	closed.Count()
+	assert.Panics(t, func() { Select(handlers, map[string]Stream[any]{"numbers": closed}) })
+
+	// A panic of a handler or an input is raised again by Select instead of crashing the process.
+	panicking := func(x any) error { panic(x) }
+	assert.PanicsWithValue(t, "a", func() { Select(map[string]Handler{"a": panicking}, map[string]Stream[any]{"a": New(words).Limit(1)}) })
+	assert.Panics(t, func() {
+		Select(handlers, map[string]Stream[any]{"numbers": New(numbers).Map(func(x any) any { panic(x) }), "words": New(words)})
+	})
+
+}
@@ -0,0 +1,80 @@
+//go:build go1.23
+
+package streams
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// seqPageSize the maximum number of values pulled from a sequence at a time.
+const seqPageSize = 64
+
+// FromSeq creates a new stream whose elements are the values yielded by the given sequence, the sequence is iterated when the stream is evaluated.
+// Values are pulled in pages of up to 64 values, the next page is only pulled once the previous one has been evaluated and only if the operations
+// of the stream can still pass elements, so that a Limit cuts off an infinite sequence (see FromChannel). It requires Go 1.23, as does Seq.
+func FromSeq[T any](seq iter.Seq[T]) Stream[T] {
+	if seq == nil {
+		panic(errIllegalArgument("FromSeq", "nil"))
+	}
+	var next func() (T, bool)
+	var stop func()
+	a := paged(func() ([]T, bool) {
+		if next == nil {
+			next, stop = iter.Pull(seq)
+		}
+		data := make([]T, 0, seqPageSize)
+		for len(data) < seqPageSize {
+			x, ok := next()
+			if !ok {
+				return data, false
+			}
+			data = append(data, x)
+		}
+		return data, true
+	})
+	return &stream[T]{
+		supplier:   a.supply,
+		operations: make([]operator[T], 0),
+		origin:     seqSourceName,
+		release: func(bool) {
+			if stop != nil {
+				stop()
+			}
+		},
+		appended: a,
+	}
+}
+
+// sequenced the methods of a stream that rely on range-over-func iteration, which requires Go 1.23.
+type sequenced[T any] interface {
+	Seq() iter.Seq[T] // Returns a sequence yielding the elements of the stream, for iterating the stream with a range loop.
+}
+
+// Seq returns a sequence yielding the elements of the stream, so that the stream can be iterated with a range loop. The stream is evaluated as the
+// sequence is iterated and the evaluation stops once the loop is exited, a sequence can only be iterated once. The elements of a parallel stream are
+// yielded one at a time, in the order in which the routines of the stream produce them.
+func (s *stream[T]) Seq() iter.Seq[T] {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	var iterated int32
+	return func(yield func(T) bool) {
+		if !atomic.CompareAndSwapInt32(&iterated, 0, 1) {
+			err := errStreamTerminated()
+			panic(&err)
+		}
+		var mux sync.Mutex
+		stopped := false
+		s.forEachWhile(func(x T) bool {
+			mux.Lock()
+			defer mux.Unlock()
+			if stopped || !yield(x) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+	}
+}
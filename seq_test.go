@@ -0,0 +1,223 @@
+//go:build go1.23
+
+package streams
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSeq(t *testing.T) {
+
+	s := FromSeq(slices.Values([]int{1, 2, 3, 4}))
+	assert.Equal(t, []int{2, 4}, s.Filter(func(x int) bool { return x%2 == 0 }).Collect())
+	assert.ElementsMatch(t, []int{1, 2, 3, 4}, FromSeq(slices.Values([]int{1, 2, 3, 4})).Parallelize(2).Collect())
+	assert.Contains(t, FromSeq(slices.Values([]int{})).String(), "source=SEQ")
+	assert.Panics(t, func() { FromSeq[int](nil) })
+
+	// An infinite sequence is cut off by a Limit and stopped once the stream has been evaluated.
+	var pulled int32
+	stopped := false
+	naturals := func(yield func(int) bool) {
+		defer func() { stopped = true }()
+		for i := 0; ; i++ {
+			atomic.AddInt32(&pulled, 1)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	assert.Equal(t, []int{0, 2, 4}, FromSeq(naturals).Filter(func(x int) bool { return x%2 == 0 }).Limit(3).Collect())
+	assert.True(t, stopped)
+	assert.LessOrEqual(t, atomic.LoadInt32(&pulled), int32(seqPageSize+1))
+	assert.Len(t, FromSeq(naturals).Parallelize(2).Limit(100).Collect(), 100)
+	count := 0
+	for range FromSeq(naturals).Seq() {
+		if count++; count == 200 {
+			break
+		}
+	}
+	assert.Equal(t, 200, count)
+
+}
+
+func TestSeq(t *testing.T) {
+
+	data := []int{1, 2, 3, 4, 5}
+
+	results := make([]int, 0)
+	for x := range New(func() []int { return data }).Map(func(x int) int { return x * 2 }).Seq() {
+		results = append(results, x)
+	}
+	assert.Equal(t, []int{2, 4, 6, 8, 10}, results)
+
+	results = make([]int, 0)
+	for x := range New(func() []int { return data }).Parallelize(2).Seq() {
+		results = append(results, x)
+	}
+	assert.ElementsMatch(t, data, results)
+
+	// Exiting the loop stops the evaluation.
+	var calls int32
+	for x := range New(func() []int { return data }).Peek(func(x int) { atomic.AddInt32(&calls, 1) }).Seq() {
+		if x == 2 {
+			break
+		}
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	count := 0
+	for range New(func() []int { return data }).ParallelizeWith(IOBound(2)).Seq() {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	// A sequence can only be iterated once.
+	s := New(func() []int { return data })
+	seq := s.Seq()
+	assert.True(t, s.Terminated())
+	assert.Equal(t, data, slices.Collect(seq))
+	assert.Panics(t, func() { _ = slices.Collect(seq) })
+	assert.Panics(t, func() { s.Seq() })
+
+}
+
+type nopScheduler struct{}
+
+func (nopScheduler) BeforePartitionStart(partition int) {}
+
+func (nopScheduler) AfterPartitionEnd(partition int) {}
+
+func (nopScheduler) BeforeMerge() {}
+
+func TestFromSeqModifiers(t *testing.T) {
+
+	naturals := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	even := func(x int) bool { return x%2 == 0 }
+
+	type fromSeqModifiersTest struct {
+		s        func(s Stream[int]) Stream[int]
+		expected []int
+	}
+
+	// Operations added after a modifier are still evaluated a page at a time, so a Limit cuts off the infinite sequence.
+	fromSeqModifiersTests := []fromSeqModifiersTest{
+		{s: func(s Stream[int]) Stream[int] { return s.Ordered() }, expected: []int{0, 1, 2}},
+		{s: func(s Stream[int]) Stream[int] { return s.FailFast() }, expected: []int{0, 1, 2}},
+		{s: func(s Stream[int]) Stream[int] { return s.WithCapture(2) }, expected: []int{0, 1, 2}},
+		{s: func(s Stream[int]) Stream[int] { return s.WithHasher(MaphashHasher()) }, expected: []int{0, 1, 2}},
+		{s: func(s Stream[int]) Stream[int] { return s.WithScheduler(nopScheduler{}) }, expected: []int{0, 1, 2}},
+		{s: func(s Stream[int]) Stream[int] { return s.ParallelizeAuto() }, expected: []int{0, 1, 2}},
+		{s: func(s Stream[int]) Stream[int] { return s.Filter(even).Ordered() }, expected: []int{0, 2, 4}},
+		{s: func(s Stream[int]) Stream[int] { return s.WithOffset(100) }, expected: []int{100, 101, 102}},
+		{s: func(s Stream[int]) Stream[int] { return s.StrideStream(3) }, expected: []int{0, 3, 6}},
+		{s: func(s Stream[int]) Stream[int] { return s.WithCostEstimator(func(x int) int { return x }) }, expected: []int{0, 1, 2}},
+	}
+
+	for _, test := range fromSeqModifiersTests {
+		assert.Equal(t, test.expected, test.s(FromSeq(naturals)).Limit(3).Collect())
+		assert.Len(t, test.s(FromSeq(naturals).Parallelize(2)).Limit(3).Collect(), 3)
+	}
+
+	// Restrictions of the source follow the pages.
+	assert.Equal(t, []int{60, 63, 66, 69, 72}, FromSeq(naturals).WithOffset(60).StrideStream(3).Limit(5).Collect())
+	assert.Equal(t, []int{60, 62, 64, 66}, FromSeq(naturals).SubStream(60, 70).Filter(even).Limit(4).Collect())
+
+}
+
+func TestFromSeqShortCircuit(t *testing.T) {
+
+	var stopped int32
+	naturals := func(yield func(int) bool) {
+		defer atomic.StoreInt32(&stopped, 1)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	failAt := func(n int) func(x int) (int, error) {
+		return func(x int) (int, error) {
+			if x == n {
+				return 0, fmt.Errorf("failed at %d", x)
+			}
+			return x, nil
+		}
+	}
+	presentAt := func(n int) func(x int) (int, bool) {
+		return func(x int) (int, bool) { return x, x == n }
+	}
+
+	// Terminal operations that stop early pull the infinite sequence a page at a time and stop it once they return.
+	for _, parallel := range []bool{false, true} {
+		s := func() Stream[int] {
+			atomic.StoreInt32(&stopped, 0)
+			if parallel {
+				return FromSeq(naturals).Parallelize(2)
+			}
+			return FromSeq(naturals)
+		}
+		assert.EqualError(t, FirstErr(MapResult(s(), failAt(1000))), "failed at 1000")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+		assert.Equal(t, Some(1000), FirstPresent(MapOptional(s(), presentAt(1000))))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+		results, err := s().CollectLimited(100)
+		assert.Nil(t, results)
+		assert.Equal(t, CapacityExceeded, err.(*streamError).Code())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+		results, err = s().Limit(100).CollectLimited(100)
+		assert.Nil(t, err)
+		assert.Len(t, results, 100)
+		cursor := s().Open()
+		for i := 0; i < 3; i++ {
+			x, ok := cursor.Next()
+			assert.Equal(t, i, x)
+			assert.True(t, ok)
+		}
+		cursor.Close()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+	}
+
+	// A zip ends with the shorter stream and stops the infinite sequence.
+	atomic.StoreInt32(&stopped, 0)
+	pairs := Zip(FromSeq(naturals).Filter(func(x int) bool { return x > 100 }), New(func() []string { return []string{"a", "b"} })).Collect()
+	assert.Equal(t, []Pair[int, string]{{101, "a"}, {102, "b"}}, pairs)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+	assert.Len(t, ZipWith(FromSeq(naturals), FromSeq(naturals).Parallelize(2), func(x, y int) int { return x + y }).Limit(200).Collect(), 200)
+
+}
+
+func TestFromSeqSummary(t *testing.T) {
+
+	numbers := func(yield func(int) bool) {
+		for i := 0; i < 1000; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	// Only one page of the sequence is held at a time.
+	s := FromSeq(numbers)
+	s.ForEach(func(x int) {})
+	assert.Equal(t, seqPageSize, s.Summary().PeakBuffer)
+	assert.Equal(t, 1000, s.Summary().Read)
+
+	s = FromSeq(numbers)
+	cursor := s.Open()
+	for _, ok := cursor.Next(); ok; _, ok = cursor.Next() {
+	}
+	assert.Equal(t, seqPageSize, s.Summary().PeakBuffer)
+
+}
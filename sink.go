@@ -0,0 +1,68 @@
+package streams
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	sinkAttempts = 3                     // Default number of times a batch is offered to a sink before DrainTo gives up.
+	sinkBackoff  = 50 * time.Millisecond // Default time waited before the first retry of a batch, doubled before each following retry.
+)
+
+// Sink an external system the elements of a stream are written to in batches. Write may be called again with the same batch if it returns an error,
+// once all batches have been written Commit is called exactly once.
+type Sink[T any] interface {
+	Write(batch []T) error // Writes the given batch of elements to the sink.
+	Commit() error         // Commits the batches written to the sink.
+}
+
+// sinkOptions the retry policy of DrainTo.
+type sinkOptions struct {
+	attempts int
+	backoff  time.Duration
+	sleep    func(d time.Duration)
+}
+
+// SinkOption an option for writing the elements of a stream to a sink with DrainTo.
+type SinkOption func(o *sinkOptions)
+
+// Retry returns an option which offers a batch to the sink up to the given number of attempts, waiting for backoff before the first retry and twice
+// as long before each following retry. The default is 3 attempts with a backoff of 50ms, a backoff of 0 retries immediately.
+func Retry(attempts int, backoff time.Duration) SinkOption {
+	if attempts < 1 {
+		panic(errIllegalArgument("Retry", fmt.Sprint(attempts)))
+	} else if backoff < 0 {
+		panic(errIllegalArgument("Retry", fmt.Sprint(backoff)))
+	}
+	return func(o *sinkOptions) {
+		o.attempts = attempts
+		o.backoff = backoff
+	}
+}
+
+// newSinkOptions returns the retry policy given by the options.
+func newSinkOptions(opts []SinkOption) sinkOptions {
+	o := sinkOptions{attempts: sinkAttempts, backoff: sinkBackoff, sleep: time.Sleep}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// writeBatch writes the batch to the sink, retrying with an exponential backoff up to the number of attempts of the options and returning the last
+// error if none of the attempts succeeded.
+func writeBatch[T any](sink Sink[T], batch []T, o sinkOptions) error {
+	var err error
+	backoff := o.backoff
+	for i := 0; i < o.attempts; i++ {
+		if i > 0 && backoff > 0 {
+			o.sleep(backoff)
+			backoff *= 2
+		}
+		if err = sink.Write(batch); err == nil {
+			return nil
+		}
+	}
+	return err
+}
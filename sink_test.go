@@ -0,0 +1,97 @@
+package streams
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memorySink a sink which keeps written batches in memory and fails the given number of writes.
+type memorySink struct {
+	batches   [][]int
+	failures  int
+	writes    int
+	commits   int
+	commitErr error
+}
+
+func (sink *memorySink) Write(batch []int) error {
+	sink.writes++
+	if sink.failures > 0 {
+		sink.failures--
+		return errors.New("write failed")
+	}
+	sink.batches = append(sink.batches, append([]int{}, batch...))
+	return nil
+}
+
+func (sink *memorySink) Commit() error {
+	sink.commits++
+	return sink.commitErr
+}
+
+func TestDrainTo(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5, 6, 7} }
+
+	type drainToTest struct {
+		s               Stream[int]
+		sink            *memorySink
+		batchSize       int
+		expectedBatches [][]int
+		expectedWrites  int
+		expectedCommits int
+		expectedErr     bool
+	}
+
+	drainToTests := []drainToTest{
+		{s: New(supplier), sink: &memorySink{}, batchSize: 3, expectedBatches: [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, expectedWrites: 3, expectedCommits: 1},
+		{s: New(supplier), sink: &memorySink{failures: 2}, batchSize: 7, expectedBatches: [][]int{{1, 2, 3, 4, 5, 6, 7}}, expectedWrites: 3, expectedCommits: 1},
+		{s: New(supplier), sink: &memorySink{failures: 3}, batchSize: 2, expectedBatches: nil, expectedWrites: 3, expectedCommits: 0, expectedErr: true},
+		{s: New(supplier), sink: &memorySink{commitErr: errors.New("commit failed")}, batchSize: 10, expectedBatches: [][]int{{1, 2, 3, 4, 5, 6, 7}}, expectedWrites: 1, expectedCommits: 1, expectedErr: true},
+		{s: New(supplier).Filter(func(x int) bool { return false }), sink: &memorySink{}, batchSize: 2, expectedBatches: nil, expectedWrites: 0, expectedCommits: 1},
+	}
+
+	var waits []time.Duration
+	record := func(o *sinkOptions) { o.sleep = func(d time.Duration) { waits = append(waits, d) } }
+
+	for _, test := range drainToTests {
+		err := test.s.DrainTo(test.sink, test.batchSize, record)
+		assert.Equal(t, test.expectedErr, err != nil)
+		assert.Equal(t, test.expectedBatches, test.sink.batches)
+		assert.Equal(t, test.expectedWrites, test.sink.writes)
+		assert.Equal(t, test.expectedCommits, test.sink.commits)
+	}
+
+	// Writes of a parallel stream are not concurrent, so the sink needs no synchronization.
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	sink := &memorySink{}
+	assert.Nil(t, New(func() []int { return data }).Parallelize(4).DrainTo(sink, 10))
+	assert.Equal(t, 100, len(sink.batches))
+	assert.Equal(t, 1, sink.commits)
+	assert.ElementsMatch(t, data, New(func() [][]int { return sink.batches }).Reduce(func(x, y []int) []int { return append(x, y...) }))
+
+	// Retries wait for a backoff that doubles after each retry.
+	assert.Equal(t, []time.Duration{sinkBackoff, 2 * sinkBackoff, sinkBackoff, 2 * sinkBackoff}, waits)
+	waits = nil
+	sink = &memorySink{failures: 4}
+	assert.Nil(t, New(supplier).DrainTo(sink, 10, Retry(5, time.Second), record))
+	assert.Equal(t, 5, sink.writes)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}, waits)
+	waits = nil
+	sink = &memorySink{failures: 1}
+	assert.NotNil(t, New(supplier).DrainTo(sink, 10, Retry(1, 0), record))
+	assert.Equal(t, 1, sink.writes)
+	assert.Empty(t, waits)
+	assert.Panics(t, func() { Retry(0, time.Second) })
+	assert.Panics(t, func() { Retry(1, -time.Second) })
+
+	assert.Panics(t, func() { New(supplier).DrainTo(&memorySink{}, 0) })
+	assert.Panics(t, func() { New(supplier).DrainTo(nil, 1) })
+
+}
@@ -0,0 +1,152 @@
+package streams
+
+import "sort"
+
+// sorting the source of a sorted stream, kept so that a Distinct following the sort can be evaluated in the same pass.
+type sorting[T any] struct {
+	source     *stream[T]
+	operations []operator[T]
+	less       func(x, y T) bool
+}
+
+// Sorted returns a stream consisting of the elements of this stream sorted according to the given less function, elements that are equal keep
+// their encounter order. Sorting requires all the elements so the operations of this stream are evaluated first. A parallel stream stays parallel and
+// keeps the sorted order in Collect and Reduce (see Ordered), other operations following the sort are not ordered. A Distinct directly before or after
+// Sorted is evaluated along with the sort using a single sorted set.
+func (s *stream[T]) Sorted(less func(x, y T) bool) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if less == nil {
+		panic(errIllegalArgument("Sorted", "nil"))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	if n := len(s.operations); n > 0 && s.operations[n-1].hash != nil {
+		return sorted(s, s, s.operations[:n-1], less, s.operations[n-1].hash, true)
+	}
+	return sorted(s, s, s.operations, less, nil, false)
+}
+
+// prioritized an element along with its priority.
+type prioritized[T any] struct {
+	priority int
+	x        T
+}
+
+// Prioritize returns a stream consisting of the elements of this stream ordered by descending priority (computed using the given function), elements
+// with the same priority keep their encounter order. A parallel stream dispatches elements to its routines one at a time in that order so that
+// elements with a higher priority are processed first, i.e before a later Limit or LimitDuration cuts the stream off. Ordering requires all the
+// elements so the operations of this stream are evaluated first.
+func (s *stream[T]) Prioritize(priority func(x T) int) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if priority == nil {
+		panic(errIllegalArgument("Prioritize", "nil"))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier := elementsSupplier(s)
+	e := s.executor
+	e.perElement = true
+	result := s.sourced(func() []T {
+		data := supplier()
+		elements := make([]prioritized[T], len(data))
+		for i, x := range data {
+			elements[i] = prioritized[T]{priority: priority(x), x: x}
+		}
+		elements = stableSort(elements, func(x, y prioritized[T]) bool { return x.priority > y.priority })
+		for i := range elements {
+			data[i] = elements[i].x
+		}
+		return data
+	})
+	result.auto, result.upstream, result.executor = false, sortedPlan(s, s.operations), e
+	return result
+}
+
+// sorted returns a stream of the elements resulting from applying the given operations to the source of s sorted using less, if hash is not nil the
+// stream is also made distinct using hash either before (keeping the first element in encounter order for each hash) or after sorting. The elements
+// of a parallel stream are collected in encounter order so that the sort is stable, the stream stays parallel and keeps the sorted order in Collect
+// and Reduce.
+func sorted[T any](s, settings *stream[T], operations []operator[T], less func(x, y T) bool, hash func(x T) string, distinctFirst bool) *stream[T] {
+	source := &stream[T]{supplier: s.supplier, operations: operations, parallel: s.parallel, executor: s.executor, ordered: true}
+	supplier, hasher := elementsSupplier(source), settings.hasher
+	result := settings.sourced(func() []T {
+		if hash == nil {
+			return stableSort(supplier(), less)
+		}
+		return sortDistinct(supplier(), less, hash, hasher, distinctFirst)
+	})
+	result.distinct = settings.distinct || hash != nil
+	result.ordered = settings.ordered || settings.parallel
+	result.upstream = sortedPlan(s, operations)
+	if hash == nil {
+		result.sorting = &sorting[T]{source: s, operations: operations, less: less}
+	}
+	return result
+}
+
+// sortedPlan returns a function checking the plan of the given operations of s, which are evaluated before the elements are sorted. The elements kept
+// by a stateful operation of a parallel stream (i.e Distinct or Limit) depend on the scheduling of its routines, so the sorted elements would not be
+// deterministic.
+func sortedPlan[T any](s *stream[T], operations []operator[T]) func() *streamError {
+	upstream, parallel := s.upstream, s.parallel
+	return func() *streamError {
+		if upstream != nil {
+			if err := upstream(); err != nil {
+				return err
+			}
+		}
+		if err := plan(operations, parallel); err != nil {
+			return err
+		}
+		for i, operation := range operations {
+			if parallel && operation.stateful {
+				return errIllegalPlan("the elements kept by a stateful operation of a parallel stream depend on the scheduling of its routines, sorting them is not deterministic", operation.name, i)
+			}
+		}
+		return nil
+	}
+}
+
+// indexed an element along with its position in the encounter order.
+type indexed[T any] struct {
+	i int
+	x T
+}
+
+// sortDistinct returns the distinct elements (according to hash) of the given data sorted using less in a single tree set, elements that are equal
+// according to less are ordered by their position so that the sort is stable. If distinctFirst is set the first element in encounter order is kept
+// for each hash, as for Distinct followed by Sorted, otherwise the first element in sorted order is kept, as for Sorted followed by Distinct.
+func sortDistinct[T any](data []T, less func(x, y T) bool, hash func(x T) string, hasher Hasher[string], distinctFirst bool) []T {
+	set := newSortedSet(nil, func(x, y indexed[T]) bool { return less(x.x, y.x) || (!less(y.x, x.x) && x.i < y.i) })
+	seen := newKeyIndex(hasher)
+	// first checks if the given element is the first with its hash.
+	first := func(x T) bool {
+		key := hash(x)
+		if _, ok := seen.get(key); ok {
+			return false
+		}
+		seen.put(key, 0)
+		return true
+	}
+	for i, x := range data {
+		if !distinctFirst || first(x) {
+			set.Add(indexed[T]{i: i, x: x})
+		}
+	}
+	results := make([]T, 0, set.Len())
+	set.Ascend(func(x indexed[T]) bool {
+		if distinctFirst || first(x.x) {
+			results = append(results, x.x)
+		}
+		return true
+	})
+	return results
+}
+
+// stableSort sorts the given data using less keeping the order of equal elements, returns the sorted data.
+func stableSort[T any](data []T, less func(x, y T) bool) []T {
+	sort.SliceStable(data, func(i, j int) bool { return less(data[i], data[j]) })
+	return data
+}
@@ -0,0 +1,212 @@
+package streams
+
+// SortedSet a set of elements kept in ascending order (according to the given less function), two elements are considered equal if neither is less
+// than the other. The set is backed by a balanced (AVL) tree and is not safe for concurrent use.
+type SortedSet[T any] struct {
+	root *treeNode[T]
+	less func(x, y T) bool
+	size int
+}
+
+// treeNode a node of the tree backing a sorted set.
+type treeNode[T any] struct {
+	value       T
+	left, right *treeNode[T]
+	height      int
+}
+
+// newSortedSet creates a sorted set containing the given elements.
+func newSortedSet[T any](data []T, less func(x, y T) bool) *SortedSet[T] {
+	set := &SortedSet[T]{less: less}
+	for _, x := range data {
+		set.Add(x)
+	}
+	return set
+}
+
+// Len returns the number of elements in the set.
+func (s *SortedSet[T]) Len() int {
+	return s.size
+}
+
+// Add adds the given element to the set, false is returned if an equal element is already in the set.
+func (s *SortedSet[T]) Add(x T) bool {
+	var added bool
+	s.root = s.insert(s.root, x, &added)
+	if added {
+		s.size++
+	}
+	return added
+}
+
+// Remove removes the given element from the set, false is returned if the set does not contain it.
+func (s *SortedSet[T]) Remove(x T) bool {
+	var removed bool
+	s.root = s.delete(s.root, x, &removed)
+	if removed {
+		s.size--
+	}
+	return removed
+}
+
+// Contains checks if the set contains the given element.
+func (s *SortedSet[T]) Contains(x T) bool {
+	node := s.root
+	for node != nil {
+		if s.less(x, node.value) {
+			node = node.left
+		} else if s.less(node.value, x) {
+			node = node.right
+		} else {
+			return true
+		}
+	}
+	return false
+}
+
+// Min returns the least element of the set, false is returned if the set is empty.
+func (s *SortedSet[T]) Min() (T, bool) {
+	if s.root == nil {
+		var zero T
+		return zero, false
+	}
+	return treeMinNode(s.root).value, true
+}
+
+// Max returns the greatest element of the set, false is returned if the set is empty.
+func (s *SortedSet[T]) Max() (T, bool) {
+	if s.root == nil {
+		var zero T
+		return zero, false
+	}
+	node := s.root
+	for node.right != nil {
+		node = node.right
+	}
+	return node.value, true
+}
+
+// Ascend performs the given action on the elements of the set in ascending order until it returns false.
+func (s *SortedSet[T]) Ascend(f func(x T) bool) {
+	treeAscend(s.root, f)
+}
+
+// Slice returns the elements of the set in ascending order.
+func (s *SortedSet[T]) Slice() []T {
+	results := make([]T, 0, s.size)
+	s.Ascend(func(x T) bool {
+		results = append(results, x)
+		return true
+	})
+	return results
+}
+
+// treeAscend visits the nodes of the tree rooted at node in order, returns false if f stopped the traversal.
+func treeAscend[T any](node *treeNode[T], f func(x T) bool) bool {
+	if node == nil {
+		return true
+	}
+	return treeAscend(node.left, f) && f(node.value) && treeAscend(node.right, f)
+}
+
+// insert inserts x into the tree rooted at node and returns the new root of the tree.
+func (s *SortedSet[T]) insert(node *treeNode[T], x T, added *bool) *treeNode[T] {
+	if node == nil {
+		*added = true
+		return &treeNode[T]{value: x, height: 1}
+	}
+	if s.less(x, node.value) {
+		node.left = s.insert(node.left, x, added)
+	} else if s.less(node.value, x) {
+		node.right = s.insert(node.right, x, added)
+	} else {
+		return node
+	}
+	return treeBalance(node)
+}
+
+// delete removes x from the tree rooted at node and returns the new root of the tree.
+func (s *SortedSet[T]) delete(node *treeNode[T], x T, removed *bool) *treeNode[T] {
+	if node == nil {
+		return nil
+	}
+	if s.less(x, node.value) {
+		node.left = s.delete(node.left, x, removed)
+	} else if s.less(node.value, x) {
+		node.right = s.delete(node.right, x, removed)
+	} else {
+		*removed = true
+		if node.left == nil {
+			return node.right
+		} else if node.right == nil {
+			return node.left
+		}
+		successor := treeMinNode(node.right)
+		node.value = successor.value
+		var ignored bool
+		node.right = s.delete(node.right, successor.value, &ignored)
+	}
+	return treeBalance(node)
+}
+
+// treeMinNode returns the left most node of the tree rooted at node.
+func treeMinNode[T any](node *treeNode[T]) *treeNode[T] {
+	for node.left != nil {
+		node = node.left
+	}
+	return node
+}
+
+// treeHeight returns the height of the tree rooted at node.
+func treeHeight[T any](node *treeNode[T]) int {
+	if node == nil {
+		return 0
+	}
+	return node.height
+}
+
+// treeUpdate recomputes the height of the given node from its children.
+func treeUpdate[T any](node *treeNode[T]) {
+	node.height = 1 + treeHeight(node.left)
+	if h := treeHeight(node.right); h >= node.height {
+		node.height = 1 + h
+	}
+}
+
+// treeRotateLeft rotates the tree rooted at node to the left and returns the new root.
+func treeRotateLeft[T any](node *treeNode[T]) *treeNode[T] {
+	root := node.right
+	node.right = root.left
+	root.left = node
+	treeUpdate(node)
+	treeUpdate(root)
+	return root
+}
+
+// treeRotateRight rotates the tree rooted at node to the right and returns the new root.
+func treeRotateRight[T any](node *treeNode[T]) *treeNode[T] {
+	root := node.left
+	node.left = root.right
+	root.right = node
+	treeUpdate(node)
+	treeUpdate(root)
+	return root
+}
+
+// treeBalance restores the balance of the tree rooted at node after an insertion or deletion and returns the new root.
+func treeBalance[T any](node *treeNode[T]) *treeNode[T] {
+	treeUpdate(node)
+	factor := treeHeight(node.left) - treeHeight(node.right)
+	if factor > 1 {
+		if treeHeight(node.left.left) < treeHeight(node.left.right) {
+			node.left = treeRotateLeft(node.left)
+		}
+		return treeRotateRight(node)
+	} else if factor < -1 {
+		if treeHeight(node.right.right) < treeHeight(node.right.left) {
+			node.right = treeRotateRight(node.right)
+		}
+		return treeRotateLeft(node)
+	}
+	return node
+}
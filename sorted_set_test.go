@@ -0,0 +1,84 @@
+package streams
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSortedSet(t *testing.T) {
+
+	supplier := func() []string { return []string{"pear", "apple", "fig", "apple", "kiwi", "fig"} }
+	less := func(x, y string) bool { return x < y }
+
+	type toSortedSetTest struct {
+		s        Stream[string]
+		expected []string
+	}
+
+	toSortedSetTests := []toSortedSetTest{
+		{s: New(supplier), expected: []string{"apple", "fig", "kiwi", "pear"}},
+		{s: New(supplier).Parallelize(2), expected: []string{"apple", "fig", "kiwi", "pear"}},
+		{s: New(supplier).Limit(3), expected: []string{"apple", "fig", "pear"}},
+		{s: New(func() []string { return []string{} }), expected: []string{}},
+	}
+
+	for _, test := range toSortedSetTests {
+		set := test.s.ToSortedSet(less)
+		assert.Equal(t, len(test.expected), set.Len())
+		assert.Equal(t, test.expected, set.Slice())
+	}
+
+	// Elements are equal if neither is less than the other.
+	set := New(supplier).ToSortedSet(func(x, y string) bool { return len(x) < len(y) })
+	assert.Equal(t, []string{"fig", "pear", "apple"}, set.Slice())
+	assert.True(t, set.Contains("kiwi"))
+	assert.Panics(t, func() { New(supplier).ToSortedSet(nil) })
+
+}
+
+func TestSortedSet(t *testing.T) {
+
+	set := newSortedSet([]int{}, func(x, y int) bool { return x < y })
+	_, ok := set.Min()
+	assert.False(t, ok)
+	_, ok = set.Max()
+	assert.False(t, ok)
+
+	random := rand.New(rand.NewSource(1))
+	expected := make(map[int]bool)
+	for i := 0; i < 2000; i++ {
+		x := random.Intn(500)
+		if random.Intn(3) == 0 {
+			assert.Equal(t, expected[x], set.Remove(x))
+			delete(expected, x)
+		} else {
+			assert.Equal(t, !expected[x], set.Add(x))
+			expected[x] = true
+		}
+	}
+
+	keys := make([]int, 0, len(expected))
+	for x := range expected {
+		keys = append(keys, x)
+	}
+	sort.Ints(keys)
+	assert.Equal(t, len(keys), set.Len())
+	assert.Equal(t, keys, set.Slice())
+	assert.LessOrEqual(t, set.root.height, 2*11)
+
+	min, _ := set.Min()
+	max, _ := set.Max()
+	assert.Equal(t, keys[0], min)
+	assert.Equal(t, keys[len(keys)-1], max)
+
+	visited := 0
+	set.Ascend(func(x int) bool {
+		visited++
+		return visited < 3
+	})
+	assert.Equal(t, 3, visited)
+
+}
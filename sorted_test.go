@@ -0,0 +1,160 @@
+package streams
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSorted(t *testing.T) {
+
+	supplier := func() []int { return []int{5, 3, 8, 3, 1, 9, 5, 2} }
+	less := func(x, y int) bool { return x < y }
+	hash := func(x int) string { return fmt.Sprint(x) }
+
+	type sortedTest struct {
+		s        Stream[int]
+		fused    bool
+		parallel bool
+		expected []int
+	}
+
+	sortedTests := []sortedTest{
+		{s: New(supplier).Sorted(less), expected: []int{1, 2, 3, 3, 5, 5, 8, 9}},
+		{s: New(supplier).Parallelize(2).Sorted(less), parallel: true, expected: []int{1, 2, 3, 3, 5, 5, 8, 9}},
+		{s: New(supplier).Filter(func(x int) bool { return x > 2 }).Sorted(less).Limit(3), expected: []int{3, 3, 5}},
+		{s: New(supplier).Distinct(hash).Sorted(less), fused: true, expected: []int{1, 2, 3, 5, 8, 9}},
+		{s: New(supplier).Sorted(less).Distinct(hash), fused: true, expected: []int{1, 2, 3, 5, 8, 9}},
+		{s: New(supplier).Parallelize(3).Distinct(hash).Sorted(less), fused: true, parallel: true, expected: []int{1, 2, 3, 5, 8, 9}},
+		{s: New(supplier).Parallelize(3).Sorted(less).Distinct(hash), fused: true, parallel: true, expected: []int{1, 2, 3, 5, 8, 9}},
+		{s: New(supplier).Distinct(hash).Map(func(x int) int { return x * 2 }).Sorted(less), expected: []int{2, 4, 6, 10, 16, 18}},
+		{s: New(supplier).Sorted(less).Map(func(x int) int { return -x }).Distinct(hash), expected: []int{-1, -2, -3, -5, -8, -9}},
+	}
+
+	for _, test := range sortedTests {
+		s := test.s.(*stream[int])
+		assert.Equal(t, test.fused, len(s.operations) == 0 && s.sorting == nil)
+		assert.Equal(t, test.parallel, s.Parallel())
+		assert.Equal(t, test.expected, s.Collect())
+	}
+
+	// Elements that are equal according to less keep their encounter order.
+	type pair struct {
+		key, value int
+	}
+	pairs := []pair{{2, 1}, {1, 2}, {2, 3}, {1, 4}, {2, 1}}
+	byKey := func(x, y pair) bool { return x.key < y.key }
+	pairHash := func(x pair) string { return fmt.Sprint(x) }
+	assert.Equal(t, []pair{{1, 2}, {1, 4}, {2, 1}, {2, 3}, {2, 1}}, New(func() []pair { return pairs }).Sorted(byKey).Collect())
+	assert.Equal(t, []pair{{1, 2}, {1, 4}, {2, 1}, {2, 3}}, New(func() []pair { return pairs }).Distinct(pairHash).Sorted(byKey).Collect())
+	assert.Equal(t, []pair{{1, 2}, {1, 4}, {2, 1}, {2, 3}}, New(func() []pair { return pairs }).Sorted(byKey).Distinct(pairHash).Collect())
+
+	// Elements with the same hash need not be equal according to less.
+	type event struct {
+		id string
+		ts int
+	}
+	events := func() []event { return []event{{"a", 3}, {"b", 1}, {"a", 1}, {"c", 2}} }
+	byID := func(x event) string { return x.id }
+	byTs := func(x, y event) bool { return x.ts < y.ts }
+	assert.Equal(t, []event{{"b", 1}, {"c", 2}, {"a", 3}}, New(events).Distinct(byID).Sorted(byTs).Collect())
+	assert.Equal(t, []event{{"b", 1}, {"a", 1}, {"c", 2}}, New(events).Sorted(byTs).Distinct(byID).Collect())
+	assert.Equal(t, []event{{"b", 1}, {"c", 2}, {"a", 3}}, New(events).Parallelize(2).Distinct(byID).Sorted(byTs).Collect())
+	assert.Equal(t, []event{{"b", 1}, {"a", 1}, {"c", 2}}, New(events).Parallelize(2).Sorted(byTs).Distinct(byID).Collect())
+
+	assert.Panics(t, func() { New(supplier).Sorted(nil) })
+
+}
+
+func TestStableSort(t *testing.T) {
+
+	type pair struct {
+		key, value int
+	}
+	less := func(x, y pair) bool { return x.key < y.key }
+
+	random := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 15, 16, 17, 100, 1000, 4097} {
+		data := make([]pair, n)
+		for i := range data {
+			data[i] = pair{key: random.Intn(50), value: i}
+		}
+		expected := append([]pair{}, data...)
+		sort.SliceStable(expected, func(i, j int) bool { return less(expected[i], expected[j]) })
+		assert.Equal(t, expected, stableSort(data, less))
+	}
+
+}
+
+// benchmarkDistinctSorted benchmarks the given pipeline on data with many duplicates and on data that is mostly unique.
+func benchmarkDistinctSorted(b *testing.B, pipeline func(s Stream[int]) Stream[int]) {
+	for _, values := range []int{10000, 1000000000} {
+		random := rand.New(rand.NewSource(1))
+		data := make([]int, 100000)
+		for i := range data {
+			data[i] = random.Intn(values)
+		}
+		b.Run(fmt.Sprintf("values=%d", values), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pipeline(New(func() []int { return append([]int{}, data...) })).Count()
+			}
+		})
+	}
+}
+
+func BenchmarkDistinctSortedFused(b *testing.B) {
+	less := func(x, y int) bool { return x < y }
+	hash := func(x int) string { return fmt.Sprint(x) }
+	benchmarkDistinctSorted(b, func(s Stream[int]) Stream[int] { return s.Distinct(hash).Sorted(less) })
+}
+
+func BenchmarkDistinctSortedNaive(b *testing.B) {
+	less := func(x, y int) bool { return x < y }
+	hash := func(x int) string { return fmt.Sprint(x) }
+	// Map between the two operations prevents them from being fused.
+	benchmarkDistinctSorted(b, func(s Stream[int]) Stream[int] {
+		return s.Distinct(hash).Map(func(x int) int { return x }).Sorted(less)
+	})
+}
+
+func TestPrioritize(t *testing.T) {
+
+	supplier := func() []int { return []int{3, 9, 1, 7, 5, 8, 2} }
+	priority := func(x int) int { return x }
+
+	type prioritizeTest struct {
+		s        Stream[int]
+		expected []int
+	}
+
+	prioritizeTests := []prioritizeTest{
+		{s: New(supplier).Prioritize(priority), expected: []int{9, 8, 7, 5, 3, 2, 1}},
+		{s: New(supplier).Prioritize(priority).Limit(3), expected: []int{9, 8, 7}},
+		{s: New(supplier).Filter(func(x int) bool { return x%2 == 1 }).Prioritize(func(x int) int { return -x }), expected: []int{1, 3, 5, 7, 9}},
+		{s: New(supplier).Prioritize(func(x int) int { return x % 2 }), expected: []int{3, 9, 1, 7, 5, 8, 2}},
+	}
+
+	for _, test := range prioritizeTests {
+		assert.Equal(t, test.expected, test.s.Collect())
+	}
+
+	// Routines take elements in priority order, a single routine processes them strictly in that order.
+	var mux sync.Mutex
+	processed := make([]int, 0)
+	s := New(supplier).ParallelizeWith(IOBound(1)).Prioritize(priority)
+	assert.Equal(t, ExecutionInfo{Parallel: true, Workers: 1, PerElement: true}, s.ExecutionInfo())
+	s.ForEach(func(x int) {
+		mux.Lock()
+		defer mux.Unlock()
+		processed = append(processed, x)
+	})
+	assert.Equal(t, []int{9, 8, 7, 5, 3, 2, 1}, processed)
+
+	assert.ElementsMatch(t, []int{9, 8, 7, 5, 3, 2, 1}, New(supplier).Parallelize(3).Prioritize(priority).Collect())
+	assert.Panics(t, func() { New(supplier).Prioritize(nil) })
+
+}
@@ -0,0 +1,23 @@
+package streams
+
+// Stoppable a lazily backed source of a stream that can be told to stop fetching elements, i.e a pager prefetching the next pages of a listing or
+// the producer of a channel. Stop is called once the terminal operation of the stream stops before consuming all elements of the source, which
+// happens when an operation such as Limit or LimitUntil lets no more elements through, ForEachWhile stops or a Cursor is closed early.
+type Stoppable interface {
+	Stop() // Stops fetching elements, it is called at most once.
+}
+
+// NewStoppable creates a new stream with the given supplier for elements whose source is told to stop once the terminal operation of the stream, or
+// of the stream derived from it, stops early. Stop is not called if all elements of the source are consumed.
+func NewStoppable[T any](supplier func() []T, source Stoppable) Stream[T] {
+	s := New(supplier).(*stream[T])
+	s.origin = stoppableSourceName
+	if source != nil {
+		s.release = func(early bool) {
+			if early {
+				source.Stop()
+			}
+		}
+	}
+	return s
+}
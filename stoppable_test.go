@@ -0,0 +1,60 @@
+package streams
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pager a stoppable source that counts the calls to Stop.
+type pager struct {
+	stops int32
+}
+
+func (p *pager) Stop() {
+	atomic.AddInt32(&p.stops, 1)
+}
+
+func TestNewStoppable(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} }
+
+	type stoppableTest struct {
+		name     string
+		evaluate func(s Stream[int])
+		stopped  bool
+	}
+
+	stoppableTests := []stoppableTest{
+		{name: "Collect", evaluate: func(s Stream[int]) { s.Collect() }, stopped: false},
+		{name: "Limit", evaluate: func(s Stream[int]) { s.Limit(3).Collect() }, stopped: true},
+		{name: "ParallelLimit", evaluate: func(s Stream[int]) { s.Parallelize(2).Limit(3).Count() }, stopped: true},
+		{name: "LimitNotReached", evaluate: func(s Stream[int]) { s.Limit(20).Count() }, stopped: false},
+		{name: "LimitUntil", evaluate: func(s Stream[int]) { s.LimitUntil(func(x int) bool { return x > 4 }).Collect() }, stopped: true},
+		{name: "LimitDuration", evaluate: func(s Stream[int]) { s.LimitDuration(0).Collect() }, stopped: true},
+		{name: "LimitDurationNotReached", evaluate: func(s Stream[int]) { s.LimitDuration(time.Hour).Count() }, stopped: false},
+		{name: "ForEachWhile", evaluate: func(s Stream[int]) { s.ForEachWhile(func(x int) bool { return x < 5 }) }, stopped: true},
+		{name: "ForEachWhileAll", evaluate: func(s Stream[int]) { s.ForEachWhile(func(x int) bool { return true }) }, stopped: false},
+		{name: "CursorClosed", evaluate: func(s Stream[int]) { c := s.Open(); c.Next(); c.Close() }, stopped: true},
+		{name: "CursorDrained", evaluate: func(s Stream[int]) {
+			c := s.Open()
+			for _, ok := c.Next(); ok; _, ok = c.Next() {
+			}
+		}, stopped: false},
+	}
+
+	for _, test := range stoppableTests {
+		p := &pager{}
+		test.evaluate(NewStoppable(supplier, p))
+		expected := int32(0)
+		if test.stopped {
+			expected = 1
+		}
+		assert.Equal(t, expected, atomic.LoadInt32(&p.stops), test.name)
+	}
+
+	assert.Equal(t, 10, NewStoppable(supplier, nil).Limit(20).Count())
+
+}
@@ -1,85 +1,310 @@
 package streams
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressInterval the minimum amount of time between two renders of a progress bar, see WithProgressBar.
+var ProgressInterval = 100 * time.Millisecond
 
 // Stream a sequence of elements that can be operated on sequentially or in parallel. The underlying source for a stream should be finite, infinite sources
 // are not supported and will lead to an infinite loop.
 type Stream[T any] interface {
-	Filter(f func(x T) bool) Stream[T]        // Returns a stream consisting of the elements of this stream that satisfy the given predicate.
-	Map(f func(x T) T) Stream[T]              // Returns a stream consisting of the results of applying the given transformation to the elements of the stream.
-	Limit(n int) Stream[T]                    // Returns a stream consisting of the elements of this stream, truncated to be no longer than given length.
-	Skip(n int) Stream[T]                     // Returns a stream consisting of the remaining elements of this stream after discarding the first n elements of the stream.
-	Distinct(hash func(x T) string) Stream[T] // Returns a stream consisting of the distinct elements (according to the given hash of elements) of this stream.
-	Peek(f func(x T)) Stream[T]               // Returns a stream consisting of the elements of this stream.
+	Filter(f func(x T) bool) Stream[T]             // Returns a stream consisting of the elements of this stream that satisfy the given predicate.
+	TryFilter(f func(x T) (bool, error)) Stream[T] // Returns a stream consisting of the elements that satisfy the predicate, stopping at the first error of the predicate.
+	FilterN(n int, f func(x T) bool) Stream[T]     // Returns a stream consisting of the elements that satisfy the given predicate, evaluated by at most n routines at a time.
+	Map(f func(x T) T) Stream[T]                   // Returns a stream consisting of the results of applying the given transformation to the elements of the stream.
+	MapMulti(f func(x T, emit func(T))) Stream[T]  // Returns a stream consisting of the elements emitted by f for each element of the stream.
+	TryMap(f func(x T) (T, error)) Stream[T]       // Returns a stream consisting of the results of the function, stopping at the first error of the function.
+	MapN(n int, f func(x T) T) Stream[T]           // Returns a stream consisting of the results of applying the given transformation, by at most n routines at a time.
+	Limit(n int) Stream[T]                         // Returns a stream consisting of the elements of this stream, truncated to be no longer than given length.
+	LimitUntil(f func(x T) bool) Stream[T]         // Returns a stream consisting of the elements of this stream up to the first element that satisfies the given predicate.
+	LimitDuration(d time.Duration) Stream[T]       // Returns a stream consisting of the elements of this stream evaluated within the given duration.
+	Skip(n int) Stream[T]                          // Returns a stream consisting of the remaining elements of this stream after discarding the first n elements of the stream.
+	SubStream(from, to int) Stream[T]              // Returns a stream restricted to the elements of the source at indexes in [from, to).
+	WithOffset(start int) Stream[T]                // Returns a stream whose source starts at the given offset, i.e to resume a job that failed.
+	StrideStream(step int) Stream[T]               // Returns a stream restricted to every step-th element of the source, starting with the first.
+	Distinct(hash func(x T) string) Stream[T]      // Returns a stream consisting of the distinct elements (according to the given hash of elements) of this stream.
+	Sorted(less func(x, y T) bool) Stream[T]       // Returns a stream consisting of the elements of this stream sorted according to the given less function.
+	Prioritize(priority func(x T) int) Stream[T]   // Returns a stream consisting of the elements of this stream ordered, and processed, by descending priority.
+	Peek(f func(x T)) Stream[T]                    // Returns a stream consisting of the elements of this stream.
 	// additionally the provided action on each element as elements are consumed.	// Terminal operations.
-	GroupBy(f func(x T) string) GroupedStream[T]    // Returns a grouped stream in which elements are assigned a group using the given group key function.
-	Partition(f func(x T) []T) PartitionedStream[T] // Returns a partitioned streamed whose elements are the results of splitting each member of this stream using the given function.
+	Progress(f func(done int)) Stream[T]                                 // Returns a stream consisting of the elements of this stream, reporting the number of elements consumed so far.
+	WithProgressBar(total int, render func(done, total int)) Stream[T]   // Returns a stream consisting of the elements of this stream, rendering progress towards the given total.
+	WithCapture(max int) Stream[T]                                       // Returns a stream that records up to max source elements causing panics and reports them once evaluated.
+	WithHasher(h Hasher[string]) Stream[T]                               // Returns a stream whose hash based operations (Distinct, GroupBy) hash keys using the given hasher.
+	WithScheduler(scheduler Scheduler) Stream[T]                         // Returns a stream whose parallel evaluation notifies the given scheduler at its scheduling points, meant for tests.
+	FailFast() Stream[T]                                                 // Returns a stream whose ForEachErr stops at the first error.
+	Ordered() Stream[T]                                                  // Returns a stream whose parallel Collect and Reduce keep the order of the source elements.
+	GroupBy(f func(x T) string) GroupedStream[T]                         // Returns a grouped stream in which elements are assigned a group using the given group key function.
+	GroupBySorted(f func(x T) string) GroupedStream[T]                   // Returns a grouped stream of a stream whose elements are ordered by the group key, each run of equal keys forms a group.
+	Partition(f func(x T) []T) PartitionedStream[T]                      // Returns a partitioned streamed whose elements are the results of splitting each member of this stream using the given function.
+	Window(size int) PartitionedStream[T]                                // Returns a partitioned stream whose elements are the consecutive elements of this stream batched into slices of the given size.
+	SlidingWindow(size, step int) PartitionedStream[T]                   // Returns a partitioned stream whose elements are the windows of the given size of consecutive elements of this stream, starting every step elements.
+	Validate(rules ...func(x T) error) (Stream[T], []ValidationError[T]) // Returns a stream of the elements that satisfy all the given rules along with the violations of those that do not.
 
-	ForEach(f func(x T))       // Performs an action specified by the function f for each element of the stream.
-	Count() int                // Returns a count of elements in the stream.
-	Reduce(f func(x, y T) T) T // Returns result of performing reduction on the elements of the stream, using ssociative accumulation function, and returns the reduced value.
+	ForEach(f func(x T))                                                   // Performs an action specified by the function f for each element of the stream.
+	ForEachWhile(f func(x T) bool)                                         // Performs an action specified by the function f for each element of the stream until f returns false.
+	ForEachErr(f func(x T) error) error                                    // Performs an action specified by the function f for each element of the stream, returning the errors of f joined into one.
+	AnyMatch(f func(x T) bool) bool                                        // Returns whether any element of the stream satisfies the predicate, stopping at the first that does.
+	AllMatch(f func(x T) bool) bool                                        // Returns whether all elements of the stream satisfy the predicate, stopping at the first that does not.
+	NoneMatch(f func(x T) bool) bool                                       // Returns whether no element of the stream satisfies the predicate, stopping at the first that does.
+	ForEachBatchBytes(maxBytes int, size func(x T) int, f func(batch []T)) // Performs an action for batches of elements whose total size does not exceed maxBytes.
+	DrainTo(sink Sink[T], batchSize int, opts ...SinkOption) error         // Writes the elements of the stream to the sink in batches of the given size and commits them.
+	ToFile(path string, encode func(x T) []byte, opts ...FileOption) error // Writes the encoded elements of the stream to the file at the given path, the file is only replaced if all elements were written.
+	Count() int                                                            // Returns a count of elements in the stream.
+	Reduce(f func(x, y T) T) T                                             // Returns result of performing reduction on the elements of the stream, using ssociative accumulation function, and returns the reduced value.
 	// The zero value is returned if there are no elements.
+	Min(less func(x, y T) bool) (T, bool) // Returns the least element of the stream according to less, false is returned if there are no elements.
+	Max(less func(x, y T) bool) (T, bool) // Returns the greatest element of the stream according to less, false is returned if there are no elements.
 
-	Collect() []T              // Returns a slice containing the elements from the stream.
-	Parallel() bool            // Returns an indication of whether the stream is parallel.
-	Parallelize(int) Stream[T] // Returns a parallel stream with the given level of parallelism.
+	Collect() []T                                                     // Returns a slice containing the elements from the stream.
+	ToChannel(ctx context.Context) <-chan T                           // Returns a channel receiving the elements of the stream as they are produced, closed once all have been sent or ctx is done.
+	sequenced[T]                                                      // Seq returns a sequence for iterating the stream with a range loop, from Go 1.23.
+	CollectErr() ([]T, error)                                         // Returns a slice containing the elements from the stream, or the first error of an operation such as TryMap.
+	CollectWithin(d time.Duration) ([]T, bool)                        // Returns the elements produced by the stream within the given duration along with an indication of whether the evaluation completed.
+	CollectLimited(max int) ([]T, error)                              // Returns a slice containing the elements from the stream, or an error if there are more than max elements.
+	ToHeap(less func(x, y T) bool) *Heap[T]                           // Returns a heap containing the elements from the stream, ordered by the given less function.
+	ToSortedSet(less func(x, y T) bool) *SortedSet[T]                 // Returns a sorted set containing the distinct elements from the stream, ordered by the given less function.
+	ToSetFunc(hash func(x T) string) map[string]T                     // Returns a map from hash to element containing the distinct elements (according to the given hash) from the stream.
+	ToSyncMap(key func(x T) any, value func(x T) any) *sync.Map       // Returns a sync.Map containing the keys and values computed for the elements from the stream.
+	Bucketize(n int) [][]T                                            // Returns the elements from the stream split into n contiguous buckets whose sizes differ by at most one.
+	BucketizeRoundRobin(n int) [][]T                                  // Returns the elements from the stream dealt round-robin into n buckets.
+	Open() Cursor[T]                                                  // Returns a cursor which evaluates the stream one resulting element at a time.
+	Parallel() bool                                                   // Returns an indication of whether the stream is parallel.
+	ExecutionInfo() ExecutionInfo                                     // Returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+	Parallelize(int) Stream[T]                                        // Returns a parallel stream with the given level of parallelism, replacing that of a parallel stream.
+	ParallelizeWith(p Profile) Stream[T]                              // Returns a parallel stream using the given execution profile.
+	ParallelizeAuto() Stream[T]                                       // Returns a stream which decides whether to run in parallel, and with how many routines, when evaluated.
+	Sequential() Stream[T]                                            // Returns a sequential stream, i.e to switch a parallel stream back to a single routine.
+	Repartition(n int) Stream[T]                                      // Returns a stream whose later operations are evaluated by n routines over evenly sized partitions of the elements evaluated so far.
+	WithCostEstimator(cost func(x T) int) Stream[T]                   // Returns a stream whose parallel evaluation gives routines chunks of elements of roughly equal total cost.
+	Barrier() Stream[T]                                               // Returns a stream whose later operations run only once the operations of this stream have been applied to all elements.
+	FilterOutliers(value func(x T) float64, zScore float64) Stream[T] // Returns a stream of the elements whose value is within zScore standard deviations of the mean value.
+	AppendLazy(next func() Stream[T]) Stream[T]                       // Returns a stream of the elements of this stream followed by those of the stream returned by next, invoked on evaluation.
 
+	DryRun() error    // Checks that the stream can be evaluated without pulling any data from its source.
+	Lint() []Warning  // Returns warnings for operations of the stream that are redundant or discard every element, without evaluating it.
 	Terminated() bool // Checks if a terminal operation has been invoked on the stream.
 	Closed() bool     // Checks if a stream has been closed. A stream is closed either when a new stream is created from it using intermediate
 	// operations, terminated streams are also closed.
+	Summary() Summary   // Returns statistics of the evaluation of the stream by its terminal operation.
+	ConsumedCount() int // Returns the offset of the source of the stream plus the number of source elements consumed by its terminal operation.
+	ID() uint64         // Returns the identifier of the stream, unique amongst the streams of the process.
+	String() string     // Returns a description of the stream, i.e its identifier, source, operations and parallel settings.
 
 }
 
 // stream underlying concrete type, keeps track of operations.
 type stream[T any] struct {
-	supplier    func() []T
-	operations  []operator[T]
-	parallel    bool
-	maxRoutines int
-	distinct    bool
-	terminated  bool
-	closed      bool
+	supplier   func() []T
+	operations []operator[T]
+	parallel   bool
+	executor   executor
+	distinct   bool
+	auto       bool
+	capture    int
+	release    func(early bool) // Invoked once the stream has been evaluated, early indicates the terminal operation stopped before consuming the source.
+	interrupt  func()           // Stops pulling the source from another routine, i.e once CollectWithin times out, nil if the source can not be stopped.
+	hasher     Hasher[string]
+	sorting    *sorting[T]         // Source of a sorted stream that has no operations, nil if the stream is not sorted.
+	upstream   func() *streamError // Checks the plan of the operations evaluated before the source of the stream (i.e before a sort), nil if there are none.
+	failFast   bool                // Indicates whether ForEachErr stops at the first error.
+	ordered    bool                // Indicates whether the elements of a parallel stream are collected in the order of the source elements they result from.
+	offset     int                 // Number of source elements skipped by WithOffset.
+	origin     string              // Kind of source of the stream, i.e SUPPLIER or CHANNEL.
+	id         uint64              // Identifier of the stream, assigned when first requested.
+	appended   *appended[T]        // Source of a stream created by AppendLazy, nil otherwise.
+	stats      *statistics
+	early      int32 // Set by a terminal operation that stopped before consuming all elements of the source.
+	terminated int32
+	closed     int32
 }
 
-// New creates a new stream with the given supplier for elements.
+// New creates a new stream with the given supplier for elements. The supplier is invoked exactly once by the terminal operation of the stream, or of
+// the stream derived from it, and is never invoked by DryRun. A nil supplier creates an empty stream.
 func New[T any](supplier func() []T) Stream[T] {
+	if supplier == nil {
+		supplier = func() []T { return []T{} }
+	}
 	return &stream[T]{
-		supplier:   supplier,
+		supplier:   countCalls(supplier),
 		operations: make([]operator[T], 0),
+		origin:     supplierSourceName,
 	}
 }
 
+// appendOperation returns a new slice consisting of the given operations followed by the given operation, the given slice is left unmodified so
+// that streams never share a backing array of operations.
+func appendOperation[T any](operations []operator[T], operation operator[T]) []operator[T] {
+	result := make([]operator[T], len(operations), len(operations)+1)
+	copy(result, operations)
+	return append(result, operation)
+}
+
 // new creates a new stream which adds the given operation.
 func new[T any](s *stream[T], operator operator[T]) *stream[T] {
-	defer s.close()
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.sorting = nil
+	if s.appended != nil && len(s.operations) == 0 {
+		result.appended = s.appended.with(operator)
+		result.supplier = result.appended.supply
+		return result
+	}
+	result.operations = appendOperation(s.operations, operator)
+	return result
+}
+
+// clone returns a stream with the source, operations and settings of this stream, streams derived from it then change what they need to. The state
+// of the evaluation of this stream (i.e whether it has been closed) is not copied.
+func (s *stream[T]) clone() *stream[T] {
 	return &stream[T]{
-		supplier:    s.supplier,
-		operations:  append(s.operations, operator),
-		parallel:    s.parallel,
-		distinct:    s.distinct,
-		maxRoutines: s.maxRoutines,
+		supplier:   s.supplier,
+		operations: s.operations,
+		parallel:   s.parallel,
+		executor:   s.executor,
+		distinct:   s.distinct,
+		auto:       s.auto,
+		capture:    s.capture,
+		release:    s.release,
+		interrupt:  s.interrupt,
+		hasher:     s.hasher,
+		sorting:    s.sorting,
+		upstream:   s.upstream,
+		failFast:   s.failFast,
+		ordered:    s.ordered,
+		offset:     s.offset,
+		origin:     s.origin,
+		appended:   s.appended,
+	}
+}
+
+// sourced returns a stream with the settings of this stream whose source is given by supplier, i.e the elements resulting from the operations of
+// this stream, so it has no operations of its own.
+func (s *stream[T]) sourced(supplier func() []T) *stream[T] {
+	result := s.clone()
+	result.supplier, result.operations, result.sorting, result.appended = supplier, make([]operator[T], 0), nil, nil
+	return result
+}
+
+// evaluation returns the operations to apply when evaluating the stream along with a function that must be invoked once evaluation is done. If the
+// stream captures elements that cause panics, the operations are wrapped to record such elements and the function raises the captured panics.
+func (s *stream[T]) evaluation() ([]operator[T], func()) {
+	release, operations := s.release, s.operations
+	for i := range operations {
+		if operations[i].start != nil {
+			operations[i].start()
+		}
+	}
+	if release == nil {
+		release = func(bool) {}
+	}
+	finish := func() { release(s.stoppedEarly(operations)) }
+	if stats := s.stats; stats != nil {
+		operations = wrapStatistics(stats, operations)
+		next := finish
+		finish = func() {
+			stats.finish()
+			next()
+		}
+	}
+	if s.capture == 0 {
+		return operations, finish
+	}
+	c := &capture[T]{max: s.capture}
+	return c.wrap(operations), func() {
+		defer finish()
+		c.raise()
+	}
+}
+
+// stoppedEarly returns an indication of whether the terminal operation of the stream stopped before consuming all elements of the source, either by
+// itself (i.e ForEachWhile) or because one of the given operations was exhausted (i.e Limit).
+func (s *stream[T]) stoppedEarly(operations []operator[T]) bool {
+	if atomic.LoadInt32(&s.early) == 1 {
+		return true
+	} else if s.appended != nil && s.appended.exhausted() {
+		return true
+	}
+	return exhausted(operations)
+}
+
+// supply returns the elements of the source of the stream, recording their number for the summary of a terminated stream.
+func (s *stream[T]) supply() []T {
+	data := s.supplier()
+	if s.stats != nil {
+		s.stats.buffered = len(data)
+	}
+	return data
+}
+
+// source returns the supplier of the stream for deriving a stream of another kind, such streams do not keep track of the release of the stream so
+// it happens as soon as the elements have been supplied.
+func (s *stream[T]) source() func() []T {
+	if s.release == nil {
+		return s.supplier
+	}
+	return func() []T {
+		defer s.release(false)
+		return s.supplier()
 	}
 }
 
 // Closed returns an indication of whether the stream has been closed or not.
 func (s *stream[T]) Closed() bool {
-	return s.closed
+	return atomic.LoadInt32(&s.closed) == 1
 }
 
-// close closes the stream.
-func (s *stream[T]) close() {
-	s.closed = true
+// close closes the stream, an error is returned if the stream has already been closed. Closing is atomic so that only one of many routines
+// deriving streams from the same stream concurrently succeeds.
+func (s *stream[T]) close() *streamError {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		_, err := s.valid()
+		return err
+	}
+	return nil
 }
 
 // Terminated returns an indication of whether the stream has been closed by invoking a terminal operation.
 func (s *stream[T]) Terminated() bool {
-	return s.terminated
+	return atomic.LoadInt32(&s.terminated) == 1
+}
+
+// terminate terminates the stream, an error is returned if the stream has already been closed.
+func (s *stream[T]) terminate() *streamError {
+	if err := s.close(); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&s.terminated, 1)
+	workers := 1
+	if s.parallel {
+		workers = s.executor.maxRoutines
+	}
+	s.stats = &statistics{start: time.Now(), workers: workers}
+	return nil
+}
+
+// Summary returns statistics of the evaluation of the stream by its terminal operation, the zero value is returned if the stream has not been
+// terminated. Operations that evaluate the stream lazily (i.e Open) complete their summary once they are done.
+func (s *stream[T]) Summary() Summary {
+	if s.stats == nil {
+		return Summary{}
+	}
+	return s.stats.summary()
 }
 
-// terminate terminate the stream.
-func (s *stream[T]) terminate() {
-	s.terminated = true
-	s.closed = true
+// ConsumedCount returns the offset of the source of the stream (see WithOffset) plus the number of source elements its terminal operation consumed, so
+// a stream whose evaluation stopped early can be resumed from the returned offset. Elements of a parallel stream are not consumed in order, so the
+// count is only a resumable offset for a sequential stream. The offset is returned if the stream has not been terminated.
+func (s *stream[T]) ConsumedCount() int {
+	return s.offset + s.Summary().Read
 }
 
 // valid checks if a stream is valid before performing any type of operation.
@@ -99,29 +324,412 @@ func (s stream[T]) Parallel() bool {
 	return s.parallel
 }
 
-// Parallelize returns a parallel stream with the given level of parallelism
+// ExecutionInfo returns how the stream is evaluated, i.e whether it is parallel and the number of routines used.
+func (s *stream[T]) ExecutionInfo() ExecutionInfo {
+	return executionInfo(s.parallel, s.auto, s.executor)
+}
+
+// Parallelize returns a parallel stream with the given level of parallelism. Parallelizing a stream that is already parallel replaces its level
+// of parallelism and profile, the last call wins, use Sequential to switch back to sequential evaluation.
 func (s *stream[T]) Parallelize(n int) Stream[T] {
-	if n <= 1 {
-		panic(errIllegalConfig("Parallelism", fmt.Sprint(n)))
+	if err := checkParallelism(n); err != nil {
+		panic(err)
+	} else if err := s.close(); err != nil {
+		panic(err)
 	}
-	return &stream[T]{
-		supplier:    s.supplier,
-		operations:  s.operations,
-		parallel:    true,
-		maxRoutines: n,
+	result := s.clone()
+	result.operations, result.parallel, result.auto = parallelOperations(s.operations), true, false
+	result.executor = s.executor.configure(executor{maxRoutines: n})
+	if s.appended != nil {
+		result.appended = s.appended.evaluatedBy(true, result.executor)
+		result.supplier = result.appended.supply
 	}
+	return result
+}
+
+// Sequential returns a sequential stream consisting of the elements of this stream, i.e to evaluate the operations of a parallel stream that follow
+// in a single routine. Stateful operations already added keep working as they are safe for access from multiple routines.
+func (s *stream[T]) Sequential() Stream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.parallel, result.auto = false, false
+	if s.appended != nil {
+		result.appended = s.appended.evaluatedBy(false, result.executor)
+		result.supplier = result.appended.supply
+	}
+	return result
+}
+
+// ParallelizeWith returns a parallel stream whose number of routines and dispatching of elements to them is given by the profile.
+func (s *stream[T]) ParallelizeWith(p Profile) Stream[T] {
+	if p.executor.maxRoutines < 1 {
+		panic(errIllegalConfig("Profile", fmt.Sprint(p)))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.operations, result.parallel, result.auto = parallelOperations(s.operations), true, false
+	result.executor = s.executor.configure(p.executor)
+	if s.appended != nil {
+		result.appended = s.appended.evaluatedBy(true, result.executor)
+		result.supplier = result.appended.supply
+	}
+	return result
+}
+
+// ParallelizeAuto returns a stream which decides whether to run in parallel when a terminal operation is invoked. The terminal operation starts
+// processing elements sequentially and measures the cost per element on a sample, the remaining elements are processed in parallel with a computed
+// number of routines only when the estimated benefit outweighs the overhead of the routines. Streams with stateful operations created for sequential
+// execution always remain sequential.
+func (s *stream[T]) ParallelizeAuto() Stream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.auto = true
+	return result
+}
+
+// Repartition returns a stream whose source is the elements resulting from the operations of this stream, operations added to the returned stream
+// are evaluated by n routines over contiguous partitions of equal size, i.e to rebalance work after a selective filter. Partitions are bounds into
+// the evaluated elements so elements are not copied into each partition, a stream repartitioned into a single partition is sequential.
+func (s *stream[T]) Repartition(n int) Stream[T] {
+	if n < 1 {
+		panic(errIllegalArgument("Repartition", fmt.Sprint(n)))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.sourced(elementsSupplier(s))
+	result.parallel, result.auto = n > 1, false
+	result.executor = s.executor.configure(executor{maxRoutines: n})
+	return result
+}
+
+// Barrier returns a stream consisting of the elements of this stream whose later operations are only applied once the operations of this stream have
+// been applied to all the elements, by all routines of a parallel stream. The elements at the barrier are materialized, i.e so that the side effects of
+// a Peek are complete before a later operation reads the state they modify.
+func (s *stream[T]) Barrier() Stream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	return s.sourced(elementsSupplier(s))
+}
+
+// FilterOutliers returns a stream consisting of the elements of this stream whose value is within zScore standard deviations of the mean of the
+// values of all the elements, i.e to drop readings of a faulty sensor. The mean and variance are computed in a first pass over the evaluated
+// elements of this stream, so they are materialized, and the elements are filtered in a second pass. All elements are kept if their values do not
+// vary.
+func (s *stream[T]) FilterOutliers(value func(x T) float64, zScore float64) Stream[T] {
+	if value == nil {
+		panic(errIllegalArgument("FilterOutliers", "nil"))
+	} else if !(zScore > 0) {
+		panic(errIllegalArgument("FilterOutliers", fmt.Sprint(zScore)))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier := elementsSupplier(s)
+	return s.sourced(func() []T { return filterOutliers(supplier(), value, zScore) })
+}
+
+// AppendLazy returns a stream consisting of the elements of this stream followed by the elements of the stream returned by next, i.e the next page
+// of a listing. Next is only invoked once the elements of this stream have been evaluated and only if the operations added to the returned stream
+// can still pass elements, so that a Limit cuts off an unbounded chain of streams appended by next. A nil stream returned by next adds no elements.
+// The operations added to the returned stream are evaluated a stream at a time as part of its source.
+func (s *stream[T]) AppendLazy(next func() Stream[T]) Stream[T] {
+	if next == nil {
+		panic(errIllegalArgument("AppendLazy", "nil"))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	a := &appended[T]{head: elementsSupplier(s), next: next, operations: make([]operator[T], 0), parallel: s.parallel, executor: s.executor}
+	result := s.sourced(a.supply)
+	result.distinct, result.appended = false, a
+	return result
+}
+
+// appended the source of a stream created by AppendLazy, the operations added to the stream are part of the source so that the stream returned
+// by next is only evaluated if they can still pass elements.
+type appended[T any] struct {
+	head       func() []T
+	next       func() Stream[T]
+	operations []operator[T]
+	parallel   bool
+	executor   executor
+	// restriction restricts the elements of each page before the operations are applied, it is stateful and follows the pages in order.
+	restriction func(data []T) []T
+}
+
+// with returns the source with the given operation added.
+func (a *appended[T]) with(operator operator[T]) *appended[T] {
+	return &appended[T]{head: a.head, next: a.next, operations: appendOperation(a.operations, operator), parallel: a.parallel, executor: a.executor,
+		restriction: a.restriction}
+}
+
+// evaluatedBy returns the source with its operations evaluated in parallel by the given executor or sequentially.
+func (a *appended[T]) evaluatedBy(parallel bool, e executor) *appended[T] {
+	operations := a.operations
+	if parallel {
+		operations = parallelOperations(operations)
+	}
+	return &appended[T]{head: a.head, next: a.next, operations: operations, parallel: parallel, executor: e, restriction: a.restriction}
+}
+
+// restricted returns the source with each page restricted by f, which is given the page and the number of elements in the pages before it.
+func (a *appended[T]) restricted(f func(data []T, offset int) []T) *appended[T] {
+	previous, offset := a.restriction, 0
+	result := *a
+	result.restriction = func(data []T) []T {
+		if previous != nil {
+			data = previous(data)
+		}
+		restricted := f(data, offset)
+		offset += len(data)
+		return restricted
+	}
+	return &result
+}
+
+// restrict returns the elements of the given page that remain after the restriction of the source.
+func (a *appended[T]) restrict(data []T) []T {
+	if a.restriction == nil {
+		return data
+	}
+	return a.restriction(data)
+}
+
+// supply returns the elements of the source.
+func (a *appended[T]) supply() []T {
+	results := make([]T, 0)
+	a.each(func(data []T) bool {
+		results = append(results, data...)
+		return true
+	})
+	return results
+}
+
+// each calls f with the elements resulting from applying the operations on the elements of each appended stream in turn, until f returns false or
+// the operations are exhausted.
+func (a *appended[T]) each(f func(data []T) bool) {
+	next := a.pull()
+	for {
+		if data, ok := next(); !ok || !f(data) {
+			return
+		}
+	}
+}
+
+// pull returns a function that returns the elements resulting from applying the operations on the elements of the next appended stream, each call
+// pulls one page of the source. The indication returned is false once there are no more appended streams or the operations are exhausted.
+func (a *appended[T]) pull() func() ([]T, bool) {
+	current, started, done := a, false, false
+	var inner func() ([]T, bool)
+	return func() ([]T, bool) {
+		if done || (started && a.exhausted()) {
+			done = true
+			return nil, false
+		} else if !started {
+			started = true
+			return a.apply(a.restrict(current.head())), true
+		}
+		for inner == nil {
+			next := current.next()
+			if next == nil {
+				done = true
+				return nil, false
+			}
+			tail := next.(*stream[T])
+			if err := tail.close(); err != nil {
+				panic(err)
+			} else if tail.appended == nil {
+				done = true
+				return a.apply(a.restrict(elementsSupplier(tail)())), true
+			} else if len(tail.appended.operations) > 0 {
+				inner = tail.appended.pull()
+			} else {
+				// The appended stream has no operations of its own, continuing with it keeps a long chain (i.e the pages of a channel) from nesting.
+				current = tail.appended
+				return a.apply(a.restrict(current.head())), true
+			}
+		}
+		data, ok := inner()
+		if !ok {
+			done = true
+			return nil, false
+		}
+		return a.apply(a.restrict(data)), true
+	}
+}
+
+// paged returns the source of a stream whose elements are pulled a page at a time using pull, which reports whether more pages may follow.
+func paged[T any](pull func() ([]T, bool)) *appended[T] {
+	more := true
+	return &appended[T]{
+		head: func() []T {
+			var data []T
+			data, more = pull()
+			return data
+		},
+		next: func() Stream[T] {
+			if !more {
+				return nil
+			}
+			a := paged(pull)
+			return &stream[T]{supplier: a.supply, operations: make([]operator[T], 0), appended: a}
+		},
+		operations: make([]operator[T], 0),
+	}
+}
+
+// apply returns the elements resulting from applying the operations on the given elements, in their order.
+func (a *appended[T]) apply(data []T) []T {
+	if a.parallel {
+		return parallelCollectOrdered(data, a.operations, a.executor)
+	}
+	return collect(data, a.operations)
+}
+
+// exhausted checks if any of the operations lets no more elements through.
+func (a *appended[T]) exhausted() bool {
+	return exhausted(a.operations)
+}
+
+// exhausted checks if any of the given operations lets no more elements through.
+func exhausted[T any](operations []operator[T]) bool {
+	for i := range operations {
+		if operations[i].exhausted != nil && operations[i].exhausted() {
+			return true
+		}
+	}
+	return false
 }
 
 // Collect returns a slice containing the elements from the stream.
 func (s *stream[T]) Collect() []T {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	operations, done := s.evaluation()
+	defer done()
+	if s.auto {
+		var results []T
+		rest, e := autoSplit(s.supply(), operations, func(sample []T) { results = collect(sample, operations) })
+		s.stats.workers = e.maxRoutines
+		if e.maxRoutines > 1 {
+			return append(results, s.collectParallel(rest, operations, e)...)
+		}
+		return append(results, collect(rest, operations)...)
+	} else if s.parallel {
+		return s.collectParallel(s.supply(), operations, s.executor)
+	}
+	return collect(s.supply(), operations)
+}
+
+// CollectLimited returns a slice containing the elements from the stream. Evaluation is aborted and an error is returned as soon as the stream
+// produces more than max elements, protecting against unexpectedly large results.
+func (s *stream[T]) CollectLimited(max int) ([]T, error) {
+	if max < 0 {
+		panic(errIllegalArgument("CollectLimited", fmt.Sprint(max)))
+	} else if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
+	if s.appended != nil {
+		results, ok := make([]T, 0), true
+		s.forEachPage(func(data []T, operations []operator[T]) bool {
+			var page []T
+			if s.parallel {
+				page, ok = parallelCollectLimited(data, operations, max-len(results), s.executor)
+			} else {
+				var counter int64
+				page, ok = collectLimited(data, operations, max-len(results), &counter)
+			}
+			results = append(results, page...)
+			if !ok {
+				atomic.StoreInt32(&s.early, 1)
+			}
+			return ok
+		})
+		if !ok {
+			return nil, errCapacityExceeded(max)
+		}
+		return results, nil
+	}
+	operations, done := s.evaluation()
+	defer done()
+	var results []T
+	var ok bool
 	if s.parallel {
-		return parallelCollect(s.supplier(), s.operations, s.maxRoutines)
+		results, ok = parallelCollectLimited(s.supply(), operations, max, s.executor)
+	} else {
+		var counter int64
+		results, ok = collectLimited(s.supply(), operations, max, &counter)
+	}
+	if !ok {
+		return nil, errCapacityExceeded(max)
+	}
+	return results, nil
+}
+
+// ToHeap returns a heap containing the elements from the stream, the least element according to the given less function is at the top.
+func (s *stream[T]) ToHeap(less func(x, y T) bool) *Heap[T] {
+	if less == nil {
+		panic(errIllegalArgument("ToHeap", "nil"))
+	}
+	return newHeap(s.Collect(), less)
+}
+
+// ToSortedSet returns a sorted set containing the elements from the stream ordered by the given less function, elements that are equal according
+// to less are kept once.
+func (s *stream[T]) ToSortedSet(less func(x, y T) bool) *SortedSet[T] {
+	if less == nil {
+		panic(errIllegalArgument("ToSortedSet", "nil"))
+	}
+	return newSortedSet(s.Collect(), less)
+}
+
+// ToSetFunc returns the distinct elements from the stream keyed by the given hash of elements, for elements whose type does not satisfy the constraints
+// of a set type. Of the elements with the same hash the first one collected is kept.
+func (s *stream[T]) ToSetFunc(hash func(x T) string) map[string]T {
+	if hash == nil {
+		panic(errIllegalArgument("ToSetFunc", "nil"))
+	}
+	set := make(map[string]T)
+	for _, x := range s.Collect() {
+		key := hash(x)
+		if _, ok := set[key]; !ok {
+			set[key] = x
+		}
+	}
+	return set
+}
+
+// ToSyncMap returns a sync.Map containing the keys and values computed using the given functions for the elements from the stream, for elements with
+// the same key the value stored last is kept. The elements of a parallel stream are stored by its routines as they are processed.
+func (s *stream[T]) ToSyncMap(key func(x T) any, value func(x T) any) *sync.Map {
+	if key == nil || value == nil {
+		panic(errIllegalArgument("ToSyncMap", "nil"))
+	}
+	m := &sync.Map{}
+	s.ForEach(func(x T) { m.Store(key(x), value(x)) })
+	return m
+}
+
+// Bucketize returns the elements from the stream split into n contiguous buckets, i.e to shard work amongst n workers. The sizes of the buckets
+// differ by at most one with the larger buckets first, the order of the elements is preserved for a sequential stream.
+func (s *stream[T]) Bucketize(n int) [][]T {
+	if n < 1 {
+		panic(errIllegalArgument("Bucketize", fmt.Sprint(n)))
+	}
+	return bucketize(s.Collect(), n, false)
+}
+
+// BucketizeRoundRobin returns the elements from the stream dealt into n buckets, the ith element is placed in bucket i mod n.
+func (s *stream[T]) BucketizeRoundRobin(n int) [][]T {
+	if n < 1 {
+		panic(errIllegalArgument("BucketizeRoundRobin", fmt.Sprint(n)))
 	}
-	return collect(s.supplier(), s.operations)
+	return bucketize(s.Collect(), n, true)
 }
 
 // Map returns a stream consisting of the results of applying the given uniform
@@ -133,6 +741,37 @@ func (s *stream[T]) Map(f func(T) T) Stream[T] {
 	return new(s, uniformMap(f))
 }
 
+// MapMulti returns a stream consisting of the elements passed to emit by f for each element of this stream, so that an element can be replaced by
+// any number of elements, often none, without allocating a slice per element. Emit must only be called before f returns. The operations of this
+// stream are evaluated along with f when the returned stream is evaluated, by the routines of this stream if it is parallel.
+func (s *stream[T]) MapMulti(f func(x T, emit func(T))) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if f == nil {
+		panic(errIllegalArgument("MapMulti", "nil"))
+	}
+	return multiMapElements(s, f)
+}
+
+// TryMap returns a stream consisting of the results of applying the given function to the elements of this stream. The first error returned by the
+// function stops the evaluation, it is returned by CollectErr and ForEachErr while other terminal operations panic with an error with code
+// OperationFailed that wraps it.
+func (s *stream[T]) TryMap(f func(x T) (T, error)) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	}
+	return new(s, tryMap(f))
+}
+
+// TryFilter returns a stream consisting of the elements of this stream that match the given predicate. Like TryMap the first error returned by the
+// predicate stops the evaluation.
+func (s *stream[T]) TryFilter(f func(x T) (bool, error)) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	}
+	return new(s, tryFilter(f))
+}
+
 // Filter returns a stream consisting of the elements of this stream that match the given predicate.
 func (s *stream[T]) Filter(f func(T) bool) Stream[T] {
 	if ok, err := s.valid(); !ok {
@@ -141,6 +780,28 @@ func (s *stream[T]) Filter(f func(T) bool) Stream[T] {
 	return new(s, filter(f))
 }
 
+// MapN returns a stream consisting of the results of applying the given transformation to the elements of the stream, with at most n routines of a
+// parallel stream applying it at the same time, i.e to bound calls to a remote service independently of the parallelism of the stream.
+func (s *stream[T]) MapN(n int, f func(T) T) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if n < 1 {
+		panic(errIllegalArgument("MapN", fmt.Sprint(n)))
+	}
+	return new(s, capConcurrency(n, uniformMap(f)))
+}
+
+// FilterN returns a stream consisting of the elements of this stream that match the given predicate, with at most n routines of a parallel stream
+// evaluating the predicate at the same time.
+func (s *stream[T]) FilterN(n int, f func(T) bool) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if n < 1 {
+		panic(errIllegalArgument("FilterN", fmt.Sprint(n)))
+	}
+	return new(s, capConcurrency(n, filter(f)))
+}
+
 // Limit returns a stream consisting of the elements of this stream, truncated to be no longer than given length.
 func (s *stream[T]) Limit(n int) Stream[T] {
 	if ok, err := s.valid(); !ok {
@@ -151,6 +812,109 @@ func (s *stream[T]) Limit(n int) Stream[T] {
 	return new(s, limit[T](s.parallel, n))
 }
 
+// LimitUntil returns a stream consisting of the elements of this stream up to, and excluding, the first element that satisfies the given predicate.
+// For a parallel stream the first element is the first one to be evaluated by any routine. Elements are only dropped once they have been supplied, a
+// source pulled in pages (i.e FromChannel or AppendLazy) is no longer pulled once the predicate is satisfied.
+func (s *stream[T]) LimitUntil(f func(x T) bool) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	}
+	return new(s, limitUntil(s.parallel, f))
+}
+
+// LimitDuration returns a stream consisting of the elements of this stream that are evaluated within the given duration of the evaluation of the
+// stream starting, elements evaluated after that are dropped. Once the duration has elapsed the stream stops like it does after a Limit, i.e routines
+// of a parallel stream stop taking elements and a stoppable source is stopped.
+func (s *stream[T]) LimitDuration(d time.Duration) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if d < 0 {
+		panic(errIllegalArgument("LimitDuration", fmt.Sprint(d)))
+	}
+	return new(s, limitDuration[T](d))
+}
+
+// SubStream returns a stream consisting of the elements of this stream whose source is restricted to the elements at indexes in [from, to), the
+// operations of this stream are only applied to those elements. The source is resliced rather than copied and the range is truncated to the
+// number of elements supplied.
+func (s *stream[T]) SubStream(from, to int) Stream[T] {
+	if from < 0 || to < from {
+		panic(errIllegalArgument("SubStream", fmt.Sprintf("[%d, %d)", from, to)))
+	}
+	return s.restrict(func(data []T, offset int) []T {
+		start, end := from-offset, to-offset
+		if end > len(data) {
+			end = len(data)
+		} else if end < 0 {
+			end = 0
+		}
+		if start < 0 {
+			start = 0
+		} else if start > end {
+			start = end
+		}
+		return data[start:end]
+	})
+}
+
+// WithOffset returns a stream consisting of the elements of this stream whose source starts at the element at index start, the stream is empty if
+// start exceeds the number of elements. Together with ConsumedCount it allows a job that failed to be run again from where it stopped.
+func (s *stream[T]) WithOffset(start int) Stream[T] {
+	if start < 0 {
+		panic(errIllegalArgument("WithOffset", fmt.Sprint(start)))
+	}
+	restricted := s.restrict(func(data []T, offset int) []T {
+		if skip := start - offset; skip > len(data) {
+			return data[len(data):]
+		} else if skip > 0 {
+			return data[skip:]
+		}
+		return data
+	})
+	restricted.offset += start
+	return restricted
+}
+
+// StrideStream returns a stream consisting of the elements of this stream whose source is restricted to every step-th element starting with the
+// first, i.e to sample a large source. The operations of this stream are only applied to those elements.
+func (s *stream[T]) StrideStream(step int) Stream[T] {
+	if step < 1 {
+		panic(errIllegalArgument("StrideStream", fmt.Sprint(step)))
+	}
+	return s.restrict(func(data []T, offset int) []T {
+		if step == 1 {
+			return data
+		}
+		// The first element of the page that is a multiple of step in the source.
+		first := (step - offset%step) % step
+		sample := make([]T, 0, (len(data)+step-1)/step)
+		for i := first; i < len(data); i += step {
+			sample = append(sample, data[i])
+		}
+		return sample
+	})
+}
+
+// restrict returns a stream with the operations of this stream whose source is restricted by f, which is given the elements of the source and the
+// number of elements before them. A paged source is restricted a page at a time.
+func (s *stream[T]) restrict(f func(data []T, offset int) []T) *stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.sorting = nil
+	if s.appended != nil {
+		result.appended = s.appended.restricted(f)
+		result.supplier = result.appended.supply
+		return result
+	}
+	supplier := s.supplier
+	result.supplier = func() []T { return f(supplier(), 0) }
+	return result
+}
+
 // Skip returns a stream consisting of the remaining elements of this stream after discarding the first n elements of the stream.
 func (s *stream[T]) Skip(n int) Stream[T] {
 	if ok, err := s.valid(); !ok {
@@ -161,64 +925,147 @@ func (s *stream[T]) Skip(n int) Stream[T] {
 
 // Count returns the count of elements in this stream.
 func (s *stream[T]) Count() int {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
-	if s.parallel {
-		return parallelCount(s.supplier(), s.operations, s.maxRoutines)
+	operations, done := s.evaluation()
+	defer done()
+	if s.auto {
+		var counter int
+		rest, e := autoSplit(s.supply(), operations, func(sample []T) { counter = count(sample, operations) })
+		s.stats.workers = e.maxRoutines
+		if e.maxRoutines > 1 {
+			return counter + parallelCount(rest, operations, e)
+		}
+		return counter + count(rest, operations)
+	} else if s.parallel {
+		return parallelCount(s.supply(), operations, s.executor)
 	}
-	return count(s.supplier(), s.operations)
+	return count(s.supply(), operations)
 
 }
 
 // GroupBy transforms the stream to a grouped stream using the given group key function to assign an element to a group.
 func (s *stream[T]) GroupBy(groupKey func(x T) string) GroupedStream[T] {
-	defer s.close()
+	if err := s.close(); err != nil {
+		panic(err)
+	}
 	// Provide the key function implicitly.
 	groupBy := func(data []T) []Group[T] {
-		return groupBy(data, groupKey)
+		return groupBy(data, groupKey, s.hasher)
+	}
+
+	if s.parallel {
+		supplier := parallelTransformSupplier(s.source(), s.operations, groupBy, s.executor)
+		return &groupedStream[T]{
+			supplier:   supplier,
+			operations: make([]operator[Group[T]], 0),
+			parallel:   s.parallel,
+			executor:   s.executor,
+		}
 	}
+	supplier := transformSupplier(s.source(), s.operations, groupBy)
+	return &groupedStream[T]{
+		supplier:   supplier,
+		operations: make([]operator[Group[T]], 0),
+		parallel:   s.parallel,
+		executor:   s.executor,
+	}
+}
 
+// GroupBySorted transforms the stream to a grouped stream using the given group key function, the elements of the stream must be ordered by their key
+// (i.e lines of a log file ordered by time). Each run of elements with equal keys forms a group as soon as the run ends, so groups share the memory
+// of the elements of the stream and only the keys of the runs are kept. A key that appears in runs that are not adjacent causes the terminal operation
+// to panic with an error with code UnsortedStream, so that every terminal operation sees each key once.
+func (s *stream[T]) GroupBySorted(groupKey func(x T) string) GroupedStream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, hasher := s.source(), s.operations, s.hasher
 	if s.parallel {
-		supplier := parallelTransformSupplier(s.supplier, s.operations, groupBy, s.maxRoutines)
 		return &groupedStream[T]{
-			supplier:    supplier,
-			operations:  make([]operator[Group[T]], 0),
-			parallel:    s.parallel,
-			maxRoutines: s.maxRoutines,
+			supplier: func() []Group[T] {
+				return groupRuns(parallelCollectOrdered(supplier(), operations, s.executor), groupKey, hasher)
+			},
+			operations: make([]operator[Group[T]], 0),
+			parallel:   s.parallel,
+			executor:   s.executor,
 		}
 	}
-	supplier := transformSupplier(s.supplier, s.operations, groupBy)
 	return &groupedStream[T]{
-		supplier:    supplier,
-		operations:  make([]operator[Group[T]], 0),
-		parallel:    s.parallel,
-		maxRoutines: s.maxRoutines,
+		supplier:   transformSupplier(supplier, operations, func(data []T) []Group[T] { return groupRuns(data, groupKey, hasher) }),
+		operations: make([]operator[Group[T]], 0),
+		parallel:   s.parallel,
+		executor:   s.executor,
 	}
 }
 
 // Partition returns a partitioned streamed whose elements are the results of splitting each member of this stream using the given function.
 func (s *stream[T]) Partition(f func(x T) []T) PartitionedStream[T] {
-	defer s.close()
+	if err := s.close(); err != nil {
+		panic(err)
+	}
 	if s.parallel {
-		supplier := parallelPartitionSupplierElements(s.supplier, s.operations, f, s.maxRoutines)
+		supplier := parallelPartitionSupplierElements(s.source(), s.operations, f, s.executor)
 		return &partitionedStream[T]{
-			supplier:    supplier,
-			operations:  make([]operator[[]T], 0),
-			parallel:    s.parallel,
-			maxRoutines: s.maxRoutines,
+			supplier:   supplier,
+			operations: make([]operator[[]T], 0),
+			parallel:   s.parallel,
+			executor:   s.executor,
 		}
 	}
 	supplier := func() [][]T {
-		return partitionSupplierElements(s.supplier(), s.operations, f)
+		return partitionSupplierElements(s.source()(), s.operations, f)
+	}
+
+	return &partitionedStream[T]{
+		supplier:   supplier,
+		operations: make([]operator[[]T], 0),
+		parallel:   s.parallel,
+		executor:   s.executor,
+	}
+}
+
+// Window returns a partitioned stream whose elements are the consecutive elements of this stream batched into slices of the given size, the last
+// slice has the remaining elements if their number is not a multiple of the size. Elements of a parallel stream are batched in their source order.
+func (s *stream[T]) Window(size int) PartitionedStream[T] {
+	if size < 1 {
+		panic(errIllegalArgument("Window", fmt.Sprint(size)))
+	}
+	return s.window(size, size, true)
+}
+
+// SlidingWindow returns a partitioned stream whose elements are the windows of the given size of consecutive elements of this stream, a window
+// starts every step elements. Only full windows are included, so no window is produced from a stream with less elements than the size. Elements
+// of a parallel stream are windowed in their source order.
+func (s *stream[T]) SlidingWindow(size, step int) PartitionedStream[T] {
+	if size < 1 {
+		panic(errIllegalArgument("SlidingWindow", fmt.Sprint(size)))
+	} else if step < 1 {
+		panic(errIllegalArgument("SlidingWindow", fmt.Sprint(step)))
 	}
+	return s.window(size, step, false)
+}
 
+// window returns a partitioned stream whose elements are windows of consecutive elements of this stream, see windows.
+func (s *stream[T]) window(size, step int, partial bool) PartitionedStream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, e := s.source(), s.operations, s.executor
+	if s.parallel {
+		return &partitionedStream[T]{
+			supplier:   func() [][]T { return windows(parallelCollectOrdered(supplier(), operations, e), size, step, partial) },
+			operations: make([]operator[[]T], 0),
+			parallel:   s.parallel,
+			executor:   s.executor,
+		}
+	}
 	return &partitionedStream[T]{
-		supplier:    supplier,
-		operations:  make([]operator[[]T], 0),
-		parallel:    s.parallel,
-		maxRoutines: s.maxRoutines,
+		supplier:   func() [][]T { return windows(collect(supplier(), operations), size, step, partial) },
+		operations: make([]operator[[]T], 0),
+		parallel:   s.parallel,
+		executor:   s.executor,
 	}
 }
 
@@ -226,27 +1073,417 @@ func (s *stream[T]) Partition(f func(x T) []T) PartitionedStream[T] {
 func (s *stream[T]) Distinct(hash func(x T) string) Stream[T] {
 	if ok, err := s.valid(); !ok {
 		panic(err)
+	} else if s.sorting != nil && len(s.operations) == 0 && hash != nil {
+		if err := s.close(); err != nil {
+			panic(err)
+		}
+		return sorted(s.sorting.source, s, s.sorting.operations, s.sorting.less, hash, false)
 	}
-	newStream := new(s, distinct(s.parallel, s.distinct, hash))
+	newStream := new(s, distinct(s.parallel, s.distinct, hash, s.hasher))
 	newStream.distinct = true
 	return newStream
 }
 
 // ForEach performs an action for each element of this stream.
 func (s *stream[T]) ForEach(f func(T)) {
-	if ok, err := s.valid(); !ok {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
-	data := s.supplier()
-	operations := s.operations
-	if s.parallel {
-		parallelForEach(data, operations, f, s.maxRoutines)
+	s.forEach(f)
+}
+
+// ToChannel returns a channel receiving the elements of the stream as they are produced, i.e so that a consumer can start processing the elements of
+// a parallel stream before all of them have been evaluated. The stream is evaluated by a new routine which closes the channel once all elements have
+// been sent or the given context is done, so a consumer that stops reading must cancel the context for the routine to return. Once the context is done
+// the evaluation stops as for ForEachWhile and the channel is closed without the remaining elements. The channel of a parallel stream is buffered
+// with a slot for each routine.
+func (s *stream[T]) ToChannel(ctx context.Context) <-chan T {
+	if ctx == nil {
+		panic(errIllegalArgument("ToChannel", "nil"))
+	} else if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	buffer := 0
+	if s.parallel || s.auto {
+		buffer = s.ExecutionInfo().Workers
+	}
+	channel := make(chan T, buffer)
+	go func() {
+		defer close(channel)
+		defer trackRoutine("ToChannel")()
+		s.forEachWhile(func(x T) bool {
+			select {
+			case channel <- x:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return channel
+}
+
+// forEach performs an action for each element of the stream, which has been terminated.
+func (s *stream[T]) forEach(f func(T)) {
+	if s.appended != nil {
+		s.forEachPage(func(data []T, operations []operator[T]) bool {
+			if s.parallel {
+				parallelForEach(data, operations, f, s.executor)
+			} else {
+				forEach(data, operations, f)
+			}
+			return true
+		})
+		return
+	}
+	data := s.supply()
+	operations, done := s.evaluation()
+	defer done()
+	if s.auto {
+		rest, e := autoSplit(data, operations, func(sample []T) { forEach(sample, operations, f) })
+		s.stats.workers = e.maxRoutines
+		if e.maxRoutines > 1 {
+			parallelForEach(rest, operations, f, e)
+			return
+		}
+		forEach(rest, operations, f)
+		return
+	} else if s.parallel {
+		parallelForEach(data, operations, f, s.executor)
 		return
 	}
 	forEach(data, operations, f)
 }
 
+// forEachPage calls f with each page of the source of a stream created by AppendLazy or FromChannel along with the operations to apply to it, so that
+// elements are processed as the pages are pulled, until f returns false.
+func (s *stream[T]) forEachPage(f func(data []T, operations []operator[T]) bool) {
+	operations, done := s.evaluation()
+	defer done()
+	s.appended.each(func(data []T) bool {
+		s.page(data)
+		return f(data, operations)
+	})
+}
+
+// page records the number of elements of a page pulled from the source for the summary of a terminated stream, only one page is held at a time so
+// the peak is the largest page.
+func (s *stream[T]) page(data []T) {
+	if s.stats != nil && len(data) > s.stats.buffered {
+		s.stats.buffered = len(data)
+	}
+}
+
+// ForEachBatchBytes performs an action specified by the function f for batches of elements of the stream, a batch is passed to f once adding the next
+// element would take the total size of its elements (computed using the given size function) over maxBytes, i.e for sinks with a limit on the size
+// of a request. An element larger than maxBytes is passed to f in a batch of its own. For a parallel stream f may be called concurrently.
+func (s *stream[T]) ForEachBatchBytes(maxBytes int, size func(x T) int, f func(batch []T)) {
+	if maxBytes < 1 {
+		panic(errIllegalArgument("ForEachBatchBytes", fmt.Sprint(maxBytes)))
+	} else if size == nil || f == nil {
+		panic(errIllegalArgument("ForEachBatchBytes", "nil"))
+	}
+	var mux sync.Mutex
+	var batch []T
+	var bytes int
+	s.ForEach(func(x T) {
+		n := size(x)
+		mux.Lock()
+		var full []T
+		if len(batch) > 0 && bytes+n > maxBytes {
+			full, batch, bytes = batch, nil, 0
+		}
+		batch = append(batch, x)
+		bytes += n
+		mux.Unlock()
+		if full != nil {
+			f(full)
+		}
+	})
+	if len(batch) > 0 {
+		f(batch)
+	}
+}
+
+// DrainTo writes the elements of the stream to the sink in batches of at most batchSize elements, a batch whose write fails is retried with a backoff
+// (see Retry) and the stream stops being consumed once a batch cannot be written. The sink is committed only if all batches were written, the error of
+// the failed write or of the commit is returned. Calls to the sink are never concurrent, even for a parallel stream.
+func (s *stream[T]) DrainTo(sink Sink[T], batchSize int, opts ...SinkOption) error {
+	if batchSize < 1 {
+		panic(errIllegalArgument("DrainTo", fmt.Sprint(batchSize)))
+	} else if sink == nil {
+		panic(errIllegalArgument("DrainTo", "nil"))
+	}
+	o := newSinkOptions(opts)
+	var mux sync.Mutex
+	var err error
+	batch := make([]T, 0, batchSize)
+	s.ForEachWhile(func(x T) bool {
+		mux.Lock()
+		defer mux.Unlock()
+		if err != nil {
+			return false
+		}
+		batch = append(batch, x)
+		if len(batch) == batchSize {
+			err = writeBatch(sink, batch, o)
+			batch = make([]T, 0, batchSize)
+		}
+		return err == nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = writeBatch(sink, batch, o)
+	}
+	if err != nil {
+		return err
+	}
+	return sink.Commit()
+}
+
+// ToFile writes the elements of the stream encoded with the given function to the file at the given path. The elements are written to a temporary
+// file in the same directory which is renamed to the path once all elements have been written, so the file is never left partially written if
+// writing fails or an operation of the stream panics. For a parallel stream the elements are written in the order they are processed.
+func (s *stream[T]) ToFile(path string, encode func(x T) []byte, opts ...FileOption) error {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if encode == nil {
+		panic(errIllegalArgument("ToFile", "nil"))
+	}
+	w, err := newFileWriter(path, opts)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			w.abort()
+		}
+	}()
+	var mux sync.Mutex
+	s.ForEachWhile(func(x T) bool {
+		data := encode(x)
+		mux.Lock()
+		defer mux.Unlock()
+		if err == nil {
+			err = w.write(data)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	} else if err = w.commit(); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// ForEachWhile performs the given action on each element of the stream until the action returns false. For a parallel stream the routines stop
+// taking elements as soon as the action returns false for any element, elements already being processed by other routines are still completed.
+func (s *stream[T]) ForEachWhile(f func(T) bool) {
+	if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	s.forEachWhile(f)
+}
+
+// forEachWhile performs an action for each element of the stream, which has been terminated, until the action returns false.
+func (s *stream[T]) forEachWhile(f func(T) bool) {
+	var stop int32
+	if s.appended != nil {
+		s.forEachPage(func(data []T, operations []operator[T]) bool {
+			if s.parallel {
+				parallelForEachWhile(data, operations, f, &stop, s.executor)
+			} else {
+				forEachWhile(data, operations, f, &stop)
+			}
+			if atomic.LoadInt32(&stop) == 1 {
+				atomic.StoreInt32(&s.early, 1)
+				return false
+			}
+			return true
+		})
+		return
+	}
+	data := s.supply()
+	operations, done := s.evaluation()
+	defer done()
+	defer func() {
+		if atomic.LoadInt32(&stop) == 1 {
+			atomic.StoreInt32(&s.early, 1)
+		}
+	}()
+	if s.auto {
+		rest, e := autoSplit(data, operations, func(sample []T) { forEachWhile(sample, operations, f, &stop) })
+		s.stats.workers = e.maxRoutines
+		if atomic.LoadInt32(&stop) == 1 {
+			return
+		} else if e.maxRoutines > 1 {
+			parallelForEachWhile(rest, operations, f, &stop, e)
+			return
+		}
+		forEachWhile(rest, operations, f, &stop)
+		return
+	} else if s.parallel {
+		parallelForEachWhile(data, operations, f, &stop, s.executor)
+		return
+	}
+	forEachWhile(data, operations, f, &stop)
+}
+
+// AnyMatch returns an indication of whether any element of the stream satisfies the given predicate, false is returned if there are no elements. The
+// evaluation stops at the first element that satisfies the predicate, routines of a parallel stream stop taking elements once any of them finds one.
+func (s *stream[T]) AnyMatch(f func(x T) bool) bool {
+	var found int32
+	s.ForEachWhile(func(x T) bool {
+		if f(x) {
+			atomic.StoreInt32(&found, 1)
+			return false
+		}
+		return true
+	})
+	return atomic.LoadInt32(&found) == 1
+}
+
+// AllMatch returns an indication of whether all elements of the stream satisfy the given predicate, true is returned if there are no elements. The
+// evaluation stops at the first element that does not satisfy the predicate.
+func (s *stream[T]) AllMatch(f func(x T) bool) bool {
+	return !s.AnyMatch(func(x T) bool { return !f(x) })
+}
+
+// NoneMatch returns an indication of whether no element of the stream satisfies the given predicate, true is returned if there are no elements. The
+// evaluation stops at the first element that satisfies the predicate.
+func (s *stream[T]) NoneMatch(f func(x T) bool) bool {
+	return !s.AnyMatch(f)
+}
+
+// ForEachErr performs an action specified by the function f for each element of the stream and returns the errors returned by f joined into one,
+// nil is returned if there were none. If the stream fails fast (see FailFast) f is not called for any element after the first error and that error
+// is returned, routines of a parallel stream finish the calls already in progress before returning. The error of an operation that failed, i.e
+// TryMap, stops the evaluation and is joined to the errors returned by f.
+func (s *stream[T]) ForEachErr(f func(T) error) error {
+	var mux sync.Mutex
+	var errs []error
+	failure := catchFailure(func() {
+		s.ForEachWhile(func(x T) bool {
+			if err := f(x); err != nil {
+				mux.Lock()
+				defer mux.Unlock()
+				errs = append(errs, err)
+				return !s.failFast
+			}
+			return true
+		})
+	})
+	if failure != nil {
+		errs = append(errs, failure)
+	}
+	if len(errs) == 0 {
+		return nil
+	} else if s.failFast {
+		return errs[0]
+	}
+	return joinErrors(errs)
+}
+
+// CollectErr returns a slice containing the elements from the stream, the evaluation is stopped by the first error of an operation, i.e TryMap, which
+// is returned instead of the elements. Routines of a parallel stream finish the partitions they are evaluating before returning.
+func (s *stream[T]) CollectErr() ([]T, error) {
+	var data []T
+	if err := catchFailure(func() { data = s.Collect() }); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CollectWithin returns a slice containing the elements produced by the stream within the given duration and an indication of whether the evaluation
+// completed in time. Once the duration elapses the elements produced so far are returned, the routines evaluating the stream stop taking elements
+// although an element that is being evaluated is not interrupted, and a source created by FromChannel is stopped so that they do not wait for it. A
+// panic raised after the duration has elapsed is discarded.
+func (s *stream[T]) CollectWithin(d time.Duration) ([]T, bool) {
+	if d < 0 {
+		panic(errIllegalArgument("CollectWithin", fmt.Sprint(d)))
+	} else if err := s.terminate(); err != nil {
+		panic(err)
+	}
+	var mux sync.Mutex
+	var expired bool
+	results := make([]T, 0)
+	done := make(chan any, 1)
+	go func() {
+		defer trackRoutine("CollectWithin")()
+		defer func() { done <- recover() }()
+		s.forEachWhile(func(x T) bool {
+			mux.Lock()
+			defer mux.Unlock()
+			if expired {
+				return false
+			}
+			results = append(results, x)
+			return true
+		})
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if r != nil {
+			panic(r)
+		}
+		return results, true
+	case <-timer.C:
+		mux.Lock()
+		defer mux.Unlock()
+		expired = true
+		if s.interrupt != nil {
+			// The evaluation may be waiting for the source, i.e an open channel, stopping it lets the routine return.
+			s.interrupt()
+		}
+		return append(make([]T, 0, len(results)), results...), false
+	}
+}
+
+// FailFast returns a stream consisting of the elements of this stream whose ForEachErr stops at the first error returned by the action.
+func (s *stream[T]) FailFast() Stream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.failFast = true
+	return result
+}
+
+// Ordered returns a stream consisting of the elements of this stream whose parallel Collect and Reduce keep the order of the source elements the
+// elements result from, as a sequential stream does, by stitching the results of partitions back together in the order of the partitions. Streams
+// derived from the returned stream using its methods are ordered as well.
+func (s *stream[T]) Ordered() Stream[T] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.ordered = true
+	return result
+}
+
+// collectParallel returns the resulting elements from applying the given operations to the data in parallel, in the order of the data if the stream
+// is ordered.
+func (s *stream[T]) collectParallel(data []T, operations []operator[T], e executor) []T {
+	if s.ordered {
+		return parallelCollectOrdered(data, operations, e)
+	}
+	return parallelCollect(data, operations, e)
+}
+
+// reduceParallel returns the result of reduction on the resulting elements from applying the given operations to the data in parallel, the results
+// of partitions are reduced in the order of the data if the stream is ordered.
+func (s *stream[T]) reduceParallel(data []T, operations []operator[T], f func(x, y T) T, e executor) (T, bool) {
+	if s.ordered {
+		return parallelReduceOrdered(data, operations, f, e)
+	}
+	return parallelReduce(data, operations, f, e)
+}
+
 // Peek returns a stream consisting of the elements of this stream,
 // additionally the provided action on each element as elements are consumed.
 func (s *stream[T]) Peek(f func(T)) Stream[T] {
@@ -259,15 +1496,207 @@ func (s *stream[T]) Peek(f func(T)) Stream[T] {
 // Reduce performs a reduction on the elements of the stream, using ssociative accumulation function, and returns the reduced value.
 // The zero value is returned if there are no elements.
 func (s *stream[T]) Reduce(f func(x, y T) T) T {
-	if ok, err := s.valid(); !ok {
+	val, _ := s.reduce(f)
+	return val
+}
+
+// Min returns the least element of the stream according to less, the first of equal least elements for a sequential stream. False is returned if
+// there are no elements. A parallel stream computes the least element of each partition and then the least of those.
+func (s *stream[T]) Min(less func(x, y T) bool) (T, bool) {
+	return s.reduce(func(x, y T) T {
+		if less(y, x) {
+			return y
+		}
+		return x
+	})
+}
+
+// Max returns the greatest element of the stream according to less, the first of equal greatest elements for a sequential stream. False is returned
+// if there are no elements. A parallel stream computes the greatest element of each partition and then the greatest of those.
+func (s *stream[T]) Max(less func(x, y T) bool) (T, bool) {
+	return s.reduce(func(x, y T) T {
+		if less(x, y) {
+			return y
+		}
+		return x
+	})
+}
+
+// reduce terminates the stream and performs a reduction on its elements using the given associative function, false is returned if there are no
+// elements.
+func (s *stream[T]) reduce(f func(x, y T) T) (T, bool) {
+	if err := s.terminate(); err != nil {
 		panic(err)
 	}
-	defer s.terminate()
+	operations, done := s.evaluation()
+	defer done()
+	if s.auto {
+		var x T
+		var sampled bool
+		rest, e := autoSplit(s.supply(), operations, func(sample []T) { x, sampled = reduce(sample, operations, f) })
+		s.stats.workers = e.maxRoutines
+		var y T
+		var ok bool
+		if e.maxRoutines > 1 {
+			y, ok = s.reduceParallel(rest, operations, f, e)
+		} else {
+			y, ok = reduce(rest, operations, f)
+		}
+		if sampled && ok {
+			return f(x, y), true
+		} else if ok {
+			return y, true
+		}
+		return x, sampled
+	} else if s.parallel {
+		return s.reduceParallel(s.supply(), operations, f, s.executor)
+	}
+	return reduce(s.supply(), operations, f)
+}
+
+// Validate evaluates the stream and checks each element against the given rules. Elements that satisfy all the rules are passed on to the returned stream,
+// the rest are reported as violations together with the errors of the rules they failed. The source of the stream is released once it has been
+// evaluated, so the returned stream does not release it again.
+func (s *stream[T]) Validate(rules ...func(x T) error) (Stream[T], []ValidationError[T]) {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	var valid []T
+	var violations []ValidationError[T]
+	operations, done := s.evaluation()
+	defer done()
 	if s.parallel {
-		val, _ := parallelReduce(s.supplier(), s.operations, f, s.maxRoutines)
-		return val
+		valid, violations = parallelValidate(s.supply(), operations, rules, s.executor)
+	} else {
+		valid, violations = validate(s.supply(), operations, rules)
 	}
-	val, _ := reduce(s.supplier(), s.operations, f)
-	return val
+	result := s.sourced(func() []T { return valid })
+	result.release = nil
+	return result, violations
+}
+
+// Progress returns a stream consisting of the elements of this stream, additionally the provided function is called with the number of elements
+// that have been consumed so far as each element is consumed. For a parallel stream the count is shared across routines and f may be called concurrently.
+func (s *stream[T]) Progress(f func(done int)) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	}
+	return new(s, progress[T](f))
+}
+
+// WithProgressBar returns a stream consisting of the elements of this stream, additionally render is called with the number of elements consumed
+// so far and the expected total. Renders are throttled to at most one per ProgressInterval except for the final one (done == total) and are never
+// invoked concurrently or with a smaller count than a previous render.
+func (s *stream[T]) WithProgressBar(total int, render func(done, total int)) Stream[T] {
+	var last int64 // Time of the last render in nanoseconds.
+	var mux sync.Mutex
+	rendered := 0
+	return s.Progress(func(done int) {
+		now := time.Now().UnixNano()
+		prev := atomic.LoadInt64(&last)
+		if done != total && (now-prev < int64(ProgressInterval) || !atomic.CompareAndSwapInt64(&last, prev, now)) {
+			return
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		if done < rendered {
+			return
+		}
+		rendered = done
+		render(done, total)
+	})
+}
+
+// Lint returns warnings for operations of the stream that are obviously redundant, i.e Distinct twice with the same hash function or Map(Identity),
+// or that discard every element, i.e Limit(0) or Skip(n) after a smaller Limit, so that misconfigured pipelines are caught in tests. The stream is left open.
+func (s *stream[T]) Lint() []Warning {
+	return lint(s.declared())
+}
+
+// declared returns the operations added to the stream in order, including those evaluated as part of the source of a stream created by AppendLazy or
+// FromChannel.
+func (s *stream[T]) declared() []operator[T] {
+	if s.appended == nil {
+		return s.operations
+	}
+	return append(append(make([]operator[T], 0), s.appended.operations...), s.operations...)
+}
+
+// DryRun checks that the stream can be evaluated without pulling any data from its source, returns an error describing the first problem found
+// i.e an operation created with a nil function, a stateful operation created for a sequential stream evaluated in parallel or a stateful operation
+// of a parallel stream (i.e Distinct) evaluated before Sorted, whose sorted elements would then depend on the scheduling of the routines. Operations
+// evaluated before the source of the stream (i.e before Sorted or Map) are checked as well. It is named DryRun rather than Validate since Validate
+// checks the elements of the stream against rules.
+func (s *stream[T]) DryRun() error {
+	if ok, err := s.valid(); !ok {
+		return err
+	} else if s.supplier == nil {
+		return errIllegalPlan("nil supplier", "SOURCE", 0)
+	} else if err := s.plan(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// plan checks the plan of the operations of the stream and of the operations evaluated before its source, see plan.
+func (s *stream[T]) plan() *streamError {
+	if s.upstream != nil {
+		if err := s.upstream(); err != nil {
+			return err
+		}
+	}
+	return plan(s.declared(), s.parallel)
+}
+
+// WithCapture returns a stream consisting of the elements of this stream which, when evaluated by a terminal operation, recovers from panics raised
+// by the functions given to its operations and records the source elements (before any transformation) that caused them. Elements that cause a panic
+// are dropped and at most max of them are recorded, once evaluation is done the terminal operation panics with an error carrying the recorded elements.
+// Errors raised by the package (i.e the failure of TryMap) are not captured.
+func (s *stream[T]) WithCapture(max int) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if max <= 0 {
+		panic(errIllegalArgument("WithCapture", fmt.Sprint(max)))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.capture = max
+	return result
+}
+
+// WithHasher returns a stream consisting of the elements of this stream whose subsequent hash based operations (Distinct, GroupBy) hash the keys
+// computed by their key functions using the given hasher instead of the built in map hashing.
+func (s *stream[T]) WithHasher(h Hasher[string]) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if h == nil {
+		panic(errIllegalArgument("WithHasher", "nil"))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	result := s.clone()
+	result.hasher = h
+	return result
+}
 
+// WithScheduler returns a stream consisting of the elements of this stream whose parallel evaluation notifies the given scheduler at its scheduling
+// points. It is meant for tests, the scheduler is kept by streams derived from the returned stream including when their parallelism is changed.
+func (s *stream[T]) WithScheduler(scheduler Scheduler) Stream[T] {
+	if ok, err := s.valid(); !ok {
+		panic(err)
+	} else if scheduler == nil {
+		panic(errIllegalArgument("WithScheduler", "nil"))
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	e := s.executor
+	e.scheduler = scheduler
+	result := s.clone()
+	result.executor = e
+	if s.appended != nil {
+		result.appended = s.appended.evaluatedBy(s.parallel, e)
+		result.supplier = result.appended.supply
+	}
+	return result
 }
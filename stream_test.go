@@ -1,9 +1,16 @@
 package streams
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -98,6 +105,56 @@ func TestMap(t *testing.T) {
 
 }
 
+func TestMapMulti(t *testing.T) {
+
+	type mapMultiTest struct {
+		data     []int
+		f        func(x int, emit func(int))
+		expected []int
+	}
+
+	repeat := func(x int, emit func(int)) {
+		for i := 0; i < x; i++ {
+			emit(x)
+		}
+	}
+	var mapMultiTests = []mapMultiTest{
+		{data: []int{}, f: repeat, expected: []int{}},
+		{data: []int{0, 1, 2, 0, 3}, f: repeat, expected: []int{1, 2, 2, 3, 3, 3}},
+		{data: []int{1, 2, 3}, f: func(x int, emit func(int)) {}, expected: []int{}},
+	}
+
+	for _, test := range mapMultiTests {
+		s1, s2 := New(func() []int { return test.data }).MapMulti(test.f),
+			New(func() []int { return test.data }).Parallelize(2).MapMulti(test.f)
+		assert.Equal(t, test.expected, s1.Collect())
+		assert.ElementsMatch(t, test.expected, s2.Collect())
+		assert.True(t, s1.Terminated())
+		assert.True(t, s2.Terminated())
+		assert.True(t, s2.Parallel())
+	}
+
+	// Operations before and after are applied.
+	s := New(func() []int { return []int{1, 2, 3, 4} }).Filter(func(x int) bool { return x%2 == 0 }).MapMulti(repeat).Map(func(x int) int { return x * 10 })
+	assert.Equal(t, []int{20, 20, 40, 40, 40, 40}, s.Collect())
+	assert.Panics(t, func() { New(func() []int { return []int{1} }).MapMulti(nil) })
+
+	// The settings of the stream are kept.
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+	twice := func(x int, emit func(int)) {
+		emit(x)
+		emit(x)
+	}
+	expected := New(func() []int { return data }).MapMulti(twice).Collect()
+	assert.Equal(t, expected, New(func() []int { return data }).Parallelize(4).Ordered().MapMulti(twice).Collect())
+	assert.True(t, New(func() []int { return data }).ParallelizeAuto().MapMulti(twice).ExecutionInfo().Auto)
+	assert.True(t, New(func() []int { return data }).FailFast().MapMulti(twice).(*stream[int]).failFast)
+
+}
+
 func TestCount(t *testing.T) {
 
 	type countTest struct {
@@ -147,6 +204,60 @@ func TestReduce(t *testing.T) {
 		assert.True(t, s2.Closed())
 		assert.True(t, s2.Terminated())
 	}
+	// Elements filtered out before the first retained element should not take part in the reduction.
+	greaterThanOne := func(x int) bool { return x > 1 }
+	product := func(x, y int) int { return x * y }
+	assert.Equal(t, 24, New(func() []int { return []int{1, 2, 3, 4} }).Filter(greaterThanOne).Reduce(product))
+	assert.Equal(t, 24, New(func() []int { return []int{1, 2, 3, 4} }).Parallelize(2).Filter(greaterThanOne).Reduce(product))
+}
+
+func TestMinMax(t *testing.T) {
+
+	type minMaxTest struct {
+		data     []int
+		min, max int
+		ok       bool
+	}
+
+	var minMaxTests = []minMaxTest{
+		{data: []int{}, min: 0, max: 0, ok: false},
+		{data: []int{7}, min: 7, max: 7, ok: true},
+		{data: []int{3, 1, 4, 1, 5, 9, 2, 6}, min: 1, max: 9, ok: true},
+		{data: []int{-2, -8, -1}, min: -8, max: -1, ok: true},
+	}
+
+	less := func(x, y int) bool { return x < y }
+	for _, test := range minMaxTests {
+		for _, s := range []func() Stream[int]{
+			func() Stream[int] { return New(func() []int { return test.data }) },
+			func() Stream[int] { return New(func() []int { return test.data }).Parallelize(2) },
+			func() Stream[int] { return New(func() []int { return test.data }).ParallelizeAuto() },
+		} {
+			least, ok := s().Min(less)
+			assert.Equal(t, test.min, least)
+			assert.Equal(t, test.ok, ok)
+			greatest, ok := s().Max(less)
+			assert.Equal(t, test.max, greatest)
+			assert.Equal(t, test.ok, ok)
+		}
+	}
+
+	// The first of equal elements is returned by a sequential stream.
+	type keyed struct{ key, value int }
+	byKey := func(x, y keyed) bool { return x.key < y.key }
+	data := []keyed{{2, 0}, {1, 1}, {3, 2}, {1, 3}, {3, 4}}
+	first, _ := New(func() []keyed { return data }).Min(byKey)
+	last, _ := New(func() []keyed { return data }).Max(byKey)
+	assert.Equal(t, keyed{1, 1}, first)
+	assert.Equal(t, keyed{3, 2}, last)
+
+	// Elements filtered out do not take part.
+	s := New(func() []int { return []int{1, 2, 3, 4} }).Parallelize(2).Filter(func(x int) bool { return x%2 == 0 })
+	least, ok := s.Min(less)
+	assert.Equal(t, 2, least)
+	assert.True(t, ok)
+	assert.True(t, s.Terminated())
+
 }
 
 func TestLimit(t *testing.T) {
@@ -319,151 +430,1995 @@ func TestForEach(t *testing.T) {
 
 }
 
-func TestErr(t *testing.T) {
+func TestToChannel(t *testing.T) {
 
-	type errTest struct {
-		f               func()
-		expectedErrCode int
+	data := []int{1, 2, 3, 4, 5}
+	collect := func(channel <-chan int) []int {
+		results := make([]int, 0)
+		for x := range channel {
+			results = append(results, x)
+		}
+		return results
 	}
 
-	supplier := func() []int { return []int{} }
-	var errTests = []errTest{
-		{f: func() {
-			a := New(supplier)
-			_ = a.Filter(nil)
-			a.Filter(nil)
-		},
-			expectedErrCode: StreamClosed,
-		},
-		{f: func() {
-			a := New(supplier)
-			_ = a.Filter(nil)
-			a.Map(nil)
-		},
-			expectedErrCode: StreamClosed,
-		},
-		{
-			f: func() {
-				a := New(supplier)
-				_ = a.Collect()
-				a.Filter(nil)
-			},
-			expectedErrCode: StreamTerminated,
-		},
-		{
-			f: func() {
-				_ = New(supplier).Parallelize(1)
-			},
-			expectedErrCode: IllegalConfig,
-		},
-		{
-			f: func() {
-				_ = New(supplier).Limit(-1)
-			},
-			expectedErrCode: IllegalArgument,
-		},
-		{
-			f: func() {
-				_ = New(supplier).Skip(-1)
-			},
-			expectedErrCode: IllegalArgument,
-		},
-		{
-			f: func() {
-				a := New(supplier)
-				_ = a.Filter(nil)
-				a.Collect()
-			},
-			expectedErrCode: StreamClosed,
-		},
-		{
-			f: func() {
-				a := New(supplier)
-				_ = a.Filter(nil)
-				a.Limit(1)
-			},
-			expectedErrCode: StreamClosed,
-		},
-		{
-			f: func() {
-				a := New(supplier)
-				_ = a.Filter(nil)
-				a.Skip(1)
-			},
-			expectedErrCode: StreamClosed,
-		},
+	s1, s2 := New(func() []int { return data }).Map(func(x int) int { return x * 2 }), New(func() []int { return data }).Parallelize(2).Map(func(x int) int { return x * 2 })
+	assert.Equal(t, []int{2, 4, 6, 8, 10}, collect(s1.ToChannel(context.Background())))
+	assert.ElementsMatch(t, []int{2, 4, 6, 8, 10}, collect(s2.ToChannel(context.Background())))
+	assert.True(t, s1.Terminated())
+	assert.True(t, s2.Terminated())
+	assert.Panics(t, func() { s1.ToChannel(context.Background()) })
+	assert.Empty(t, collect(New[int](nil).ToChannel(context.Background())))
+
+	// Elements are received before the remaining elements are evaluated.
+	received := make(chan struct{})
+	channel := New(func() []int { return data }).Peek(func(x int) {
+		if x == 2 {
+			<-received
+		}
+	}).ToChannel(context.Background())
+	select {
+	case x := <-channel:
+		assert.Equal(t, 1, x)
+		close(received)
+	case <-time.After(time.Second):
+		t.Fatal("no element received")
 	}
-
-	for _, test := range errTests {
-		t.Run("", func(t *testing.T) {
-			defer func(expectedErrCode int) {
-				if r := recover(); r != nil {
-					assert.Equal(t, expectedErrCode, r.(*streamError).Code())
-				}
-			}(test.expectedErrCode)
-			test.f()
-		})
+	assert.Equal(t, []int{2, 3, 4, 5}, collect(channel))
+
+	// Cancelling the context stops the evaluation once the consumer stops reading.
+	for _, parallel := range []bool{false, true} {
+		p := &pager{}
+		s := NewStoppable(func() []int { return make([]int, 100) }, p)
+		if parallel {
+			s = s.Parallelize(2)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		channel := s.ToChannel(ctx)
+		<-channel
+		cancel()
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&p.stops) == 1 }, time.Second, time.Millisecond)
+		assert.Less(t, len(collect(channel)), 99)
 	}
+	assert.Panics(t, func() { New(func() []int { return data }).ToChannel(nil) })
+
 }
 
-func TestE2E(t *testing.T) {
+func TestValidate(t *testing.T) {
 
-	type e2eTest[T any] struct {
-		data     []int
-		sequence func(s Stream[int]) Stream[int]
-		expected T
+	type validateTest struct {
+		data       []int
+		valid      []int
+		violations []int
 	}
 
-	var e2eTestsA = []e2eTest[[]int]{
-		{data: []int{}, expected: []int{}, sequence: func(s Stream[int]) Stream[int] {
-			return s
-		}},
-		{data: []int{1, 2, 3, 4, 5, 6}, expected: []int{4, 8, 12}, sequence: func(s Stream[int]) Stream[int] {
-			return s.Map(func(x int) int { return x * 2 }).
-				Filter(func(x int) bool { return x%4 == 0 })
-		}},
-		{data: []int{1, 2, 3, 4, 5, 6}, expected: []int{4, 8, 12}, sequence: func(s Stream[int]) Stream[int] {
-			return s.Map(func(x int) int { return x * 2 }).
-				Filter(func(x int) bool { return x%4 == 0 })
-		}},
+	var validateTests = []validateTest{
+		{data: []int{}, valid: []int{}, violations: []int{}},
+		{data: []int{1, 2, 3, 4, 5, 6}, valid: []int{2, 4}, violations: []int{1, 3, 5, 6}},
 	}
 
-	for _, test := range e2eTestsA {
+	even := func(x int) error {
+		if x%2 != 0 {
+			return fmt.Errorf("%d is odd", x)
+		}
+		return nil
+	}
+	small := func(x int) error {
+		if x > 5 {
+			return fmt.Errorf("%d is too large", x)
+		}
+		return nil
+	}
+	elements := func(violations []ValidationError[int]) []int {
+		result := make([]int, 0)
+		for _, violation := range violations {
+			result = append(result, violation.Element)
+		}
+		return result
+	}
 
+	for _, test := range validateTests {
 		s1, s2 := New(func() []int { return test.data }),
 			New(func() []int { return test.data }).Parallelize(2)
-
-		assert.ElementsMatch(t, test.expected, test.sequence(s1).Collect())
-		assert.ElementsMatch(t, test.expected, test.sequence(s2).Collect())
+		valid1, violations1 := s1.Validate(even, small)
+		valid2, violations2 := s2.Validate(even, small)
+		assert.ElementsMatch(t, test.violations, elements(violations1))
+		assert.ElementsMatch(t, test.violations, elements(violations2))
+		assert.ElementsMatch(t, test.valid, valid1.Collect())
+		assert.ElementsMatch(t, test.valid, valid2.Collect())
 		assert.True(t, s1.Closed())
 		assert.True(t, s2.Closed())
+		assert.True(t, valid2.Parallel())
+	}
+
+	_, violations := New(func() []int { return []int{7} }).Validate(even, small)
+	assert.Equal(t, "ErrValidation: 7 is odd; 7 is too large", violations[0].Error())
+
+	// The source is released once by Validate and the returned stream keeps the execution mode.
+	p := &pager{}
+	valid, _ := NewStoppable(func() []int { return []int{1, 2, 3, 4} }, p).Limit(2).Validate(even)
+	assert.Equal(t, []int{2}, valid.Limit(1).Collect())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&p.stops))
+	auto, _ := New(func() []int { return []int{2} }).ParallelizeAuto().Validate(even)
+	assert.True(t, auto.ExecutionInfo().Auto)
+
+}
+
+func TestProgress(t *testing.T) {
 
+	data := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	var mux sync.Mutex
+	reported := make([]int, 0)
+	report := func(done int) {
+		mux.Lock()
+		defer mux.Unlock()
+		reported = append(reported, done)
 	}
 
-	var e2eTestsB = []e2eTest[int]{
-		{data: []int{}, expected: 0, sequence: func(s Stream[int]) Stream[int] {
-			return s
-		}},
-		{data: []int{1, 2, 3, 4, 5, 6}, expected: 2, sequence: func(s Stream[int]) Stream[int] {
-			return s.Map(func(x int) int { return x * 2 }).
-				Filter(func(x int) bool { return x%4 == 0 }).Limit(2)
-		}},
-		{data: []int{1, 2, 3, 4, 5, 6}, expected: 3, sequence: func(s Stream[int]) Stream[int] {
-			return s.Map(func(x int) int { return x * 2 }).
-				Filter(func(x int) bool { return x%4 == 0 }).Limit(10)
-		}},
+	s1, s2 := New(func() []int { return data }).Progress(report),
+		New(func() []int { return data }).Parallelize(2).Progress(report)
+
+	s1.Count()
+	assert.Equal(t, data, reported)
+	reported = reported[:0]
+	s2.Count()
+	assert.ElementsMatch(t, data, reported)
+
+}
+
+func TestWithProgressBar(t *testing.T) {
+
+	data := make([]int, 1000)
+
+	for _, parallel := range []bool{false, true} {
+		s := New(func() []int { return data })
+		if parallel {
+			s = s.Parallelize(4)
+		}
+		renders := make([]int, 0)
+		s.WithProgressBar(len(data), func(done, total int) {
+			assert.Equal(t, len(data), total)
+			renders = append(renders, done)
+		}).Count()
+
+		// The first element renders immediately, the rest are throttled except the final count.
+		assert.Less(t, len(renders), len(data))
+		assert.Equal(t, len(data), renders[len(renders)-1])
+		assert.IsIncreasing(t, renders)
 	}
 
-	for _, test := range e2eTestsB {
+}
 
-		s1, s2 := New(func() []int { return test.data }),
-			New(func() []int { return test.data }).Parallelize(2)
+func TestDryRun(t *testing.T) {
 
-		assert.Equal(t, test.expected, test.sequence(s1).Count())
-		assert.Equal(t, test.expected, test.sequence(s2).Count())
-		assert.True(t, s1.Closed())
-		assert.True(t, s2.Closed())
+	type dryRunTest struct {
+		s               Stream[int]
+		expectedErrCode int
+	}
+
+	supplier := func() []int { panic("the supplier should not be invoked") }
+	terminated := New(func() []int { return []int{} })
+	terminated.Count()
+	key := func(x int) string { return fmt.Sprint(x % 3) }
+	less := func(x, y int) bool { return x < y }
+	double := func(x int) int { return 2 * x }
+
+	var dryRunTests = []dryRunTest{
+		{s: New(supplier).Filter(func(x int) bool { return true }).Limit(2)},
+		{s: New(supplier).Parallelize(2).Distinct(func(x int) string { return fmt.Sprint(x) })},
+		{s: New[int](nil)},
+		{s: New(supplier).Map(nil), expectedErrCode: IllegalPlan},
+		{s: New(supplier).Filter(nil).Limit(2), expectedErrCode: IllegalPlan},
+		{s: New(supplier).Limit(2).Parallelize(2)},
+		{s: terminated, expectedErrCode: StreamTerminated},
+		{s: New(supplier).Parallelize(2).Distinct(key).Sorted(less)},
+		{s: New(supplier).Parallelize(2).Sorted(less).Distinct(key)},
+		{s: New(supplier).Distinct(key).Map(double).Sorted(less)},
+		{s: New(supplier).Parallelize(2).Distinct(key).Map(double).Sorted(less), expectedErrCode: IllegalPlan},
+		{s: New(supplier).Parallelize(2).Limit(2).Sorted(less).Map(double), expectedErrCode: IllegalPlan},
+		{s: New(supplier).Parallelize(2).Limit(2).Prioritize(double), expectedErrCode: IllegalPlan},
+		{s: New(supplier).Filter(nil).Sorted(less), expectedErrCode: IllegalPlan},
+		{s: Map(New(supplier).Filter(nil), double), expectedErrCode: IllegalPlan},
+	}
+
+	for _, test := range dryRunTests {
+		err := test.s.DryRun()
+		if test.expectedErrCode == 0 {
+			assert.Nil(t, err)
+			continue
+		}
+		assert.Equal(t, test.expectedErrCode, err.(*streamError).Code())
+		assert.NotEmpty(t, err.Error())
+	}
+
+	err := New(supplier).Peek(func(x int) {}).Filter(nil).DryRun()
+	assert.Equal(t, "ErrIllegalPlan: Illegal operation FILTER at position 1: nil function.", err.Error())
+	err = New(supplier).Parallelize(2).Distinct(key).Map(double).Sorted(less).DryRun()
+	assert.Contains(t, err.Error(), "Illegal operation DISTINCT at position 0")
+	assert.Nil(t, New(supplier).GroupBy(func(x int) string { return "" }).DryRun())
+
+}
+
+func TestConcurrentDerivation(t *testing.T) {
 
+	type concurrentTest struct {
+		derive          func(s Stream[int])
+		expectedErrCode int
+	}
+
+	var concurrentTests = []concurrentTest{
+		{derive: func(s Stream[int]) { s.Map(func(x int) int { return x + 1 }) }, expectedErrCode: StreamClosed},
+		{derive: func(s Stream[int]) { s.Limit(2) }, expectedErrCode: StreamClosed},
+		{derive: func(s Stream[int]) { s.Parallelize(2) }, expectedErrCode: StreamClosed},
+		{derive: func(s Stream[int]) { s.GroupBy(func(x int) string { return fmt.Sprint(x) }) }, expectedErrCode: StreamClosed},
+		{derive: func(s Stream[int]) { s.Count() }, expectedErrCode: StreamTerminated},
+	}
+
+	data := []int{1, 2, 3, 4, 5}
+
+	// Only one of the routines deriving from the same stream should succeed, the rest should fail with an error.
+	for _, test := range concurrentTests {
+		for i := 0; i < 100; i++ {
+			base := New(func() []int { return data }).Filter(func(x int) bool { return x > 1 })
+			var wg sync.WaitGroup
+			var successes int32
+			for j := 0; j < 8; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() {
+						if r := recover(); r != nil {
+							assert.Contains(t, []int{StreamClosed, test.expectedErrCode}, r.(*streamError).Code())
+						}
+					}()
+					test.derive(base)
+					atomic.AddInt32(&successes, 1)
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, int32(1), successes)
+		}
+	}
+
+}
+
+func TestWithCapture(t *testing.T) {
+
+	data := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	inverse := func(x int) int { return 100 / (x % 3) }
+
+	for _, parallel := range []bool{false, true} {
+		for _, max := range []int{1, 10} {
+			s := New(func() []int { return data })
+			if parallel {
+				s = s.Parallelize(2)
+			}
+			func() {
+				defer func() {
+					r := recover()
+					assert.NotNil(t, r)
+					err := r.(*streamError)
+					assert.Equal(t, ElementPanic, err.Code())
+					assert.Contains(t, err.Error(), "integer divide by zero")
+					if max == 1 {
+						assert.Len(t, err.Elements(), 1)
+					} else {
+						assert.ElementsMatch(t, []any{3, 6}, err.Elements())
+					}
+				}()
+				s.WithCapture(max).Map(func(x int) int { return x * 1 }).Map(inverse).Count()
+			}()
+		}
+	}
+
+	assert.ElementsMatch(t, []int{50, 100}, New(func() []int { return []int{1, 2} }).WithCapture(1).Map(inverse).Collect())
+
+	// Operations keep their exhausted state, Limit stops the source early.
+	p := &pager{}
+	assert.Equal(t, []int{1, 2}, NewStoppable(func() []int { return data }, p).WithCapture(1).Limit(2).Collect())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&p.stops))
+	operations, _ := New(func() []int { return data }).WithCapture(1).Limit(2).(*stream[int]).evaluation()
+	assert.Len(t, operations, 1)
+	assert.NotNil(t, operations[0].exhausted)
+
+	// The failure of TryMap is not captured.
+	failure := errors.New("failure")
+	_, err := New(func() []int { return data }).WithCapture(10).TryMap(func(x int) (int, error) {
+		if x == 4 {
+			return 0, failure
+		}
+		return x, nil
+	}).CollectErr()
+	assert.Equal(t, failure, err)
+
+	assert.True(t, New(func() []int { return data }).ParallelizeAuto().WithCapture(1).ExecutionInfo().Auto)
+
+}
+
+func TestParallelizeAuto(t *testing.T) {
+
+	data := make([]int, 500)
+	for i := range data {
+		data[i] = i + 1
+	}
+
+	cheap := func(x int) int { return x }
+	expensive := func(x int) int {
+		time.Sleep(50 * time.Microsecond)
+		return x
+	}
+
+	for _, f := range []func(int) int{cheap, expensive} {
+		even := func(x int) bool { return x%2 == 0 }
+		assert.Equal(t, 250, New(func() []int { return data }).ParallelizeAuto().Map(f).Filter(even).Count())
+		assert.Equal(t, 125250, New(func() []int { return data }).ParallelizeAuto().Map(f).Reduce(func(x, y int) int { return x + y }))
+		assert.ElementsMatch(t, data, New(func() []int { return data }).ParallelizeAuto().Map(f).Collect())
+		assert.Len(t, New(func() []int { return data }).ParallelizeAuto().Map(f).Limit(100).Collect(), 100)
+
+		var counter int32
+		New(func() []int { return data }).ParallelizeAuto().Map(f).ForEach(func(x int) { atomic.AddInt32(&counter, 1) })
+		assert.Equal(t, int32(500), counter)
+	}
+
+	s := New(func() []int { return []int{} }).ParallelizeAuto()
+	assert.False(t, s.Parallel())
+	assert.Equal(t, 0, s.Reduce(func(x, y int) int { return x + y }))
+
+}
+
+func TestParallelizeWith(t *testing.T) {
+
+	data := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	even := func(x int) bool { return x%2 == 0 }
+
+	for _, profile := range []Profile{CPUBound(), IOBound(1), IOBound(3), IOBound(20)} {
+		assert.True(t, New(func() []int { return data }).ParallelizeWith(profile).Parallel())
+		assert.ElementsMatch(t, []int{2, 4, 6, 8, 10}, New(func() []int { return data }).ParallelizeWith(profile).Filter(even).Collect())
+		assert.Equal(t, 5, New(func() []int { return data }).ParallelizeWith(profile).Filter(even).Count())
+		assert.Equal(t, 55, New(func() []int { return data }).ParallelizeWith(profile).Reduce(func(x, y int) int { return x + y }))
+		assert.Equal(t, 3, New(func() []int { return data }).ParallelizeWith(profile).Limit(3).Count())
+	}
+
+	// Slow elements should be spread across the in flight routines.
+	var inFlight, peak int32
+	New(func() []int { return data }).ParallelizeWith(IOBound(5)).ForEach(func(x int) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	})
+	assert.LessOrEqual(t, peak, int32(5))
+	assert.Greater(t, peak, int32(1))
+
+	assert.Equal(t, "IOBound", IOBound(2).String())
+	assert.Panics(t, func() { IOBound(0) })
+	assert.Panics(t, func() { New(func() []int { return data }).ParallelizeWith(Profile{}) })
+
+}
+
+func TestParallelPanic(t *testing.T) {
+
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+	failure := errors.New("failed")
+
+	// The panic of a routine is raised by the terminal operation and can be recovered by its caller.
+	for _, s := range []Stream[int]{New(func() []int { return data }).Parallelize(4), New(func() []int { return data }).ParallelizeWith(IOBound(4))} {
+		assert.PanicsWithValue(t, failure, func() {
+			s.ForEach(func(x int) {
+				if x == 10 {
+					panic(failure)
+				}
+			})
+		})
+	}
+
+	// Routines with per element dispatch stop taking elements once a routine has panicked.
+	var processed int32
+	assert.Panics(t, func() {
+		New(func() []int { return data }).ParallelizeWith(IOBound(2)).ForEach(func(x int) {
+			atomic.AddInt32(&processed, 1)
+			if x == 0 {
+				panic(failure)
+			}
+			time.Sleep(time.Millisecond)
+		})
+	})
+	assert.Less(t, atomic.LoadInt32(&processed), int32(len(data)))
+
+	// No routine outlives the terminal operation.
+	routines := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		assert.Panics(t, func() {
+			New(func() []int { return data }).Parallelize(8).Map(func(x int) int {
+				if x%2 == 0 {
+					panic(failure)
+				}
+				return x
+			}).Collect()
+		})
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), routines)
+
+}
+
+// labelled an element describing itself for the error of an operation that panics on it.
+type labelled int
+
+// String returns a description of the element.
+func (x labelled) String() string {
+	return fmt.Sprintf("label-%d", int(x))
+}
+
+func TestOperatorPanic(t *testing.T) {
+
+	failure := errors.New("failed")
+	supplier := func() []labelled { return []labelled{1, 2, 3, 4} }
+	failAt := func(x labelled) labelled {
+		if x == 3 {
+			panic(failure)
+		}
+		return x
+	}
+
+	// The panic of the function of an operation is raised as an error naming the operation and its position.
+	for _, s := range []Stream[labelled]{New(supplier), New(supplier).Parallelize(2)} {
+		func() {
+			defer func() {
+				r := recover()
+				err, ok := r.(Error)
+				assert.True(t, ok)
+				assert.Equal(t, OperatorPanic, err.Code())
+				assert.Equal(t, "ErrOperatorPanic: Operation MAP at position 1 panicked on element label-3: failed.", err.Error())
+				assert.True(t, errors.Is(err, failure))
+			}()
+			s.Filter(func(x labelled) bool { return x > 1 }).Map(failAt).Limit(10).Collect()
+		}()
 	}
 
+	// Elements that do not describe themselves are left out, panics with values other than errors are described.
+	func() {
+		defer func() {
+			err := recover().(Error)
+			assert.Equal(t, "ErrOperatorPanic: Operation FILTER at position 0 panicked: boom.", err.Error())
+		}()
+		New(func() []int { return []int{1} }).Filter(func(x int) bool { panic("boom") }).Count()
+	}()
+
+	// Errors raised by the package are raised unchanged.
+	illegal := errIllegalArgument("1", "test")
+	assert.PanicsWithValue(t, illegal, func() {
+		New(func() []int { return []int{1} }).Peek(func(x int) { panic(illegal) }).Count()
+	})
+
+}
+
+func TestSequential(t *testing.T) {
+
+	data := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	supplier := func() []int { return data }
+
+	// The last call to Parallelize wins.
+	s := New(supplier).Parallelize(2).Parallelize(5)
+	assert.Equal(t, ExecutionInfo{Parallel: true, Workers: 5}, s.ExecutionInfo())
+	s = New(supplier).ParallelizeWith(IOBound(8)).Parallelize(3)
+	assert.Equal(t, ExecutionInfo{Parallel: true, Workers: 3}, s.ExecutionInfo())
+
+	// Sequential switches back to a single routine.
+	s = New(supplier).Parallelize(4).Filter(func(x int) bool { return x%2 == 0 }).Sequential()
+	assert.False(t, s.Parallel())
+	assert.Equal(t, ExecutionInfo{Workers: 1}, s.ExecutionInfo())
+	assert.Equal(t, []int{2, 4, 6, 8, 10}, s.Collect())
+	assert.Equal(t, []int{1, 2, 3}, New(supplier).Parallelize(4).Limit(3).Sequential().Collect())
+	assert.Equal(t, ExecutionInfo{Workers: 1}, New(supplier).ParallelizeAuto().Sequential().ExecutionInfo())
+	assert.True(t, New(supplier).Sequential().Parallelize(2).Parallel())
+
+	grouped := New(supplier).GroupBy(func(x int) string { return fmt.Sprint(x % 2) }).Parallelize(2).Sequential()
+	assert.False(t, grouped.Parallel())
+	assert.Equal(t, map[string]int{"0": 5, "1": 5}, grouped.Count())
+	partitioned := New(supplier).Partition(func(x int) []int { return []int{x} }).Parallelize(2).Sequential()
+	assert.False(t, partitioned.Parallel())
+	assert.Equal(t, 10, partitioned.Count())
+
+	// Invalid levels of parallelism can be checked without recovering from a panic.
+	err := CheckParallelism(1)
+	assert.Equal(t, IllegalConfig, err.(Error).Code())
+	assert.Nil(t, CheckParallelism(2))
+	defer func() {
+		r := recover().(Error)
+		assert.Equal(t, err.Error(), r.Error())
+	}()
+	New(supplier).Parallelize(1)
+
+}
+
+func TestCollectLimited(t *testing.T) {
+
+	type collectLimitedTest struct {
+		data            []int
+		max             int
+		expectedErrCode int
+	}
+
+	var collectLimitedTests = []collectLimitedTest{
+		{data: []int{}, max: 0},
+		{data: []int{1, 2, 3, 4}, max: 4},
+		{data: []int{1, 2, 3, 4}, max: 10},
+		{data: []int{1, 2, 3, 4}, max: 3, expectedErrCode: CapacityExceeded},
+		{data: []int{1, 2, 3, 4}, max: 0, expectedErrCode: CapacityExceeded},
+	}
+
+	for _, test := range collectLimitedTests {
+		s1, s2 := New(func() []int { return test.data }),
+			New(func() []int { return test.data }).Parallelize(2)
+		for _, s := range []Stream[int]{s1, s2} {
+			results, err := s.CollectLimited(test.max)
+			if test.expectedErrCode == 0 {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, test.data, results)
+			} else {
+				assert.Nil(t, results)
+				assert.Equal(t, test.expectedErrCode, err.(*streamError).Code())
+			}
+			assert.True(t, s.Terminated())
+		}
+	}
+
+	// Evaluation should stop shortly after the maximum is exceeded.
+	var processed int32
+	_, err := New(func() []int { return make([]int, 1000) }).Peek(func(x int) { atomic.AddInt32(&processed, 1) }).CollectLimited(10)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(11), processed)
+
+}
+
+func TestOpen(t *testing.T) {
+
+	type openTest struct {
+		a        []int
+		b        []int
+		expected []int
+	}
+
+	openTests := []openTest{
+		{a: []int{}, b: []int{}, expected: []int{}},
+		{a: []int{1, 3, 5}, b: []int{}, expected: []int{1, 3, 5}},
+		{a: []int{1, 3, 5, 7}, b: []int{2, 4, 6}, expected: []int{1, 2, 3, 4, 5, 6, 7}},
+	}
+
+	merge := func(a, b Stream[int]) []int {
+		c, d := a.Open(), b.Open()
+		result := make([]int, 0)
+		x, xOk := c.Next()
+		y, yOk := d.Next()
+		for xOk || yOk {
+			if !yOk || (xOk && x <= y) {
+				result = append(result, x)
+				x, xOk = c.Next()
+			} else {
+				result = append(result, y)
+				y, yOk = d.Next()
+			}
+		}
+		return result
+	}
+
+	for _, test := range openTests {
+		a := New(func() []int { return test.a })
+		b := New(func() []int { return test.b }).Parallelize(2)
+		assert.Equal(t, test.expected, merge(a, b))
+		assert.True(t, a.Terminated())
+		assert.True(t, b.Terminated())
+	}
+
+	pulled := false
+	cursor := New(func() []int { pulled = true; return []int{1, 2, 3, 4} }).Filter(func(x int) bool { return x%2 == 0 }).Open()
+	assert.False(t, pulled)
+	x, ok := cursor.Next()
+	assert.Equal(t, 2, x)
+	assert.True(t, ok)
+	cursor.Close()
+	_, ok = cursor.Next()
+	assert.False(t, ok)
+
+	// The elements of an open channel are pulled as they are consumed, closing the cursor stops the source.
+	channel := make(chan int, 3)
+	channel <- 1
+	channel <- 2
+	channel <- 3
+	s, source := FromChannel(channel)
+	cursor = s.Map(func(x int) int { return x * 10 }).Open()
+	for _, expected := range []int{10, 20, 30} {
+		x, ok = cursor.Next()
+		assert.Equal(t, expected, x)
+		assert.True(t, ok)
+	}
+	cursor.Close()
+	select {
+	case <-source.stop:
+	default:
+		assert.Fail(t, "source not stopped")
+	}
+
+	limited := make(chan int, 3)
+	limited <- 1
+	limited <- 2
+	s, _ = FromChannel(limited)
+	cursor = s.Limit(2).Open()
+	cursor.Next()
+	cursor.Next()
+	_, ok = cursor.Next()
+	assert.False(t, ok)
+
+}
+
+type foldHasher struct{}
+
+func (foldHasher) Hash(k string) uint64 { return uint64(len(k)) }
+
+func (foldHasher) Equal(x, y string) bool { return strings.EqualFold(x, y) }
+
+func TestWithHasher(t *testing.T) {
+
+	type withHasherTest struct {
+		data     []string
+		hasher   Hasher[string]
+		distinct []string
+		groups   map[string]int
+	}
+
+	withHasherTests := []withHasherTest{
+		{data: []string{}, hasher: MaphashHasher(), distinct: []string{}, groups: map[string]int{}},
+		{data: []string{"a", "b", "a", "c"}, hasher: MaphashHasher(), distinct: []string{"a", "b", "c"}, groups: map[string]int{"a": 2, "b": 1, "c": 1}},
+		{data: []string{"a", "A", "bb", "Bb"}, hasher: foldHasher{}, distinct: []string{"a", "bb"}, groups: map[string]int{"a": 2, "bb": 2}},
+	}
+
+	identity := func(x string) string { return x }
+
+	for _, test := range withHasherTests {
+		a := New(func() []string { return test.data }).WithHasher(test.hasher).Distinct(identity).Collect()
+		b := New(func() []string { return test.data }).WithHasher(test.hasher).Parallelize(2).Distinct(identity).Collect()
+		assert.Equal(t, test.distinct, a)
+		assert.Len(t, b, len(test.distinct))
+
+		c := New(func() []string { return test.data }).WithHasher(test.hasher).GroupBy(identity).Count()
+		d := New(func() []string { return test.data }).WithHasher(test.hasher).Parallelize(2).GroupBy(identity).Count()
+		assert.Equal(t, test.groups, c)
+		assert.Equal(t, test.groups, d)
+	}
+
+	assert.Panics(t, func() { New(func() []string { return nil }).WithHasher(nil) })
+
+}
+
+func TestForEachWhile(t *testing.T) {
+
+	type forEachWhileTest struct {
+		data     []int
+		until    int
+		expected []int
+	}
+
+	forEachWhileTests := []forEachWhileTest{
+		{data: []int{}, until: 3, expected: []int{}},
+		{data: []int{1, 2, 3, 4, 5}, until: 3, expected: []int{1, 2, 3}},
+		{data: []int{1, 2, 3, 4, 5}, until: 10, expected: []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, test := range forEachWhileTests {
+		visited := make([]int, 0)
+		New(func() []int { return test.data }).ForEachWhile(func(x int) bool {
+			visited = append(visited, x)
+			return x < test.until
+		})
+		assert.Equal(t, test.expected, visited)
+	}
+
+	// Parallel routines stop taking elements once any of them is stopped.
+	data := make([]int, 10000)
+	for i := range data {
+		data[i] = i
+	}
+	var visited int32
+	New(func() []int { return data }).Parallelize(4).ForEachWhile(func(x int) bool {
+		atomic.AddInt32(&visited, 1)
+		return x != 0
+	})
+	assert.Less(t, int(visited), len(data))
+
+	var all int32
+	New(func() []int { return data }).Parallelize(4).ForEachWhile(func(x int) bool {
+		atomic.AddInt32(&all, 1)
+		return true
+	})
+	assert.Equal(t, int32(len(data)), all)
+
+}
+
+func TestMatch(t *testing.T) {
+
+	type matchTest struct {
+		data                []int
+		predicate           func(x int) bool
+		any, all, noneMatch bool
+	}
+
+	even := func(x int) bool { return x%2 == 0 }
+	var matchTests = []matchTest{
+		{data: []int{}, predicate: even, any: false, all: true, noneMatch: true},
+		{data: []int{2, 4, 6}, predicate: even, any: true, all: true, noneMatch: false},
+		{data: []int{1, 2, 3}, predicate: even, any: true, all: false, noneMatch: false},
+		{data: []int{1, 3, 5}, predicate: even, any: false, all: false, noneMatch: true},
+	}
+
+	for _, test := range matchTests {
+		supplier := func() []int { return test.data }
+		for _, s := range []func() Stream[int]{func() Stream[int] { return New(supplier) }, func() Stream[int] { return New(supplier).Parallelize(2) }} {
+			assert.Equal(t, test.any, s().AnyMatch(test.predicate))
+			assert.Equal(t, test.all, s().AllMatch(test.predicate))
+			assert.Equal(t, test.noneMatch, s().NoneMatch(test.predicate))
+		}
+	}
+
+	// Evaluation stops at the first deciding element.
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	var calls int32
+	s := New(func() []int { return data }).Peek(func(x int) { atomic.AddInt32(&calls, 1) })
+	assert.True(t, s.AnyMatch(func(x int) bool { return x == 2 }))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.True(t, s.Terminated())
+
+	calls = 0
+	assert.False(t, New(func() []int { return data }).Peek(func(x int) { atomic.AddInt32(&calls, 1) }).AllMatch(func(x int) bool { return x < 1 }))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Routines of a parallel stream stop taking elements once the answer is known.
+	calls = 0
+	assert.True(t, New(func() []int { return data }).ParallelizeWith(IOBound(4)).Peek(func(x int) { atomic.AddInt32(&calls, 1) }).
+		AnyMatch(func(x int) bool { return x == 0 }))
+	assert.Less(t, atomic.LoadInt32(&calls), int32(len(data)))
+
+}
+
+func TestLimitCancel(t *testing.T) {
+
+	data := make([]int, 100000)
+	for i := range data {
+		data[i] = i
+	}
+
+	// Routines abandon their partitions once the limit is reached, so the operations before it are applied to few elements.
+	var calls int64
+	square := func(x int) int {
+		atomic.AddInt64(&calls, 1)
+		return x * x
+	}
+	assert.Equal(t, 5, len(New(func() []int { return data }).Parallelize(4).Map(square).Limit(5).Collect()))
+	assert.Less(t, atomic.LoadInt64(&calls), int64(1000))
+
+	atomic.StoreInt64(&calls, 0)
+	assert.Equal(t, 10, New(func() []int { return data }).ParallelizeWith(IOBound(8)).Map(square).Limit(10).Count())
+	assert.Less(t, atomic.LoadInt64(&calls), int64(1000))
+
+	atomic.StoreInt64(&calls, 0)
+	assert.Equal(t, 0, New(func() []int { return data }).Parallelize(4).Map(square).Limit(0).Count())
+	assert.Equal(t, int64(0), atomic.LoadInt64(&calls))
+
+	atomic.StoreInt64(&calls, 0)
+	New(func() []int { return data }).Parallelize(4).Map(square).LimitUntil(func(x int) bool { return x > 100 }).Count()
+	assert.Less(t, atomic.LoadInt64(&calls), int64(len(data)))
+
+	// Operations after the limit are unaffected.
+	assert.ElementsMatch(t, []int{0, 1, 2}, New(func() []int { return data[:3] }).Parallelize(2).Limit(3).Map(func(x int) int { return x }).Collect())
+
+}
+
+func TestLimitUntil(t *testing.T) {
+
+	type limitUntilTest struct {
+		data     []int
+		expected []int
+	}
+
+	limitUntilTests := []limitUntilTest{
+		{data: []int{}, expected: []int{}},
+		{data: []int{1, 2, 3}, expected: []int{1, 2, 3}},
+		{data: []int{1, 2, 10, 3, 4}, expected: []int{1, 2}},
+		{data: []int{10, 1, 2}, expected: []int{}},
+	}
+
+	for _, test := range limitUntilTests {
+		a := New(func() []int { return test.data }).LimitUntil(func(x int) bool { return x >= 10 }).Collect()
+		b := New(func() []int { return test.data }).Parallelize(2).LimitUntil(func(x int) bool { return x >= 10 }).Collect()
+		assert.Equal(t, test.expected, a)
+		assert.Subset(t, test.data, b)
+		assert.NotContains(t, b, 10)
+	}
+
+}
+
+func TestLimitDuration(t *testing.T) {
+
+	data := []int{1, 2, 3, 4, 5}
+	for _, s := range []Stream[int]{New(func() []int { return data }), New(func() []int { return data }).Parallelize(2)} {
+		result := s.LimitDuration(15 * time.Millisecond).Peek(func(x int) { time.Sleep(10 * time.Millisecond) }).Collect()
+		assert.NotEmpty(t, result)
+		assert.Less(t, len(result), len(data))
+	}
+
+	assert.Equal(t, data, New(func() []int { return data }).LimitDuration(time.Hour).Collect())
+	assert.Empty(t, New(func() []int { return data }).LimitDuration(0).Collect())
+	assert.Panics(t, func() { New(func() []int { return data }).LimitDuration(-1) })
+
+	// Once the duration has elapsed the operations before LimitDuration are no longer applied.
+	many := make([]int, 100)
+	for _, s := range []Stream[int]{New(func() []int { return many }), New(func() []int { return many }).Parallelize(2)} {
+		var calls int32
+		s.Peek(func(x int) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(time.Millisecond)
+		}).LimitDuration(10 * time.Millisecond).Count()
+		assert.Less(t, atomic.LoadInt32(&calls), int32(50))
+	}
+
+	// The clock starts with the evaluation rather than when the first element reaches the operator.
+	slow := New(func() []int { return data }).Peek(func(x int) { time.Sleep(10 * time.Millisecond) }).Filter(func(x int) bool { return x > 2 })
+	assert.Empty(t, slow.LimitDuration(15*time.Millisecond).Collect())
+
+}
+
+func TestSummary(t *testing.T) {
+
+	type summaryTest struct {
+		s        Stream[int]
+		terminal func(s Stream[int])
+		expected Summary
+	}
+
+	data := []int{1, 2, 3, 4, 5, 6}
+	even := func(x int) bool { return x%2 == 0 }
+
+	summaryTests := []summaryTest{
+		{s: New(func() []int { return data }).Filter(even), terminal: func(s Stream[int]) { s.Collect() },
+			expected: Summary{Read: 6, Emitted: 3, Workers: 1, PeakBuffer: 6}},
+		{s: New(func() []int { return data }).Parallelize(3).Filter(even), terminal: func(s Stream[int]) { s.Count() },
+			expected: Summary{Read: 6, Emitted: 3, Workers: 3, PeakBuffer: 6}},
+		{s: New(func() []int { return data }).Limit(2), terminal: func(s Stream[int]) { s.ForEach(func(x int) {}) },
+			expected: Summary{Read: 6, Emitted: 2, Workers: 1, PeakBuffer: 6}},
+		{s: New(func() []int { return data }), terminal: func(s Stream[int]) { s.ForEachWhile(func(x int) bool { return x < 2 }) },
+			expected: Summary{Read: 2, Emitted: 2, Workers: 1, PeakBuffer: 6}},
+		{s: New(func() []int { return data }).ParallelizeAuto(), terminal: func(s Stream[int]) { s.Reduce(func(x, y int) int { return x + y }) },
+			expected: Summary{Read: 6, Emitted: 6, Workers: 1, PeakBuffer: 6}},
+	}
+
+	for _, test := range summaryTests {
+		assert.Equal(t, Summary{}, test.s.Summary())
+		test.terminal(test.s)
+		summary := test.s.Summary()
+		assert.Greater(t, summary.Duration, time.Duration(0))
+		summary.Duration = 0
+		assert.Equal(t, test.expected, summary)
+	}
+
+	s := New(func() []int { return data })
+	cursor := s.Open()
+	cursor.Next()
+	assert.Equal(t, 1, s.Summary().Read)
+	assert.Equal(t, time.Duration(0), s.Summary().Duration)
+	cursor.Close()
+	assert.Greater(t, s.Summary().Duration, time.Duration(0))
+
+}
+
+func TestExecutionInfo(t *testing.T) {
+
+	type executionInfoTest struct {
+		info     ExecutionInfo
+		expected ExecutionInfo
+	}
+
+	supplier := func() []int { return []int{1, 2, 3} }
+	key := func(x int) string { return fmt.Sprint(x) }
+	partition := func(x int) []int { return []int{x} }
+
+	executionInfoTests := []executionInfoTest{
+		{info: New(supplier).ExecutionInfo(), expected: ExecutionInfo{Workers: 1}},
+		{info: New(supplier).Parallelize(3).ExecutionInfo(), expected: ExecutionInfo{Parallel: true, Workers: 3}},
+		{info: New(supplier).ParallelizeWith(IOBound(8)).ExecutionInfo(), expected: ExecutionInfo{Parallel: true, Workers: 8, PerElement: true}},
+		{info: New(supplier).ParallelizeAuto().ExecutionInfo(), expected: ExecutionInfo{Auto: true, Workers: runtime.NumCPU()}},
+		{info: New(supplier).GroupBy(key).ExecutionInfo(), expected: ExecutionInfo{Workers: 1}},
+		{info: New(supplier).GroupBy(key).Parallelize(2).ExecutionInfo(), expected: ExecutionInfo{Parallel: true, Workers: 2}},
+		{info: New(supplier).Parallelize(2).GroupBy(key).ExecutionInfo(), expected: ExecutionInfo{Parallel: true, Workers: 2}},
+		{info: New(supplier).Partition(partition).ExecutionInfo(), expected: ExecutionInfo{Workers: 1}},
+		{info: New(supplier).Partition(partition).Parallelize(2).ExecutionInfo(), expected: ExecutionInfo{Parallel: true, Workers: 2}},
+		{info: NewRecordStream(func() []Record { return nil }).Parallelize(2).ExecutionInfo(), expected: ExecutionInfo{Parallel: true, Workers: 2}},
+	}
+
+	for _, test := range executionInfoTests {
+		assert.Equal(t, test.expected, test.info)
+	}
+
+}
+
+func TestParallelizeOrder(t *testing.T) {
+
+	data := []int{1, 2, 2, 3, 3, 3, 4, 5, 6, 7, 8, 9}
+	supplier := func() []int { return data }
+	hash := func(x int) string { return fmt.Sprint(x) }
+	even := func(x int) bool { return x%2 == 0 }
+
+	// Stateful operations added before Parallelize are safe to evaluate in parallel.
+	type parallelizeTest struct {
+		a, b     Stream[int]
+		expected int
+	}
+
+	parallelizeTests := []parallelizeTest{
+		{a: New(supplier).Distinct(hash).Parallelize(3), b: New(supplier).Parallelize(3).Distinct(hash), expected: 9},
+		{a: New(supplier).Limit(5).Parallelize(3), b: New(supplier).Parallelize(3).Limit(5), expected: 5},
+		{a: New(supplier).Skip(5).Parallelize(3), b: New(supplier).Parallelize(3).Skip(5), expected: 7},
+		{a: New(supplier).Filter(even).Limit(2).ParallelizeWith(IOBound(4)), b: New(supplier).ParallelizeWith(IOBound(4)).Filter(even).Limit(2), expected: 2},
+		{a: New(supplier).LimitUntil(func(x int) bool { return x > 100 }).Parallelize(2), b: New(supplier).Parallelize(2).LimitUntil(func(x int) bool { return x > 100 }), expected: 12},
+	}
+
+	for _, test := range parallelizeTests {
+		assert.Nil(t, test.a.DryRun())
+		assert.Equal(t, test.expected, test.a.Count())
+		assert.Equal(t, test.expected, test.b.Count())
+	}
+
+	// Parallelize preserves the distinct flag and is idempotent.
+	a := New(supplier).Distinct(hash).Parallelize(2).Parallelize(2)
+	assert.True(t, a.(*stream[int]).distinct)
+	assert.Equal(t, ExecutionInfo{Parallel: true, Workers: 2}, a.ExecutionInfo())
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, a.Collect())
+
+	// Grouped streams.
+	key := func(x int) string { return fmt.Sprint(x % 3) }
+	expected := map[string]int{"0": 5, "1": 3, "2": 4}
+	assert.Equal(t, expected, New(supplier).Parallelize(2).GroupBy(key).Count())
+	assert.Equal(t, expected, New(supplier).GroupBy(key).Parallelize(2).Count())
+	assert.Equal(t, expected, New(supplier).GroupBy(key).Parallelize(2).Parallelize(3).Count())
+
+	// Partitioned streams.
+	partition := func(x int) []int { return []int{x, x} }
+	b := New(supplier).Partition(partition).Distinct(hash).Parallelize(2)
+	assert.True(t, b.(*partitionedStream[int]).distinct)
+	assert.Equal(t, 9, b.Count())
+	assert.Equal(t, 9, New(supplier).Partition(partition).Parallelize(2).Distinct(hash).Count())
+	assert.Equal(t, 3, New(supplier).Partition(partition).Limit(3).Parallelize(2).Count())
+
+	// Flattening partitions preserves distinct elements.
+	c := New(supplier).Partition(partition).Distinct(hash).Parallelize(2).FlatMap()
+	assert.True(t, c.(*stream[int]).distinct)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, c.Collect())
+
+}
+
+func TestBucketize(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5, 6, 7} }
+
+	type bucketizeTest struct {
+		s          Stream[int]
+		n          int
+		roundRobin bool
+		expected   [][]int
+	}
+
+	bucketizeTests := []bucketizeTest{
+		{s: New(supplier), n: 3, expected: [][]int{{1, 2, 3}, {4, 5}, {6, 7}}},
+		{s: New(supplier), n: 3, roundRobin: true, expected: [][]int{{1, 4, 7}, {2, 5}, {3, 6}}},
+		{s: New(supplier), n: 1, expected: [][]int{{1, 2, 3, 4, 5, 6, 7}}},
+		{s: New(supplier).Limit(2), n: 4, expected: [][]int{{1}, {2}, {}, {}}},
+		{s: New(supplier).Limit(2), n: 4, roundRobin: true, expected: [][]int{{1}, {2}, {}, {}}},
+		{s: New(func() []int { return []int{} }), n: 2, expected: [][]int{{}, {}}},
+	}
+
+	for _, test := range bucketizeTests {
+		if test.roundRobin {
+			assert.Equal(t, test.expected, test.s.BucketizeRoundRobin(test.n))
+		} else {
+			assert.Equal(t, test.expected, test.s.Bucketize(test.n))
+		}
+	}
+
+	buckets := New(supplier).Parallelize(2).Bucketize(3)
+	assert.Equal(t, []int{3, 2, 2}, []int{len(buckets[0]), len(buckets[1]), len(buckets[2])})
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7}, append(append(buckets[0], buckets[1]...), buckets[2]...))
+
+	assert.Panics(t, func() { New(supplier).Bucketize(0) })
+	assert.Panics(t, func() { New(supplier).BucketizeRoundRobin(-1) })
+
+}
+
+func TestRepartition(t *testing.T) {
+
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	supplier := func() []int { return data }
+	selective := func(x int) bool { return x >= 900 }
+
+	type repartitionTest struct {
+		s        Stream[int]
+		info     ExecutionInfo
+		expected int
+	}
+
+	repartitionTests := []repartitionTest{
+		{s: New(supplier).Filter(selective).Repartition(4), info: ExecutionInfo{Parallel: true, Workers: 4}, expected: 100},
+		{s: New(supplier).Parallelize(2).Filter(selective).Repartition(8), info: ExecutionInfo{Parallel: true, Workers: 8}, expected: 100},
+		{s: New(supplier).Parallelize(4).Filter(selective).Repartition(1), info: ExecutionInfo{Workers: 1}, expected: 100},
+		{s: New(supplier).Limit(10).Repartition(3), info: ExecutionInfo{Parallel: true, Workers: 3}, expected: 10},
+	}
+
+	for _, test := range repartitionTests {
+		assert.Equal(t, test.info, test.s.ExecutionInfo())
+		assert.Equal(t, test.expected, test.s.Map(func(x int) int { return x * 2 }).Count())
+	}
+
+	// Operations after a repartition are evaluated in parallel.
+	var mux sync.Mutex
+	seen := make(map[int]bool)
+	s := New(supplier).Distinct(func(x int) string { return fmt.Sprint(x) }).Filter(selective).Repartition(4)
+	assert.True(t, s.(*stream[int]).distinct)
+	results := s.Peek(func(x int) {
+		mux.Lock()
+		defer mux.Unlock()
+		seen[x] = true
+	}).Limit(50).Collect()
+	assert.Equal(t, 50, len(results))
+	assert.GreaterOrEqual(t, len(seen), 50)
+
+	assert.Panics(t, func() { New(supplier).Repartition(0) })
+
+}
+
+// sequenceScheduler runs partitions one at a time in the given order.
+type sequenceScheduler struct {
+	order  []int
+	next   int
+	merges int
+	cond   *sync.Cond
+}
+
+func newSequenceScheduler(order ...int) *sequenceScheduler {
+	return &sequenceScheduler{order: order, cond: sync.NewCond(&sync.Mutex{})}
+}
+
+func (s *sequenceScheduler) BeforePartitionStart(partition int) {
+	s.cond.L.Lock()
+	defer s.cond.L.Unlock()
+	for s.order[s.next] != partition {
+		s.cond.Wait()
+	}
+}
+
+func (s *sequenceScheduler) AfterPartitionEnd(partition int) {
+	s.cond.L.Lock()
+	defer s.cond.L.Unlock()
+	s.next++
+	s.cond.Broadcast()
+}
+
+func (s *sequenceScheduler) BeforeMerge() {
+	s.cond.L.Lock()
+	defer s.cond.L.Unlock()
+	s.merges++
+}
+
+func TestWithScheduler(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3, 4, 5, 6} }
+
+	type withSchedulerTest struct {
+		s        func(scheduler Scheduler) Stream[int]
+		order    []int
+		expected []int
+	}
+
+	// The routine evaluating the last partition wins the race for the limit.
+	withSchedulerTests := []withSchedulerTest{
+		{
+			s: func(scheduler Scheduler) Stream[int] {
+				return New(supplier).Parallelize(2).WithScheduler(scheduler).Limit(3)
+			},
+			order:    []int{0, 1},
+			expected: []int{1, 2, 3},
+		},
+		{
+			s: func(scheduler Scheduler) Stream[int] {
+				return New(supplier).Parallelize(2).WithScheduler(scheduler).Limit(3)
+			},
+			order:    []int{1, 0},
+			expected: []int{4, 5, 6},
+		},
+		{
+			s: func(scheduler Scheduler) Stream[int] {
+				return New(supplier).WithScheduler(scheduler).Parallelize(3).Limit(2)
+			},
+			order:    []int{2, 1, 0},
+			expected: []int{5, 6},
+		},
+		{
+			s: func(scheduler Scheduler) Stream[int] {
+				return New(supplier).WithScheduler(scheduler).ParallelizeWith(IOBound(6)).Skip(4)
+			},
+			order:    []int{5, 4, 3, 2, 1, 0},
+			expected: []int{1, 2},
+		},
+	}
+
+	for i := 0; i < 100; i++ {
+		for _, test := range withSchedulerTests {
+			scheduler := newSequenceScheduler(test.order...)
+			assert.ElementsMatch(t, test.expected, test.s(scheduler).Collect())
+			assert.Equal(t, 1, scheduler.merges)
+		}
+	}
+
+	// Sequential streams have no scheduling points.
+	scheduler := newSequenceScheduler()
+	assert.Equal(t, 6, New(supplier).WithScheduler(scheduler).Count())
+	assert.Equal(t, 0, scheduler.merges)
+
+	assert.Panics(t, func() { New(supplier).WithScheduler(nil) })
+
+}
+
+func TestSupplierCalls(t *testing.T) {
+
+	var calls int32
+	supplier := func() []int {
+		atomic.AddInt32(&calls, 1)
+		return []int{1, 2, 3, 4, 5, 6}
+	}
+	key := func(x int) string { return fmt.Sprint(x % 2) }
+	split := func(x int) []int { return []int{x, x} }
+	sum := func(x, y int) int { return x + y }
+
+	type supplierCallsTest struct {
+		name     string
+		evaluate func()
+	}
+
+	supplierCallsTests := []supplierCallsTest{
+		{name: "Collect", evaluate: func() { New(supplier).Filter(func(x int) bool { return x > 2 }).Collect() }},
+		{name: "ParallelCollect", evaluate: func() { New(supplier).Parallelize(2).Collect() }},
+		{name: "AutoCount", evaluate: func() { New(supplier).ParallelizeAuto().Count() }},
+		{name: "ForEachWhile", evaluate: func() { New(supplier).ForEachWhile(func(x int) bool { return x < 3 }) }},
+		{name: "CollectLimited", evaluate: func() { New(supplier).CollectLimited(2) }},
+		{name: "Open", evaluate: func() { c := New(supplier).Open(); c.Next(); c.Close() }},
+		{name: "Validate", evaluate: func() { s, _ := New(supplier).Validate(); s.Count() }},
+		{name: "Repartition", evaluate: func() { New(supplier).Repartition(3).Reduce(sum) }},
+		{name: "GroupBy", evaluate: func() { New(supplier).GroupBy(key).Count() }},
+		{name: "ParallelGroupBy", evaluate: func() { New(supplier).Parallelize(2).GroupBy(key).Reduce(sum) }},
+		{name: "GroupByParallel", evaluate: func() { New(supplier).GroupBy(key).Parallelize(2).Collect() }},
+		{name: "Partition", evaluate: func() { New(supplier).Partition(split).Count() }},
+		{name: "ParallelPartition", evaluate: func() { New(supplier).Parallelize(2).Partition(split).Collect() }},
+		{name: "FlatMap", evaluate: func() { New(supplier).Partition(split).FlatMap().Distinct(key).Count() }},
+		{name: "ParallelFlatMap", evaluate: func() { New(supplier).Partition(split).Parallelize(2).FlatMap().Collect() }},
+		{name: "FlatMapGroupBy", evaluate: func() { New(supplier).Partition(split).FlatMap().GroupBy(key).Count() }},
+		{name: "Tag", evaluate: func() { Untag(Tag(New(supplier).Parallelize(2), key)).Count() }},
+		{name: "PartitionByKey", evaluate: func() { PartitionByKey(New(supplier).Parallelize(2), key).Count() }},
+	}
+
+	for _, test := range supplierCallsTests {
+		atomic.StoreInt32(&calls, 0)
+		test.evaluate()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), test.name)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	assert.Nil(t, New(supplier).Partition(split).FlatMap().GroupBy(key).DryRun())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+}
+
+func TestNilSupplier(t *testing.T) {
+
+	key := func(x int) string { return fmt.Sprint(x % 2) }
+	split := func(x int) []int { return []int{x, x} }
+	sum := func(x, y int) int { return x + y }
+
+	suppliers := []func() []int{nil, func() []int { return nil }}
+	for _, supplier := range suppliers {
+		assert.Equal(t, []int{}, New(supplier).Collect())
+		assert.Equal(t, []int{}, New(supplier).Parallelize(2).Filter(func(x int) bool { return true }).Collect())
+		assert.Equal(t, []int{}, New(supplier).ParallelizeAuto().Collect())
+		assert.Equal(t, 0, New(supplier).Parallelize(2).Reduce(sum))
+		assert.Equal(t, [][]int{{}, {}}, New(supplier).Bucketize(2))
+		cursor := New(supplier).Open()
+		_, ok := cursor.Next()
+		assert.False(t, ok)
+
+		assert.Equal(t, []Group[int]{}, New(supplier).GroupBy(key).Collect())
+		assert.Equal(t, map[string]int{}, New(supplier).Parallelize(2).GroupBy(key).Count())
+		assert.Equal(t, [][]int{}, New(supplier).Partition(split).Collect())
+		assert.Equal(t, [][]int{}, New(supplier).Partition(split).Parallelize(2).Collect())
+		assert.Equal(t, []int{}, New(supplier).Partition(split).Parallelize(2).FlatMap().Collect())
+		assert.Nil(t, New(supplier).Partition(split).DryRun())
+	}
+
+	// Partitioning functions returning nil produce empty partitions.
+	supplier := func() []int { return []int{1, 2} }
+	assert.Equal(t, [][]int{{}, {}}, New(supplier).Partition(func(x int) []int { return nil }).Collect())
+	assert.Equal(t, [][]int{{}, {}}, New(supplier).Parallelize(2).Partition(func(x int) []int { return nil }).Collect())
+	assert.Equal(t, []int{}, New(supplier).Partition(func(x int) []int { return nil }).FlatMap().Collect())
+
+}
+
+func TestMapNFilterN(t *testing.T) {
+
+	data := make([]int, 40)
+	for i := range data {
+		data[i] = i
+	}
+	supplier := func() []int { return data }
+
+	// tracked returns a function recording the maximum number of concurrent calls of f.
+	tracked := func(max *int32) func() func() {
+		var current int32
+		return func() func() {
+			n := atomic.AddInt32(&current, 1)
+			for {
+				m := atomic.LoadInt32(max)
+				if n <= m || atomic.CompareAndSwapInt32(max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return func() { atomic.AddInt32(&current, -1) }
+		}
+	}
+
+	type concurrencyTest struct {
+		s   Stream[int]
+		n   int
+		max int32
+	}
+
+	concurrencyTests := []concurrencyTest{
+		{s: New(supplier), n: 4, max: 1},
+		{s: New(supplier).ParallelizeWith(IOBound(8)), n: 2, max: 2},
+		{s: New(supplier).Parallelize(4), n: 1, max: 1},
+		{s: New(supplier).ParallelizeWith(IOBound(3)), n: 8, max: 3},
+	}
+
+	for _, test := range concurrencyTests {
+		var mapMax, filterMax int32
+		enterMap, enterFilter := tracked(&mapMax), tracked(&filterMax)
+		results := test.s.FilterN(test.n, func(x int) bool {
+			defer enterFilter()()
+			return x%2 == 0
+		}).MapN(test.n, func(x int) int {
+			defer enterMap()()
+			return x * 10
+		}).Collect()
+		assert.Equal(t, 20, len(results))
+		assert.LessOrEqual(t, mapMax, test.max)
+		assert.LessOrEqual(t, filterMax, test.max)
+	}
+
+	assert.Panics(t, func() { New(supplier).MapN(0, func(x int) int { return x }) })
+	assert.Panics(t, func() { New(supplier).FilterN(-1, func(x int) bool { return true }) })
+	assert.NotNil(t, New(supplier).MapN(2, nil).DryRun())
+
+}
+
+func TestBarrier(t *testing.T) {
+
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+	supplier := func() []int { return data }
+
+	type barrierTest struct {
+		s Stream[int]
+	}
+
+	barrierTests := []barrierTest{
+		{s: New(supplier)},
+		{s: New(supplier).Parallelize(4)},
+		{s: New(supplier).ParallelizeWith(IOBound(8))},
+		{s: New(supplier).ParallelizeAuto()},
+	}
+
+	// Every element after the barrier sees the side effects of the Peek on all elements.
+	for _, test := range barrierTests {
+		var seen int64
+		info := test.s.ExecutionInfo()
+		s := test.s.Peek(func(x int) { atomic.AddInt64(&seen, 1) }).Barrier()
+		assert.Equal(t, info, s.ExecutionInfo())
+		results := s.Map(func(x int) int { return int(atomic.LoadInt64(&seen)) }).Collect()
+		assert.Equal(t, 100, len(results))
+		for _, result := range results {
+			assert.Equal(t, 100, result)
+		}
+	}
+
+	s := New(supplier).Distinct(func(x int) string { return fmt.Sprint(x) }).Barrier()
+	assert.True(t, s.(*stream[int]).distinct)
+	assert.Equal(t, 100, s.Count())
+
+}
+
+func TestFilterOutliers(t *testing.T) {
+
+	type filterOutliersTest struct {
+		data     []float64
+		zScore   float64
+		expected []float64
+	}
+
+	var filterOutliersTests = []filterOutliersTest{
+		{data: []float64{}, zScore: 2, expected: []float64{}},
+		{data: []float64{10, 11, 9, 10, 12, 10, 100}, zScore: 2, expected: []float64{10, 11, 9, 10, 12, 10}},
+		{data: []float64{1, 2, 3}, zScore: 3, expected: []float64{1, 2, 3}},
+		{data: []float64{1, 2, 3}, zScore: 0.5, expected: []float64{2}},
+		{data: []float64{5, 5, 5}, zScore: 1, expected: []float64{5, 5, 5}},
+		{data: []float64{1, math.NaN(), 1}, zScore: 1, expected: []float64{1, 1}},
+	}
+
+	identity := func(x float64) float64 { return x }
+	for _, test := range filterOutliersTests {
+		s1, s2 := New(func() []float64 { return test.data }).FilterOutliers(identity, test.zScore),
+			New(func() []float64 { return test.data }).Parallelize(2).FilterOutliers(identity, test.zScore)
+		assert.Equal(t, test.expected, s1.Collect())
+		assert.ElementsMatch(t, test.expected, s2.Collect())
+		assert.True(t, s2.Parallel())
+	}
+
+	// Statistics are computed over the elements resulting from the operations before.
+	s := New(func() []float64 { return []float64{-1000, 10, 11, 9, 10, 12, 10, 100} }).Filter(func(x float64) bool { return x > 0 })
+	assert.Equal(t, []float64{10, 11, 9, 10, 12, 10}, s.FilterOutliers(identity, 2).Collect())
+
+	assert.Panics(t, func() { New[float64](nil).FilterOutliers(nil, 1) })
+	assert.Panics(t, func() { New[float64](nil).FilterOutliers(identity, 0) })
+	assert.Panics(t, func() { New[float64](nil).FilterOutliers(identity, math.NaN()) })
+
+}
+
+func TestAppendLazy(t *testing.T) {
+
+	page := func(data ...int) func() Stream[int] {
+		return func() Stream[int] { return New(func() []int { return data }) }
+	}
+
+	for _, s := range []func() Stream[int]{page(1, 2, 3), func() Stream[int] { return page(1, 2, 3)().Parallelize(2) }} {
+		var calls int32
+		next := func() Stream[int] {
+			atomic.AddInt32(&calls, 1)
+			return New(func() []int { return []int{4, 5} }).AppendLazy(page(6))
+		}
+		appended := s().Filter(func(x int) bool { return x != 2 }).AppendLazy(next).Map(func(x int) int { return x * 10 })
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+		assert.ElementsMatch(t, []int{10, 30, 40, 50, 60}, appended.Collect())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	}
+
+	// A Limit cuts off an unbounded chain of appended streams.
+	var pages int32
+	var from func(i int) Stream[int]
+	from = func(i int) Stream[int] {
+		atomic.AddInt32(&pages, 1)
+		return New(func() []int { return []int{i, i + 1} }).AppendLazy(func() Stream[int] { return from(i + 2) })
+	}
+	for _, s := range []func() Stream[int]{
+		func() Stream[int] { return from(0).Limit(5) },
+		func() Stream[int] { return from(0).Map(func(x int) int { return x }).Limit(5) },
+		func() Stream[int] { return from(0).Parallelize(2).Limit(5) },
+		func() Stream[int] {
+			return from(0).Parallelize(2).Sequential().Filter(func(x int) bool { return x%2 == 0 }).Limit(5)
+		},
+	} {
+		atomic.StoreInt32(&pages, 0)
+		assert.Len(t, s().Collect(), 5)
+		assert.LessOrEqual(t, atomic.LoadInt32(&pages), int32(5))
+	}
+	atomic.StoreInt32(&pages, 0)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, from(0).Limit(5).Collect())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&pages))
+
+	assert.Equal(t, []int{1, 2}, New(func() []int { return []int{1, 2} }).AppendLazy(func() Stream[int] { return nil }).Collect())
+	assert.Equal(t, []int{3}, New[int](nil).AppendLazy(page(3)).Collect())
+	assert.Panics(t, func() { New[int](nil).AppendLazy(nil) })
+
+}
+
+func TestForEachErr(t *testing.T) {
+
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+	supplier := func() []int { return data }
+	failing := func(x int) error {
+		if x%10 == 3 {
+			return fmt.Errorf("failed %d", x)
+		}
+		return nil
+	}
+
+	type forEachErrTest struct {
+		s        Stream[int]
+		failFast bool
+		calls    int64
+		errs     int
+	}
+
+	forEachErrTests := []forEachErrTest{
+		{s: New(supplier), calls: 100, errs: 10},
+		{s: New(supplier).Parallelize(4), calls: 100, errs: 10},
+		{s: New(supplier).ParallelizeAuto(), calls: 100, errs: 10},
+		{s: New(supplier).FailFast(), failFast: true, calls: 4, errs: 1},
+		{s: New(supplier).Filter(func(x int) bool { return x > 50 }).FailFast().Limit(20), failFast: true, calls: 3, errs: 1},
+	}
+
+	for _, test := range forEachErrTests {
+		var calls int64
+		err := test.s.ForEachErr(func(x int) error {
+			atomic.AddInt64(&calls, 1)
+			return failing(x)
+		})
+		assert.Equal(t, test.calls, calls)
+		if test.failFast {
+			assert.Regexp(t, "^failed [0-9]*3$", err.Error())
+			continue
+		}
+		errs := err.(interface{ Unwrap() []error }).Unwrap()
+		assert.Equal(t, test.errs, len(errs))
+		assert.Equal(t, test.errs-1, strings.Count(err.Error(), "\n"))
+	}
+
+	// Routines of a fail fast parallel stream stop taking elements after the first error.
+	var calls int64
+	err := New(supplier).ParallelizeWith(IOBound(2)).FailFast().ForEachErr(func(x int) error {
+		atomic.AddInt64(&calls, 1)
+		return failing(x)
+	})
+	assert.NotNil(t, err)
+	assert.Less(t, calls, int64(100))
+
+	assert.Nil(t, New(supplier).Parallelize(3).ForEachErr(func(x int) error { return nil }))
+
+}
+
+func TestTryMap(t *testing.T) {
+
+	failure := errors.New("failed")
+	parse := func(x string) (string, error) {
+		if x == "" {
+			return x, failure
+		}
+		return strings.ToUpper(x), nil
+	}
+	nonEmpty := func(x string) (bool, error) {
+		if x == "!" {
+			return false, failure
+		}
+		return x != "", nil
+	}
+
+	type tryTest struct {
+		data     []string
+		expected []string
+		err      error
+	}
+
+	var tryTests = []tryTest{
+		{data: []string{}, expected: []string{}, err: nil},
+		{data: []string{"a", "b", "c"}, expected: []string{"A", "B", "C"}, err: nil},
+		{data: []string{"a", "", "c"}, expected: nil, err: failure},
+	}
+
+	for _, test := range tryTests {
+		supplier := func() []string { return test.data }
+		for _, s := range []func() Stream[string]{func() Stream[string] { return New(supplier) }, func() Stream[string] { return New(supplier).Parallelize(2) }} {
+			data, err := s().TryMap(parse).CollectErr()
+			assert.ElementsMatch(t, test.expected, data)
+			assert.Equal(t, test.err, err)
+
+			err = s().TryMap(parse).ForEachErr(func(x string) error { return nil })
+			assert.ErrorIs(t, err, test.err)
+			if test.err == nil {
+				assert.Nil(t, err)
+			}
+		}
+	}
+
+	// TryFilter drops elements like Filter and stops at the first error.
+	data, err := New(func() []string { return []string{"a", "", "b"} }).TryFilter(nonEmpty).CollectErr()
+	assert.Equal(t, []string{"a", "b"}, data)
+	assert.Nil(t, err)
+	var calls int32
+	_, err = New(func() []string { return []string{"a", "!", "b", "c"} }).TryFilter(nonEmpty).Peek(func(x string) { atomic.AddInt32(&calls, 1) }).CollectErr()
+	assert.Equal(t, failure, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// The error is joined to the errors of the action of ForEachErr.
+	actionFailure := errors.New("action failed")
+	err = New(func() []string { return []string{"a", "b", ""} }).TryMap(parse).ForEachErr(func(x string) error {
+		if x == "A" {
+			return actionFailure
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, actionFailure)
+	assert.ErrorIs(t, err, failure)
+
+	// Other terminal operations panic with an error wrapping the error.
+	func() {
+		defer func() {
+			err := recover().(Error)
+			assert.Equal(t, OperationFailed, err.Code())
+			assert.Equal(t, "ErrOperationFailed: Operation TRY_MAP failed: failed.", err.Error())
+			assert.ErrorIs(t, err, failure)
+		}()
+		New(func() []string { return []string{""} }).TryMap(parse).Count()
+	}()
+
+	// Other panics are not recovered.
+	assert.Panics(t, func() {
+		New(func() []string { return []string{"a"} }).Map(func(x string) string { panic("boom") }).CollectErr()
+	})
+
+}
+
+func TestSubStream(t *testing.T) {
+
+	data := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	supplier := func() []int { return data }
+
+	type subStreamTest struct {
+		s        Stream[int]
+		expected []int
+	}
+
+	subStreamTests := []subStreamTest{
+		{s: New(supplier).SubStream(2, 5), expected: []int{2, 3, 4}},
+		{s: New(supplier).SubStream(0, 0), expected: []int{}},
+		{s: New(supplier).SubStream(8, 20), expected: []int{8, 9}},
+		{s: New(supplier).SubStream(12, 20), expected: []int{}},
+		{s: New(supplier).Filter(func(x int) bool { return x%2 == 0 }).SubStream(1, 6), expected: []int{2, 4}},
+		{s: New(supplier).Parallelize(2).SubStream(3, 9), expected: []int{3, 4, 5, 6, 7, 8}},
+		{s: New(supplier).StrideStream(3), expected: []int{0, 3, 6, 9}},
+		{s: New(supplier).StrideStream(1), expected: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{s: New(supplier).StrideStream(20), expected: []int{0}},
+		{s: New(supplier).Map(func(x int) int { return x * 10 }).StrideStream(4), expected: []int{0, 40, 80}},
+		{s: New(supplier).Parallelize(2).SubStream(1, 9).StrideStream(2), expected: []int{1, 3, 5, 7}},
+	}
+
+	for _, test := range subStreamTests {
+		assert.ElementsMatch(t, test.expected, test.s.Collect())
+	}
+
+	// The range is resliced from the source.
+	results := New(supplier).SubStream(4, 6).Collect()
+	assert.Equal(t, &data[4], &results[0])
+
+	assert.Panics(t, func() { New(supplier).SubStream(-1, 2) })
+	assert.Panics(t, func() { New(supplier).SubStream(3, 2) })
+	assert.Panics(t, func() { New(supplier).StrideStream(0) })
+
+}
+
+func TestToSetFunc(t *testing.T) {
+
+	type point struct {
+		x, y  int
+		label string
+	}
+
+	supplier := func() []point {
+		return []point{{1, 2, "a"}, {3, 4, "b"}, {1, 2, "c"}, {5, 6, "d"}, {3, 4, "e"}}
+	}
+	hash := func(p point) string { return fmt.Sprintf("%d,%d", p.x, p.y) }
+
+	type toSetFuncTest struct {
+		s        Stream[point]
+		expected []string
+	}
+
+	toSetFuncTests := []toSetFuncTest{
+		{s: New(supplier), expected: []string{"1,2", "3,4", "5,6"}},
+		{s: New(supplier).Parallelize(2), expected: []string{"1,2", "3,4", "5,6"}},
+		{s: New(supplier).Filter(func(p point) bool { return p.x > 1 }), expected: []string{"3,4", "5,6"}},
+		{s: New(func() []point { return []point{} }), expected: []string{}},
+	}
+
+	for _, test := range toSetFuncTests {
+		set := test.s.ToSetFunc(hash)
+		keys := make([]string, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, test.expected, keys)
+	}
+
+	// The first element with a hash is kept.
+	set := New(supplier).ToSetFunc(hash)
+	assert.Equal(t, "a", set["1,2"].label)
+	assert.Equal(t, "b", set["3,4"].label)
+	assert.Panics(t, func() { New(supplier).ToSetFunc(nil) })
+
+}
+
+func TestWithOffset(t *testing.T) {
+
+	data := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	supplier := func() []int { return data }
+
+	type withOffsetTest struct {
+		s        Stream[int]
+		expected []int
+	}
+
+	withOffsetTests := []withOffsetTest{
+		{s: New(supplier).WithOffset(0), expected: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{s: New(supplier).WithOffset(7), expected: []int{7, 8, 9}},
+		{s: New(supplier).WithOffset(20), expected: []int{}},
+		{s: New(supplier).Filter(func(x int) bool { return x%2 == 0 }).WithOffset(5), expected: []int{6, 8}},
+		{s: New(supplier).Parallelize(2).WithOffset(3).WithOffset(3), expected: []int{6, 7, 8, 9}},
+	}
+
+	for _, test := range withOffsetTests {
+		assert.ElementsMatch(t, test.expected, test.s.Collect())
+	}
+
+	// A job that stopped early is resumed from the consumed count.
+	s := New(supplier).WithOffset(2)
+	assert.Equal(t, 2, s.ConsumedCount())
+	s.ForEachWhile(func(x int) bool { return x < 5 })
+	assert.Equal(t, 6, s.ConsumedCount())
+	resumed := New(supplier).WithOffset(s.ConsumedCount())
+	assert.Equal(t, []int{6, 7, 8, 9}, resumed.Collect())
+	assert.Equal(t, 10, resumed.ConsumedCount())
+
+	assert.Panics(t, func() { New(supplier).WithOffset(-1) })
+
+}
+
+func TestForEachBatchBytes(t *testing.T) {
+
+	supplier := func() []string { return []string{"aa", "bbb", "c", "dddd", "eeeeeeee", "f", "gg"} }
+	size := func(x string) int { return len(x) }
+
+	type forEachBatchBytesTest struct {
+		s        Stream[string]
+		maxBytes int
+		expected [][]string
+	}
+
+	forEachBatchBytesTests := []forEachBatchBytesTest{
+		{s: New(supplier), maxBytes: 5, expected: [][]string{{"aa", "bbb"}, {"c", "dddd"}, {"eeeeeeee"}, {"f", "gg"}}},
+		{s: New(supplier), maxBytes: 100, expected: [][]string{{"aa", "bbb", "c", "dddd", "eeeeeeee", "f", "gg"}}},
+		{s: New(supplier), maxBytes: 1, expected: [][]string{{"aa"}, {"bbb"}, {"c"}, {"dddd"}, {"eeeeeeee"}, {"f"}, {"gg"}}},
+		{s: New(supplier).Filter(func(x string) bool { return false }), maxBytes: 5, expected: [][]string{}},
+	}
+
+	for _, test := range forEachBatchBytesTests {
+		batches := make([][]string, 0)
+		test.s.ForEachBatchBytes(test.maxBytes, size, func(batch []string) { batches = append(batches, batch) })
+		assert.Equal(t, test.expected, batches)
+	}
+
+	// Batches of a parallel stream respect the limit and cover all elements.
+	data := make([]string, 200)
+	for i := range data {
+		data[i] = strings.Repeat("x", i%7+1)
+	}
+	var mux sync.Mutex
+	elements := 0
+	New(func() []string { return data }).Parallelize(4).ForEachBatchBytes(20, size, func(batch []string) {
+		total := 0
+		for _, x := range batch {
+			total += len(x)
+		}
+		assert.LessOrEqual(t, total, 20)
+		mux.Lock()
+		defer mux.Unlock()
+		elements += len(batch)
+	})
+	assert.Equal(t, 200, elements)
+
+	assert.Panics(t, func() { New(supplier).ForEachBatchBytes(0, size, func(batch []string) {}) })
+	assert.Panics(t, func() { New(supplier).ForEachBatchBytes(10, nil, func(batch []string) {}) })
+
+}
+
+func TestErr(t *testing.T) {
+
+	type errTest struct {
+		f               func()
+		expectedErrCode int
+	}
+
+	supplier := func() []int { return []int{} }
+	var errTests = []errTest{
+		{f: func() {
+			a := New(supplier)
+			_ = a.Filter(nil)
+			a.Filter(nil)
+		},
+			expectedErrCode: StreamClosed,
+		},
+		{f: func() {
+			a := New(supplier)
+			_ = a.Filter(nil)
+			a.Map(nil)
+		},
+			expectedErrCode: StreamClosed,
+		},
+		{
+			f: func() {
+				a := New(supplier)
+				_ = a.Collect()
+				a.Filter(nil)
+			},
+			expectedErrCode: StreamTerminated,
+		},
+		{
+			f: func() {
+				_ = New(supplier).Parallelize(1)
+			},
+			expectedErrCode: IllegalConfig,
+		},
+		{
+			f: func() {
+				_ = New(supplier).Limit(-1)
+			},
+			expectedErrCode: IllegalArgument,
+		},
+		{
+			f: func() {
+				_ = New(supplier).Skip(-1)
+			},
+			expectedErrCode: IllegalArgument,
+		},
+		{
+			f: func() {
+				a := New(supplier)
+				_ = a.Filter(nil)
+				a.Collect()
+			},
+			expectedErrCode: StreamClosed,
+		},
+		{
+			f: func() {
+				a := New(supplier)
+				_ = a.Filter(nil)
+				a.Limit(1)
+			},
+			expectedErrCode: StreamClosed,
+		},
+		{
+			f: func() {
+				a := New(supplier)
+				_ = a.Filter(nil)
+				a.Skip(1)
+			},
+			expectedErrCode: StreamClosed,
+		},
+	}
+
+	for _, test := range errTests {
+		t.Run("", func(t *testing.T) {
+			defer func(expectedErrCode int) {
+				if r := recover(); r != nil {
+					assert.Equal(t, expectedErrCode, r.(*streamError).Code())
+				}
+			}(test.expectedErrCode)
+			test.f()
+		})
+	}
+}
+
+func TestE2E(t *testing.T) {
+
+	type e2eTest[T any] struct {
+		data     []int
+		sequence func(s Stream[int]) Stream[int]
+		expected T
+	}
+
+	var e2eTestsA = []e2eTest[[]int]{
+		{data: []int{}, expected: []int{}, sequence: func(s Stream[int]) Stream[int] {
+			return s
+		}},
+		{data: []int{1, 2, 3, 4, 5, 6}, expected: []int{4, 8, 12}, sequence: func(s Stream[int]) Stream[int] {
+			return s.Map(func(x int) int { return x * 2 }).
+				Filter(func(x int) bool { return x%4 == 0 })
+		}},
+		{data: []int{1, 2, 3, 4, 5, 6}, expected: []int{4, 8, 12}, sequence: func(s Stream[int]) Stream[int] {
+			return s.Map(func(x int) int { return x * 2 }).
+				Filter(func(x int) bool { return x%4 == 0 })
+		}},
+	}
+
+	for _, test := range e2eTestsA {
+
+		s1, s2 := New(func() []int { return test.data }),
+			New(func() []int { return test.data }).Parallelize(2)
+
+		assert.ElementsMatch(t, test.expected, test.sequence(s1).Collect())
+		assert.ElementsMatch(t, test.expected, test.sequence(s2).Collect())
+		assert.True(t, s1.Closed())
+		assert.True(t, s2.Closed())
+
+	}
+
+	var e2eTestsB = []e2eTest[int]{
+		{data: []int{}, expected: 0, sequence: func(s Stream[int]) Stream[int] {
+			return s
+		}},
+		{data: []int{1, 2, 3, 4, 5, 6}, expected: 2, sequence: func(s Stream[int]) Stream[int] {
+			return s.Map(func(x int) int { return x * 2 }).
+				Filter(func(x int) bool { return x%4 == 0 }).Limit(2)
+		}},
+		{data: []int{1, 2, 3, 4, 5, 6}, expected: 3, sequence: func(s Stream[int]) Stream[int] {
+			return s.Map(func(x int) int { return x * 2 }).
+				Filter(func(x int) bool { return x%4 == 0 }).Limit(10)
+		}},
+	}
+
+	for _, test := range e2eTestsB {
+
+		s1, s2 := New(func() []int { return test.data }),
+			New(func() []int { return test.data }).Parallelize(2)
+
+		assert.Equal(t, test.expected, test.sequence(s1).Count())
+		assert.Equal(t, test.expected, test.sequence(s2).Count())
+		assert.True(t, s1.Closed())
+		assert.True(t, s2.Closed())
+
+	}
+
+}
+
+func TestOrdered(t *testing.T) {
+
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	even := func(x int) bool { return x%2 == 0 }
+	expected := New(func() []int { return data }).Filter(even).Collect()
+	concat := func(x, y string) string { return x + "," + y }
+
+	for _, s := range []func() Stream[int]{
+		func() Stream[int] { return New(func() []int { return data }).Parallelize(4) },
+		func() Stream[int] { return New(func() []int { return data }).ParallelizeWith(IOBound(4)) },
+		func() Stream[int] {
+			return New(func() []int { return data }).Parallelize(3).WithCostEstimator(func(x int) int { return x % 7 })
+		},
+	} {
+		assert.Equal(t, expected, s().Ordered().Filter(even).Collect())
+		assert.Equal(t, expected, s().Filter(even).Ordered().Barrier().Collect())
+
+		letters := New(func() []string { return []string{"a", "b", "c", "d", "e", "f", "g"} }).Parallelize(3).Ordered()
+		assert.Equal(t, "a,b,c,d,e,f,g", letters.Reduce(concat))
+
+		least, ok := s().Ordered().Filter(func(x int) bool { return x < 0 }).Min(func(x, y int) bool { return x < y })
+		assert.Equal(t, 0, least)
+		assert.False(t, ok)
+	}
+
+	// A sequential stream is unaffected.
+	assert.Equal(t, expected, New(func() []int { return data }).Ordered().Filter(even).Collect())
+
+}
+
+func TestWindow(t *testing.T) {
+
+	type windowTest struct {
+		data     []int
+		window   func(s Stream[int]) PartitionedStream[int]
+		expected [][]int
+	}
+
+	windowTests := []windowTest{
+		{data: []int{}, window: func(s Stream[int]) PartitionedStream[int] { return s.Window(2) }, expected: [][]int{}},
+		{data: []int{1, 2, 3, 4, 5}, window: func(s Stream[int]) PartitionedStream[int] { return s.Window(2) }, expected: [][]int{{1, 2}, {3, 4}, {5}}},
+		{data: []int{1, 2, 3, 4}, window: func(s Stream[int]) PartitionedStream[int] { return s.Window(4) }, expected: [][]int{{1, 2, 3, 4}}},
+		{data: []int{1, 2, 3, 4, 5}, window: func(s Stream[int]) PartitionedStream[int] { return s.SlidingWindow(3, 1) },
+			expected: [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}},
+		{data: []int{1, 2, 3, 4, 5, 6}, window: func(s Stream[int]) PartitionedStream[int] { return s.SlidingWindow(2, 3) },
+			expected: [][]int{{1, 2}, {4, 5}}},
+		{data: []int{1, 2}, window: func(s Stream[int]) PartitionedStream[int] { return s.SlidingWindow(3, 1) }, expected: [][]int{}},
+	}
+
+	for _, test := range windowTests {
+		a := test.window(New(func() []int { return test.data })).Collect()
+		b := test.window(New(func() []int { return test.data }).Parallelize(2)).Collect()
+		assert.Equal(t, test.expected, a)
+		assert.ElementsMatch(t, test.expected, b)
+	}
+
+	sums := New(func() []int { return []int{1, 2, 3, 4, 5, 6, 7} }).Parallelize(3).Filter(func(x int) bool { return x != 4 }).Window(2).
+		Map(func(x int) int { return x * 10 }).Collect()
+	assert.ElementsMatch(t, [][]int{{10, 20}, {30, 50}, {60, 70}}, sums)
+
+	s := New(func() []int { return []int{} })
+	assert.Panics(t, func() { s.Window(0) })
+	assert.Panics(t, func() { s.SlidingWindow(1, 0) })
+	s.Window(1)
+	assert.Panics(t, func() { s.Window(1) })
+}
+
+func TestCollectWithin(t *testing.T) {
+
+	type collectWithinTest struct {
+		s        func(release chan struct{}) Stream[int]
+		expected []int
+		complete bool
+	}
+
+	gated := func(release chan struct{}) func(x int) int {
+		return func(x int) int {
+			if x == 4 {
+				<-release
+			}
+			return x
+		}
+	}
+
+	collectWithinTests := []collectWithinTest{
+		{s: func(release chan struct{}) Stream[int] {
+			return New(func() []int { return []int{1, 2, 3} }).Map(gated(release))
+		}, expected: []int{1, 2, 3}, complete: true},
+		{s: func(release chan struct{}) Stream[int] {
+			return New(func() []int { return []int{1, 2, 3, 4, 5} }).Map(gated(release))
+		}, expected: []int{1, 2, 3}, complete: false},
+		{s: func(release chan struct{}) Stream[int] {
+			return New(func() []int { return []int{1, 2, 3, 4} }).Parallelize(2).Map(gated(release))
+		}, expected: []int{1, 2, 3}, complete: false},
+	}
+
+	for _, test := range collectWithinTests {
+		release := make(chan struct{})
+		results, complete := test.s(release).CollectWithin(100 * time.Millisecond)
+		close(release)
+		assert.ElementsMatch(t, test.expected, results)
+		assert.Equal(t, test.complete, complete)
+	}
+
+	results, complete := New(func() []int { return []int{} }).Parallelize(2).CollectWithin(time.Second)
+	assert.Equal(t, []int{}, results)
+	assert.True(t, complete)
+
+	assert.Panics(t, func() {
+		New(func() []int { return []int{1} }).Map(func(x int) int { panic(x) }).CollectWithin(time.Second)
+	})
+	assert.Panics(t, func() { New(func() []int { return []int{1} }).CollectWithin(-1) })
+
+	// The routine evaluating a stream whose channel stays open returns once the duration elapses.
+	DebugLeakCheck(true)
+	defer DebugLeakCheck(false)
+	for _, parallel := range []bool{false, true} {
+		channel := make(chan int, 2)
+		channel <- 1
+		channel <- 2
+		s, _ := FromChannel(channel)
+		if parallel {
+			s = s.Parallelize(2)
+		}
+		results, complete = s.Map(func(x int) int { return x * 10 }).CollectWithin(50 * time.Millisecond)
+		assert.ElementsMatch(t, []int{10, 20}, results)
+		assert.False(t, complete)
+		assert.Eventually(t, func() bool { return len(LiveRoutines()) == 0 }, time.Second, time.Millisecond)
+	}
 }
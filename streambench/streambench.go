@@ -0,0 +1,108 @@
+// Package streambench runs a stream pipeline against generated data at several sizes and levels of parallelism, so that the benefit of
+// Parallelize for a given pipeline can be measured rather than guessed.
+package streambench
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phantom820/streams"
+)
+
+// Config configuration of a benchmark run.
+type Config struct {
+	Sizes       []int // Number of elements to generate for each run.
+	Parallelism []int // Levels of parallelism to run the pipeline at, a level of 1 runs the pipeline sequentially.
+	Iterations  int   // Number of times the pipeline is run for each size and level of parallelism, defaults to 10.
+}
+
+// Result measurements of a pipeline for one size and level of parallelism.
+type Result struct {
+	Size        int
+	Parallelism int
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+	Speedup     float64 // Sequential time divided by the time at this level of parallelism for the same size.
+}
+
+// Run runs the pipeline for each combination of size and level of parallelism in the config. The data for each size is generated once using
+// generate and the pipeline must end with a terminal operation. A sequential run is always included for every size as the baseline for speedups.
+func Run[T any](generate func(n int) []T, pipeline func(s streams.Stream[T]), config Config) []Result {
+	iterations := config.Iterations
+	if iterations <= 0 {
+		iterations = 10
+	}
+	levels := []int{1}
+	for _, level := range config.Parallelism {
+		if level > 1 {
+			levels = append(levels, level)
+		}
+	}
+
+	results := make([]Result, 0, len(config.Sizes)*len(levels))
+	for _, size := range config.Sizes {
+		data := generate(size)
+		var sequential int64
+		for _, level := range levels {
+			result := measure(data, pipeline, level, iterations)
+			if level == 1 {
+				sequential = result.NsPerOp
+			}
+			if result.NsPerOp > 0 {
+				result.Speedup = float64(sequential) / float64(result.NsPerOp)
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// measure runs the pipeline over the data the given number of times and returns the average cost of a run.
+func measure[T any](data []T, pipeline func(s streams.Stream[T]), level int, iterations int) Result {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		s := streams.New(func() []T { return data })
+		if level > 1 {
+			s = s.Parallelize(level)
+		}
+		pipeline(s)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return Result{
+		Size:        len(data),
+		Parallelism: level,
+		NsPerOp:     elapsed.Nanoseconds() / int64(iterations),
+		AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(iterations),
+		BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / int64(iterations),
+	}
+}
+
+// Report returns the results formatted as a table ordered by size and level of parallelism.
+func Report(results []Result) string {
+	sorted := make([]Result, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size < sorted[j].Size
+		}
+		return sorted[i].Parallelism < sorted[j].Parallelism
+	})
+
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "size\tparallelism\tns/op\tallocs/op\tB/op\tspeedup\t")
+	for _, result := range sorted {
+		fmt.Fprintf(writer, "%d\t%d\t%d\t%d\t%d\t%.2fx\t\n", result.Size, result.Parallelism, result.NsPerOp, result.AllocsPerOp, result.BytesPerOp, result.Speedup)
+	}
+	writer.Flush()
+	return buffer.String()
+}
@@ -0,0 +1,39 @@
+package streambench
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phantom820/streams"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+
+	generate := func(n int) []int {
+		data := make([]int, n)
+		for i := range data {
+			data[i] = i
+		}
+		return data
+	}
+
+	pipeline := func(s streams.Stream[int]) {
+		s.Filter(func(x int) bool { return x%2 == 0 }).Count()
+	}
+
+	results := Run(generate, pipeline, Config{Sizes: []int{10, 100}, Parallelism: []int{1, 2, 4}, Iterations: 2})
+	assert.Len(t, results, 6)
+	for _, result := range results {
+		assert.Contains(t, []int{10, 100}, result.Size)
+		assert.Contains(t, []int{1, 2, 4}, result.Parallelism)
+		if result.Parallelism == 1 {
+			assert.Equal(t, 1.0, result.Speedup)
+		}
+	}
+
+	report := Report(results)
+	assert.Equal(t, 7, strings.Count(report, "\n"))
+	assert.Contains(t, report, "speedup")
+
+}
@@ -0,0 +1,45 @@
+package streamtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/phantom820/streams"
+)
+
+// AssertEmits collects the elements of the stream and fails the test if they are not the expected elements in the given order. Elements are compared
+// by their Go syntax representation (%#v).
+func AssertEmits[T any](t testing.TB, s streams.Stream[T], expected ...T) {
+	t.Helper()
+	actual := s.Collect()
+	if len(actual) != len(expected) {
+		t.Errorf("expected %d element(s), got %d: %#v", len(expected), len(actual), actual)
+		return
+	}
+	for i := range expected {
+		if x, y := fmt.Sprintf("%#v", expected[i]), fmt.Sprintf("%#v", actual[i]); x != y {
+			t.Errorf("expected %s at position %d, got %s", x, i, y)
+			return
+		}
+	}
+}
+
+// AssertEmitsInAnyOrder collects the elements of the stream and fails the test if they are not the same multiset as the expected elements, i.e for a
+// parallel stream. Elements are compared by their Go syntax representation (%#v).
+func AssertEmitsInAnyOrder[T any](t testing.TB, s streams.Stream[T], expected ...T) {
+	t.Helper()
+	if diff := difference(counts(expected), counts(s.Collect())); diff != "" {
+		t.Errorf("elements of stream differ from expected elements: %s", diff)
+	}
+}
+
+// AssertTerminatedState fails the test if a terminal operation has not been invoked on the stream, a terminated stream is also closed.
+func AssertTerminatedState[T any](t testing.TB, s streams.Stream[T]) {
+	t.Helper()
+	if !s.Terminated() {
+		t.Errorf("expected stream to be terminated")
+	}
+	if !s.Closed() {
+		t.Errorf("expected stream to be closed")
+	}
+}
@@ -0,0 +1,58 @@
+package streamtest
+
+import (
+	"testing"
+
+	"github.com/phantom820/streams"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertEmits(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 3} }
+
+	r := &recorder{TB: t}
+	AssertEmits(r, streams.New(supplier).Map(func(x int) int { return x * 2 }), 2, 4, 6)
+	AssertEmits(r, streams.New[int](nil))
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	AssertEmits(r, streams.New(supplier), 1, 3, 2)
+	AssertEmits(r, streams.New(supplier), 1, 2)
+	assert.Equal(t, []string{"expected 3 at position 1, got 2", "expected 2 element(s), got 3: []int{1, 2, 3}"}, r.errors)
+
+}
+
+func TestAssertEmitsInAnyOrder(t *testing.T) {
+
+	supplier := func() []int { return []int{1, 2, 2, 3} }
+
+	r := &recorder{TB: t}
+	AssertEmitsInAnyOrder(r, streams.New(supplier).Parallelize(2), 2, 3, 1, 2)
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	AssertEmitsInAnyOrder(r, streams.New(supplier).Parallelize(2), 1, 2, 3)
+	assert.Equal(t, []string{"elements of stream differ from expected elements: expected 1 occurrence(s) of 2, got 2"}, r.errors)
+
+}
+
+func TestAssertTerminatedState(t *testing.T) {
+
+	s := streams.New(func() []int { return []int{1} })
+	r := &recorder{TB: t}
+	AssertTerminatedState(r, s)
+	assert.Equal(t, []string{"expected stream to be terminated", "expected stream to be closed"}, r.errors)
+
+	s.Filter(func(x int) bool { return true })
+	r = &recorder{TB: t}
+	AssertTerminatedState(r, s)
+	assert.Equal(t, []string{"expected stream to be terminated"}, r.errors)
+
+	s = streams.New(func() []int { return []int{1} })
+	s.Count()
+	r = &recorder{TB: t}
+	AssertTerminatedState(r, s)
+	assert.Empty(t, r.errors)
+
+}
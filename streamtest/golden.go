@@ -0,0 +1,98 @@
+package streamtest
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/phantom820/streams"
+)
+
+// update indicates whether Golden writes the golden files instead of comparing with them, set by running the tests with -update.
+var update = flag.Bool("update", false, "update the golden files compared with by streamtest.Golden")
+
+// goldenOptions options for comparing the elements of a stream with a golden file.
+type goldenOptions struct {
+	stable bool
+}
+
+// GoldenOption an option for comparing the elements of a stream with a golden file with Golden.
+type GoldenOption func(o *goldenOptions)
+
+// StableOrder returns an option which sorts the serialized elements before they are compared, i.e for a parallel stream whose elements are not
+// emitted in a deterministic order.
+func StableOrder() GoldenOption {
+	return func(o *goldenOptions) {
+		o.stable = true
+	}
+}
+
+// Golden collects the elements of the stream and fails the test if they differ from the contents of the golden file at the given path. Elements are
+// serialized as JSON, one element per line, so that the file can be reviewed like any other data contract. Running the tests with -update writes the
+// golden file, and the directories containing it, instead of comparing with it.
+func Golden[T any](t testing.TB, s streams.Stream[T], path string, opts ...GoldenOption) {
+	t.Helper()
+	o := goldenOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	elements := s.Collect()
+	lines := make([]string, len(elements))
+	for i, x := range elements {
+		b, err := json.Marshal(x)
+		if err != nil {
+			t.Fatalf("serializing element %d of stream: %v", i, err)
+			return
+		}
+		lines[i] = string(b)
+	}
+	if o.stable {
+		sort.Strings(lines)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("updating golden file %s: %v", path, err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(content(lines)), 0644); err != nil {
+			t.Fatalf("updating golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file %s, run the tests with -update to create it: %v", path, err)
+		return
+	}
+	expected := strings.Split(string(b), "\n")
+	actual := strings.Split(content(lines), "\n")
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		if i >= len(expected) || i >= len(actual) || expected[i] != actual[i] {
+			t.Errorf("elements of stream differ from golden file %s at line %d: expected %q, got %q", path, i+1, line(expected, i), line(actual, i))
+			return
+		}
+	}
+}
+
+// content returns the contents of a golden file with the given lines.
+func content(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// line returns the line at index i, an empty string is returned if there is no such line.
+func line(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
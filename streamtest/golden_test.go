@@ -0,0 +1,52 @@
+package streamtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phantom820/streams"
+	"github.com/stretchr/testify/assert"
+)
+
+// pair an element serialized to a golden file.
+type pair struct {
+	Key   string
+	Value int
+}
+
+func TestGolden(t *testing.T) {
+
+	supplier := func() []pair { return []pair{{Key: "b", Value: 2}, {Key: "a", Value: 1}, {Key: "c", Value: 3}} }
+	path := filepath.Join(t.TempDir(), "testdata", "pairs.golden")
+
+	// A missing golden file fails the test.
+	r := &recorder{TB: t}
+	Golden(r, streams.New(supplier), path)
+	assert.Len(t, r.errors, 1)
+
+	*update = true
+	r = &recorder{TB: t}
+	Golden(r, streams.New(supplier), path)
+	*update = false
+	assert.Empty(t, r.errors)
+	b, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"Key\":\"b\",\"Value\":2}\n{\"Key\":\"a\",\"Value\":1}\n{\"Key\":\"c\",\"Value\":3}\n", string(b))
+
+	r = &recorder{TB: t}
+	Golden(r, streams.New(supplier), path)
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	Golden(r, streams.New(supplier).Limit(2), path)
+	assert.Equal(t, []string{"elements of stream differ from golden file " + path + " at line 3: expected \"{\\\"Key\\\":\\\"c\\\",\\\"Value\\\":3}\", got \"\""}, r.errors)
+
+	// Elements of a parallel stream are compared in a stable order.
+	sorted := filepath.Join(t.TempDir(), "sorted.golden")
+	assert.Nil(t, os.WriteFile(sorted, []byte("{\"Key\":\"a\",\"Value\":1}\n{\"Key\":\"b\",\"Value\":2}\n{\"Key\":\"c\",\"Value\":3}\n"), 0644))
+	r = &recorder{TB: t}
+	Golden(r, streams.New(supplier).Parallelize(3), sorted, StableOrder())
+	assert.Empty(t, r.errors)
+
+}
@@ -0,0 +1,62 @@
+// Package streamtest provides helpers for testing stream pipelines, i.e checking that a pipeline produces the same results regardless of how it is
+// parallelized. The helpers take a testing.TB so that they can be used from fuzz targets.
+package streamtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/phantom820/streams"
+)
+
+// Parallelism levels a pipeline is evaluated with by EquivalentSequentialParallel.
+var Parallelism = []int{2, 3, 4, 8}
+
+// EquivalentSequentialParallel evaluates the pipeline built by build sequentially, with each level of Parallelism and with an IO bound profile, over
+// a source supplying a copy of the given data. It fails the test if the results of any of the parallel evaluations are not the same multiset as
+// those of the sequential evaluation. Elements are compared by their Go syntax representation (%#v). Pipelines whose result depends on the order of
+// evaluation, i.e using Limit or Skip on unordered data, are not equivalent.
+func EquivalentSequentialParallel[T any, U any](t testing.TB, data []T, build func(s streams.Stream[T]) streams.Stream[U]) {
+	t.Helper()
+	supplier := func() []T {
+		elements := make([]T, len(data))
+		copy(elements, data)
+		return elements
+	}
+
+	expected := counts(build(streams.New(supplier)).Collect())
+	for _, n := range Parallelism {
+		actual := counts(build(streams.New(supplier).Parallelize(n)).Collect())
+		if diff := difference(expected, actual); diff != "" {
+			t.Errorf("results of pipeline with parallelism %d differ from sequential results: %s", n, diff)
+		}
+	}
+	actual := counts(build(streams.New(supplier).ParallelizeWith(streams.IOBound(4))).Collect())
+	if diff := difference(expected, actual); diff != "" {
+		t.Errorf("results of pipeline with profile %v differ from sequential results: %s", streams.IOBound(4), diff)
+	}
+}
+
+// counts returns the number of occurrences of each of the given elements keyed by their Go syntax representation.
+func counts[U any](elements []U) map[string]int {
+	results := make(map[string]int)
+	for _, x := range elements {
+		results[fmt.Sprintf("%#v", x)]++
+	}
+	return results
+}
+
+// difference describes the first difference found between the expected and actual occurrences, an empty string is returned if they are equal.
+func difference(expected, actual map[string]int) string {
+	for x, n := range expected {
+		if actual[x] != n {
+			return fmt.Sprintf("expected %d occurrence(s) of %s, got %d", n, x, actual[x])
+		}
+	}
+	for x, n := range actual {
+		if _, ok := expected[x]; !ok {
+			return fmt.Sprintf("expected 0 occurrence(s) of %s, got %d", x, n)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package streamtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/phantom820/streams"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the failures reported by a helper.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestEquivalentSequentialParallel(t *testing.T) {
+
+	data := []int{5, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+
+	// Order insensitive pipelines are equivalent.
+	r := &recorder{TB: t}
+	EquivalentSequentialParallel(r, data, func(s streams.Stream[int]) streams.Stream[int] {
+		return s.Filter(func(x int) bool { return x > 1 }).Map(func(x int) int { return x * x }).Distinct(func(x int) string { return fmt.Sprint(x) })
+	})
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	EquivalentSequentialParallel(r, data, func(s streams.Stream[int]) streams.Stream[streams.Tagged[int, string]] {
+		return streams.Tag(s, func(x int) string { return strings.Repeat("*", x) })
+	})
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	EquivalentSequentialParallel(r, data, func(s streams.Stream[int]) streams.Stream[int] {
+		if s.Parallel() {
+			return s.Filter(func(x int) bool { return x != 9 })
+		}
+		return s
+	})
+	assert.Equal(t, len(Parallelism)+1, len(r.errors))
+	assert.Equal(t, "results of pipeline with parallelism 2 differ from sequential results: expected 1 occurrence(s) of 9, got 0", r.errors[0])
+
+}
+
+func FuzzEquivalentSequentialParallel(f *testing.F) {
+	f.Add([]byte("streams"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		EquivalentSequentialParallel(t, data, func(s streams.Stream[byte]) streams.Stream[byte] {
+			return s.Filter(func(x byte) bool { return x%2 == 0 }).Distinct(func(x byte) string { return string(x) })
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package streams
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Summary statistics of the evaluation of a stream by a terminal operation.
+type Summary struct {
+	Read       int           // Number of source elements the operations of the stream were applied to.
+	Emitted    int           // Number of elements that resulted from applying the operations of the stream.
+	Duration   time.Duration // Time taken by the terminal operation.
+	Workers    int           // Number of routines used to evaluate the stream, 1 for a sequential evaluation.
+	PeakBuffer int           // Largest number of source elements held in memory at once.
+}
+
+// statistics collects the summary of a stream while it is being evaluated.
+type statistics struct {
+	read     int64
+	emitted  int64
+	start    time.Time
+	duration time.Duration
+	workers  int
+	buffered int
+}
+
+// wrap returns the given operations preceded by an operation counting elements read and followed by one counting elements emitted.
+func wrapStatistics[T any](stats *statistics, operations []operator[T]) []operator[T] {
+	wrapped := make([]operator[T], 0, len(operations)+2)
+	wrapped = append(wrapped, operator[T]{
+		apply: func(x T) (T, bool) {
+			atomic.AddInt64(&stats.read, 1)
+			return x, true
+		},
+		concurrent: true,
+		internal:   true,
+	})
+	wrapped = append(wrapped, operations...)
+	return append(wrapped, operator[T]{
+		apply: func(x T) (T, bool) {
+			atomic.AddInt64(&stats.emitted, 1)
+			return x, true
+		},
+		concurrent: true,
+		internal:   true,
+	})
+}
+
+// finish records the end of the evaluation.
+func (stats *statistics) finish() {
+	stats.duration = time.Since(stats.start)
+}
+
+// summary returns the collected statistics.
+func (stats *statistics) summary() Summary {
+	return Summary{
+		Read:       int(atomic.LoadInt64(&stats.read)),
+		Emitted:    int(atomic.LoadInt64(&stats.emitted)),
+		Duration:   stats.duration,
+		Workers:    stats.workers,
+		PeakBuffer: stats.buffered,
+	}
+}
@@ -0,0 +1,32 @@
+package streams
+
+// Tagged an element of a stream along with metadata computed for it earlier in the pipeline.
+type Tagged[T any, M any] struct {
+	Element T
+	Tag     M
+}
+
+// Tag returns a stream consisting of the elements of the stream tagged with the results of applying the given function to them, so that the
+// metadata travels with the element to later stages.
+func Tag[T any, M any](s Stream[T], f func(x T) M) Stream[Tagged[T, M]] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	} else if f == nil {
+		panic(errIllegalArgument("Tag", "nil"))
+	}
+	return mapElements(source, func(x T) Tagged[T, M] {
+		return Tagged[T, M]{Element: x, Tag: f(x)}
+	})
+}
+
+// Untag returns a stream consisting of the elements of the stream with their tags dropped.
+func Untag[T any, M any](s Stream[Tagged[T, M]]) Stream[T] {
+	source := s.(*stream[Tagged[T, M]])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return mapElements(source, func(x Tagged[T, M]) T {
+		return x.Element
+	})
+}
@@ -0,0 +1,42 @@
+package streams
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTag(t *testing.T) {
+
+	supplier := func() []string {
+		return []string{"2022-01-03 c", "2022-01-01 a", "2021-12-31 z", "2022-01-02 b"}
+	}
+	parse := func(x string) time.Time {
+		date, _ := time.Parse("2006-01-02", strings.Fields(x)[0])
+		return date
+	}
+	since := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type tagTest struct {
+		s        Stream[string]
+		expected []string
+	}
+
+	tagTests := []tagTest{
+		{s: New(supplier), expected: []string{"2022-01-03 c", "2022-01-01 a", "2022-01-02 b"}},
+		{s: New(supplier).Parallelize(2), expected: []string{"2022-01-03 c", "2022-01-01 a", "2022-01-02 b"}},
+	}
+
+	for _, test := range tagTests {
+		tagged := Tag(test.s, parse).Filter(func(x Tagged[string, time.Time]) bool { return !x.Tag.Before(since) })
+		assert.ElementsMatch(t, test.expected, Untag(tagged).Collect())
+	}
+
+	tagged := Tag(New(supplier), parse).Collect()
+	assert.Equal(t, Tagged[string, time.Time]{Element: "2022-01-03 c", Tag: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)}, tagged[0])
+
+	assert.Panics(t, func() { Tag[string, int](New(supplier), nil) })
+
+}
@@ -1,22 +1,63 @@
 package streams
 
 import (
-	"sync"
+	"math"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
 )
 
 // applyOpeartions applies the given operations on the element.
-func applyOperations[T any](val T, operations []operator[T]) (T, bool) {
+func applyOperations[T any](val T, operations []operator[T]) (result T, ok bool) {
 
 	if len(operations) == 0 {
 		return val, true
 	}
-	result, ok := operations[0].apply(val)
-	for i := 1; i < len(operations) && ok; i++ {
+	// An exhausted operator lets no more elements through, so routines sharing it abandon the remaining elements without applying the operations
+	// before it. A sequential evaluation still reads every element.
+	for i := range operations {
+		if operations[i].concurrent && operations[i].exhausted != nil && operations[i].exhausted() {
+			var zero T
+			return zero, false
+		}
+	}
+	// A panic raised by the function of an operation is raised again as an error naming the operation, result is the element the operation was
+	// applied to. Errors raised by the package already describe their cause and are raised unchanged. A captured panic records the element given
+	// and drops it.
+	i := 0
+	defer func() {
+		if r := recover(); r != nil {
+			if c, ok := r.(captured); ok {
+				c.record(val, c.panic)
+				var zero T
+				result, ok = zero, false
+				return
+			}
+			if err, ok := r.(Error); ok {
+				panic(err)
+			}
+			panic(errOperatorPanic(r, operations[i].name, position(operations, i), result))
+		}
+	}()
+	for result, ok = val, true; i < len(operations) && ok; i++ {
 		result, ok = operations[i].apply(result)
 	}
 	return result, ok
 }
 
+// position returns the position of the operation at index i amongst the given operations, operations that are internal to the evaluation are not
+// counted.
+func position[T any](operations []operator[T], i int) int {
+	n := 0
+	for _, operation := range operations[:i] {
+		if !operation.internal {
+			n++
+		}
+	}
+	return n
+}
+
 // subIntervals returns sub intervals by splitting the rane [0,n).]
 func subIntervals(n int, numberOfSubIntervals int) []int {
 	if n == 0 {
@@ -43,18 +84,33 @@ func forEach[T any](data []T, operations []operator[T], f func(T)) {
 }
 
 // parallelForEach performs given action on each resulting element.
-func parallelForEach[T any](data []T, operations []operator[T], f func(T), maxRoutines int) {
+func parallelForEach[T any](data []T, operations []operator[T], f func(T), e executor) {
+	run(data, e, func(partition []T) struct{} {
+		forEach(partition, operations, f)
+		return struct{}{}
+	})
+}
 
-	subIntervals := subIntervals(len(data), maxRoutines)
-	var wg sync.WaitGroup
-	for i := 0; i < len(subIntervals)-1; i++ {
-		wg.Add(1)
-		go func(wg *sync.WaitGroup, partition []T) {
-			defer wg.Done()
-			forEach(partition, operations, f)
-		}(&wg, data[subIntervals[i]:subIntervals[i+1]])
+// forEachWhile performs given action on each resulting element until the action returns false or the given flag is set, the flag is set once the
+// action returns false so that routines sharing it stop.
+func forEachWhile[T any](data []T, operations []operator[T], f func(T) bool, stop *int32) {
+	for _, val := range data {
+		if atomic.LoadInt32(stop) == 1 {
+			return
+		} else if result, ok := applyOperations(val, operations); ok && !f(result) {
+			atomic.StoreInt32(stop, 1)
+			return
+		}
 	}
-	wg.Wait()
+}
+
+// parallelForEachWhile performs given action on each resulting element until the action returns false, routines stop taking elements from their
+// partitions as soon as any of them is stopped.
+func parallelForEachWhile[T any](data []T, operations []operator[T], f func(T) bool, stop *int32, e executor) {
+	run(data, e, func(partition []T) struct{} {
+		forEachWhile(partition, operations, f, stop)
+		return struct{}{}
+	})
 }
 
 // reduce returns result of reduction on the resulting elements after applying given operations.
@@ -63,7 +119,7 @@ func reduce[T any](data []T, operations []operator[T], f func(x, y T) T) (T, boo
 	valid := false
 	for i := range data {
 		y, ok := applyOperations(data[i], operations)
-		if i == 0 && ok {
+		if !valid && ok {
 			x = y
 			valid = true
 		} else if ok {
@@ -74,25 +130,19 @@ func reduce[T any](data []T, operations []operator[T], f func(x, y T) T) (T, boo
 }
 
 // parallelReduce returns result of reduction on the resulting elements after applying given operations.
-func parallelReduce[T any](data []T, operations []operator[T], f func(x, y T) T, maxRoutines int) (T, bool) {
-	subIntervals := subIntervals(len(data), maxRoutines)
-	channel := make(chan []T)
-	for i := 0; i < len(subIntervals)-1; i++ {
-		go func(partition []T) {
-			if val, ok := reduce(partition, operations, f); ok {
-				channel <- []T{val}
-				return
-			}
-			channel <- []T{}
-		}(data[subIntervals[i]:subIntervals[i+1]])
-	}
+func parallelReduce[T any](data []T, operations []operator[T], f func(x, y T) T, e executor) (T, bool) {
+	partials := run(data, e, func(partition []T) []T {
+		if val, ok := reduce(partition, operations, f); ok {
+			return []T{val}
+		}
+		return []T{}
+	})
 
-	results := make([]T, 0)
-	for i := 0; i < len(subIntervals)-1; i++ {
-		results = append(results, <-channel...)
+	results := make([]T, 0, len(partials))
+	for _, partial := range partials {
+		results = append(results, partial...)
 	}
-
-	return reduce(data, operations, f)
+	return reduce(results, []operator[T]{}, f)
 }
 
 // count returns a count of  resulting elements from applying given operations on each input element of the data.
@@ -116,46 +166,41 @@ func groupCount[T any](groups []Group[T]) map[string]int {
 	return result
 }
 
-// parallelCount returns a count of  resulting elements from applying given operations on each input element of the data.
-func parallelCount[T any](data []T, operations []operator[T], maxRoutines int) int {
-
-	subIntervals := subIntervals(len(data), maxRoutines)
-	channel := make(chan int)
-
-	for i := 0; i < len(subIntervals)-1; i++ {
-		go func(partition []T) {
-			channel <- count(partition, operations)
-		}(data[subIntervals[i]:subIntervals[i+1]])
+// groupCountLarge returns a count of each group, adding up groups with the same key.
+func groupCountLarge[T any](groups []Group[T]) map[string]int64 {
+	result := make(map[string]int64)
+	for _, group := range groups {
+		result[group.name] = saturatingAdd(result[group.name], int64(group.Len()))
 	}
+	return result
+}
 
-	count := 0
-	for i := 0; i < len(subIntervals)-1; i++ {
-		count = count + <-channel
+// saturatingAdd returns the sum of the given non negative counts, math.MaxInt64 is returned if the sum overflows.
+func saturatingAdd(x, y int64) int64 {
+	if x > math.MaxInt64-y {
+		return math.MaxInt64
 	}
-	return count
-
+	return x + y
 }
 
-// groupParallelCount returns a count of each group.
-func groupParallelCount[T any](groups []Group[T], maxRoutines int) map[string]int {
-
-	channel := make(chan map[string]int)
-	subIntervals := subIntervals(len(groups), maxRoutines)
-	for i := 0; i < len(subIntervals)-1; i++ {
-		go func(partition []Group[T]) {
-			channel <- groupCount(partition)
-		}(groups[subIntervals[i]:subIntervals[i+1]])
+// parallelCount returns a count of  resulting elements from applying given operations on each input element of the data.
+func parallelCount[T any](data []T, operations []operator[T], e executor) int {
+	counter := 0
+	for _, partial := range run(data, e, func(partition []T) int { return count(partition, operations) }) {
+		counter = counter + partial
 	}
+	return counter
+}
 
+// groupParallelCount returns a count of each group.
+func groupParallelCount[T any](groups []Group[T], e executor) map[string]int {
 	results := make(map[string]int)
-	for i := 0; i < len(subIntervals)-1; i++ {
-		for key, val := range <-channel {
+	for _, partial := range run(groups, e, groupCount[T]) {
+		for key, val := range partial {
 			results[key] = results[key] + val
 		}
 	}
-
 	return results
-
 }
 
 // collect returns a slice of resulting elements from applying given operations on each input element of the data.
@@ -170,33 +215,425 @@ func collect[T any](data []T, operations []operator[T]) []T {
 }
 
 // parallelCollect returns a slice of resulting elements from applying given operations on each input element of the data.
-func parallelCollect[T any](data []T, operations []operator[T], maxRoutines int) []T {
+func parallelCollect[T any](data []T, operations []operator[T], e executor) []T {
+	results := make([]T, 0)
+	for _, partial := range run(data, e, func(partition []T) []T { return collect(partition, operations) }) {
+		results = append(results, partial...)
+	}
+	return results
+}
+
+// orderedPartition the resulting elements of the partition of the data starting at index start.
+type orderedPartition[T any] struct {
+	start int
+	data  []T
+}
 
-	subIntervals := subIntervals(len(data), maxRoutines)
-	channel := make(chan []T)
+// parallelCollectOrdered returns a slice of resulting elements from applying given operations on each input element of the data in parallel, unlike
+// parallelCollect the resulting elements are in the order of the elements of the data they result from.
+func parallelCollectOrdered[T any](data []T, operations []operator[T], e executor) []T {
+	results := make([]T, 0, len(data))
+	for _, partial := range runOrdered(data, e, func(partition []T) []T { return collect(partition, operations) }) {
+		results = append(results, partial...)
+	}
+	return results
+}
 
-	for i := 0; i < len(subIntervals)-1; i++ {
-		go func(partition []T) {
-			channel <- collect(partition, operations)
-		}(data[subIntervals[i]:subIntervals[i+1]])
+// runOrdered is like run except that the results of the partitions are returned in the order of the partitions.
+func runOrdered[T any, R any](data []T, e executor, f func(partition []T) R) []R {
+	indexes := make([]int, len(data))
+	for i := range indexes {
+		indexes[i] = i
 	}
+	partials := run(indexes, e, func(partition []int) orderedPartition[R] {
+		if len(partition) == 0 {
+			return orderedPartition[R]{}
+		}
+		start := partition[0]
+		return orderedPartition[R]{start: start, data: []R{f(data[start : start+len(partition)])}}
+	})
+	sort.Slice(partials, func(i, j int) bool { return partials[i].start < partials[j].start })
+	results := make([]R, 0, len(partials))
+	for _, partial := range partials {
+		results = append(results, partial.data...)
+	}
+	return results
+}
+
+// parallelReduceOrdered returns result of reduction on the resulting elements after applying given operations in parallel, unlike parallelReduce the
+// results of the partitions are reduced in the order of the partitions so that f need not be commutative.
+func parallelReduceOrdered[T any](data []T, operations []operator[T], f func(x, y T) T, e executor) (T, bool) {
+	indexes := make([]int, len(data))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	partials := run(indexes, e, func(partition []int) orderedPartition[T] {
+		if len(partition) == 0 {
+			return orderedPartition[T]{}
+		}
+		start := partition[0]
+		if val, ok := reduce(data[start:start+len(partition)], operations, f); ok {
+			return orderedPartition[T]{start: start, data: []T{val}}
+		}
+		return orderedPartition[T]{start: start}
+	})
+	sort.Slice(partials, func(i, j int) bool { return partials[i].start < partials[j].start })
+	results := make([]T, 0, len(partials))
+	for _, partial := range partials {
+		results = append(results, partial.data...)
+	}
+	return reduce(results, []operator[T]{}, f)
+}
+
+// collectLimited returns a slice of resulting elements from applying given operations on each input element of the data, it stops and returns false
+// as soon as the number of resulting elements exceeds max. The number of resulting elements is tracked using the given counter so that it can be
+// shared by routines.
+func collectLimited[T any](data []T, operations []operator[T], max int, counter *int64) ([]T, bool) {
+	result := make([]T, 0)
+	for i := range data {
+		if atomic.LoadInt64(counter) > int64(max) {
+			return nil, false
+		} else if val, ok := applyOperations(data[i], operations); ok {
+			if atomic.AddInt64(counter, 1) > int64(max) {
+				return nil, false
+			}
+			result = append(result, val)
+		}
+	}
+	return result, true
+}
 
+// limitedPartition the resulting elements of a partition collected by parallelCollectLimited, ok is false if the partition was abandoned.
+type limitedPartition[T any] struct {
+	data []T
+	ok   bool
+}
+
+// parallelCollectLimited returns a slice of resulting elements from applying given operations on each input element of the data, routines abandon
+// their partitions as soon as the number of resulting elements across all of them exceeds max.
+func parallelCollectLimited[T any](data []T, operations []operator[T], max int, e executor) ([]T, bool) {
+	var counter int64
 	results := make([]T, 0)
+	for _, partial := range run(data, e, func(partition []T) limitedPartition[T] {
+		data, ok := collectLimited(partition, operations, max, &counter)
+		return limitedPartition[T]{data: data, ok: ok}
+	}) {
+		if !partial.ok {
+			return nil, false
+		}
+		results = append(results, partial.data...)
+	}
+	return results, true
+}
 
-	for i := 0; i < len(subIntervals)-1; i++ {
-		results = append(results, <-channel...)
+// groupRuns groups the data by the given key function, each run of elements with equal keys forms a group whose members are a subslice of the data.
+// A key that appears in runs that are not adjacent raises an error since the data is not ordered by its key.
+func groupRuns[T any](data []T, f func(x T) string, hasher Hasher[string]) []Group[T] {
+	groups := []Group[T]{}
+	if len(data) == 0 {
+		return groups
 	}
-	return results
+	ended := newKeyIndex(hasher)
+	start, key := 0, f(data[0])
+	for i := 1; i < len(data); i++ {
+		if next := f(data[i]); next != key {
+			groups = append(groups, Group[T]{name: key, data: data[start:i:i]})
+			ended.put(key, 0)
+			if _, ok := ended.get(next); ok {
+				panic(errUnsortedStream("GroupBySorted", next))
+			}
+			start, key = i, next
+		}
+	}
+	return append(groups, Group[T]{name: key, data: data[start:]})
 }
 
-func groupBy[T any](data []T, f func(x T) string) []Group[T] {
-	m := make(map[string][]T)
+// groupBy groups the data by the given key function, the keys are hashed using the given hasher if it is not nil.
+func groupBy[T any](data []T, f func(x T) string, hasher Hasher[string]) []Group[T] {
+	index := newKeyIndex(hasher)
+	groups := []Group[T]{}
 	for _, val := range data {
-		m[f(val)] = append(m[f(val)], val)
+		key := f(val)
+		i, ok := index.get(key)
+		if !ok {
+			i = len(groups)
+			index.put(key, i)
+			groups = append(groups, Group[T]{name: key})
+		}
+		groups[i].data = append(groups[i].data, val)
 	}
-	groups := []Group[T]{}
-	for key := range m {
-		groups = append(groups, Group[T]{name: key, data: m[key]})
+	return groups
+}
+
+// keyCount returns a count of the keys of the resulting elements from applying given operations on each input element of the data.
+func keyCount[T any, K comparable](data []T, operations []operator[T], key func(T) K) map[K]int {
+	counts := make(map[K]int)
+	for i := range data {
+		if val, ok := applyOperations(data[i], operations); ok {
+			counts[key(val)]++
+		}
+	}
+	return counts
+}
+
+// duplicateKeys returns the keys that occur more than once amongst the resulting elements, in order of their second occurrence.
+func duplicateKeys[T any, K comparable](data []T, operations []operator[T], key func(T) K) []K {
+	counts := make(map[K]int)
+	duplicates := make([]K, 0)
+	for i := range data {
+		if val, ok := applyOperations(data[i], operations); ok {
+			k := key(val)
+			counts[k]++
+			if counts[k] == 2 {
+				duplicates = append(duplicates, k)
+			}
+		}
+	}
+	return duplicates
+}
+
+// parallelDuplicateKeys returns the keys that occur more than once amongst the resulting elements. Each routine counts keys in its own shard
+// and the shards are merged once all routines are done.
+func parallelDuplicateKeys[T any, K comparable](data []T, operations []operator[T], key func(T) K, e executor) []K {
+	counts := make(map[K]int)
+	for _, shard := range run(data, e, func(partition []T) map[K]int { return keyCount(partition, operations, key) }) {
+		for k, n := range shard {
+			counts[k] = counts[k] + n
+		}
+	}
+
+	duplicates := make([]K, 0)
+	for k, n := range counts {
+		if n > 1 {
+			duplicates = append(duplicates, k)
+		}
+	}
+	return duplicates
+}
+
+// groupByKey groups the resulting elements from applying given operations on each input element of the data by the given key function, groups
+// are in order of the first element of each group.
+func groupByKey[T any, K comparable](data []T, operations []operator[T], key func(T) K) []KeyedGroup[K, T] {
+	index := make(map[K]int)
+	groups := []KeyedGroup[K, T]{}
+	for i := range data {
+		if val, ok := applyOperations(data[i], operations); ok {
+			k := key(val)
+			j, ok := index[k]
+			if !ok {
+				j = len(groups)
+				index[k] = j
+				groups = append(groups, KeyedGroup[K, T]{key: k})
+			}
+			groups[j].data = append(groups[j].data, val)
+		}
+	}
+	return groups
+}
+
+// parallelGroupByKey groups the resulting elements from applying given operations on each input element of the data by the given key function.
+// Each routine groups the elements of its own partition and the groups of the partitions are merged once all routines are done.
+func parallelGroupByKey[T any, K comparable](data []T, operations []operator[T], key func(T) K, e executor) []KeyedGroup[K, T] {
+	index := make(map[K]int)
+	groups := []KeyedGroup[K, T]{}
+	for _, shard := range run(data, e, func(partition []T) []KeyedGroup[K, T] { return groupByKey(partition, operations, key) }) {
+		for _, g := range shard {
+			j, ok := index[g.key]
+			if !ok {
+				j = len(groups)
+				index[g.key] = j
+				groups = append(groups, KeyedGroup[K, T]{key: g.key})
+			}
+			groups[j].data = append(groups[j].data, g.data...)
+		}
 	}
 	return groups
 }
+
+// validate splits the resulting elements from applying given operations into those that satisfy all of the given rules and those that violate at least one.
+func validate[T any](data []T, operations []operator[T], rules []func(T) error) ([]T, []ValidationError[T]) {
+	valid := make([]T, 0)
+	violations := make([]ValidationError[T], 0)
+	for i := range data {
+		val, ok := applyOperations(data[i], operations)
+		if !ok {
+			continue
+		}
+		errs := make([]error, 0)
+		for _, rule := range rules {
+			if err := rule(val); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			violations = append(violations, ValidationError[T]{Element: val, Errs: errs})
+			continue
+		}
+		valid = append(valid, val)
+	}
+	return valid, violations
+}
+
+// validation the outcome of validating a partition of elements.
+type validation[T any] struct {
+	valid      []T
+	violations []ValidationError[T]
+}
+
+// parallelValidate splits the resulting elements from applying given operations into those that satisfy all of the given rules and those that violate at least one.
+func parallelValidate[T any](data []T, operations []operator[T], rules []func(T) error, e executor) ([]T, []ValidationError[T]) {
+
+	partials := run(data, e, func(partition []T) validation[T] {
+		valid, violations := validate(partition, operations, rules)
+		return validation[T]{valid: valid, violations: violations}
+	})
+
+	valid := make([]T, 0)
+	violations := make([]ValidationError[T], 0)
+	for _, r := range partials {
+		valid = append(valid, r.valid...)
+		violations = append(violations, r.violations...)
+	}
+	return valid, violations
+}
+
+const (
+	autoSampleSize      = 64                       // Number of elements processed to estimate the cost per element of a stream.
+	autoRoutineOverhead = 50 * time.Microsecond    // Estimated cost of starting a routine and merging its results.
+	autoMinRoutineWork  = 20 * autoRoutineOverhead // Minimum amount of work a routine should be given for parallelism to pay off.
+)
+
+// autoSplit processes a sample from the start of the data using the given function to estimate the cost per element of the given operations, returns
+// the remaining data along with the executor it should be processed with, an executor with a single routine means sequential processing.
+func autoSplit[T any](data []T, operations []operator[T], process func(sample []T)) ([]T, executor) {
+	n := autoSampleSize
+	if n > len(data) {
+		n = len(data)
+	}
+	start := time.Now()
+	process(data[:n])
+	elapsed := time.Since(start)
+	rest := data[n:]
+	if n == 0 {
+		return rest, executor{maxRoutines: 1}
+	}
+	for _, operation := range operations {
+		if operation.stateful && !operation.concurrent {
+			return rest, executor{maxRoutines: 1}
+		}
+	}
+
+	perElement := elapsed / time.Duration(n)
+	routines := int(perElement * time.Duration(len(rest)) / autoMinRoutineWork)
+	if cpus := runtime.NumCPU(); routines > cpus {
+		routines = cpus
+	}
+	if routines < 2 {
+		return rest, executor{maxRoutines: 1}
+	}
+	return rest, executor{maxRoutines: routines}
+}
+
+// rebalancedReduce returns the result of reducing each group, groups are split into partitions of roughly equal size across all groups so that
+// routines are given a similar amount of work regardless of skew. The partial results of the partitions of each group are combined in a final phase,
+// in no particular order.
+func rebalancedReduce[T any](groups []Group[T], f func(x, y T) T, e executor) map[string]T {
+	total := 0
+	for _, g := range groups {
+		total += len(g.data)
+	}
+	size := total / (4 * e.maxRoutines)
+	if size < 1 {
+		size = 1
+	}
+	partitions := make([]Group[T], 0, len(groups))
+	for _, g := range groups {
+		if len(g.data) == 0 {
+			partitions = append(partitions, g)
+		}
+		for i := 0; i < len(g.data); i += size {
+			end := i + size
+			if end > len(g.data) {
+				end = len(g.data)
+			}
+			partitions = append(partitions, Group[T]{name: g.name, data: g.data[i:end]})
+		}
+	}
+
+	partials := run(partitions, e, func(partition []Group[T]) []Group[T] {
+		results := make([]Group[T], 0, len(partition))
+		for _, g := range partition {
+			result, _ := reduce(g.data, []operator[T]{}, f)
+			results = append(results, Group[T]{name: g.name, data: []T{result}})
+		}
+		return results
+	})
+
+	results := make(map[string]T)
+	for _, partial := range partials {
+		for _, g := range partial {
+			if result, ok := results[g.name]; ok {
+				results[g.name] = f(result, g.data[0])
+			} else {
+				results[g.name] = g.data[0]
+			}
+		}
+	}
+	return results
+}
+
+// bucketize splits the given data into n buckets, either dealing elements round-robin or into contiguous runs whose sizes differ by at most one.
+func bucketize[T any](data []T, n int, roundRobin bool) [][]T {
+	buckets := make([][]T, n)
+	size, remainder := len(data)/n, len(data)%n
+	for i := range buckets {
+		if i < remainder {
+			buckets[i] = make([]T, 0, size+1)
+		} else {
+			buckets[i] = make([]T, 0, size)
+		}
+	}
+	if roundRobin {
+		for i, x := range data {
+			buckets[i%n] = append(buckets[i%n], x)
+		}
+		return buckets
+	}
+	start := 0
+	for i := range buckets {
+		end := start + cap(buckets[i])
+		buckets[i] = append(buckets[i], data[start:end]...)
+		start = end
+	}
+	return buckets
+}
+
+// filterOutliers returns the elements of the given data whose value is within zScore standard deviations of the mean of the values, the mean and
+// variance are computed using Welford's algorithm. Elements with a NaN value are dropped.
+func filterOutliers[T any](data []T, value func(x T) float64, zScore float64) []T {
+	values := make([]float64, len(data))
+	var n, mean, m2 float64
+	for i := range data {
+		values[i] = value(data[i])
+		if math.IsNaN(values[i]) {
+			continue
+		}
+		n++
+		delta := values[i] - mean
+		mean += delta / n
+		m2 += delta * (values[i] - mean)
+	}
+	deviation := 0.0
+	if n > 0 {
+		deviation = math.Sqrt(m2 / n)
+	}
+	results := make([]T, 0, len(data))
+	for i := range data {
+		if math.IsNaN(values[i]) {
+			continue
+		} else if deviation == 0 || math.Abs(values[i]-mean) <= zScore*deviation {
+			results = append(results, data[i])
+		}
+	}
+	return results
+}
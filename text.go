@@ -0,0 +1,105 @@
+package streams
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	trimSpaceOperatorName    = "TRIM_SPACE"
+	toLowerOperatorName      = "TO_LOWER"
+	normalizeNFCOperatorName = "NORMALIZE_NFC"
+	dropEmptyOperatorName    = "DROP_EMPTY"
+)
+
+// TrimSpace returns a stream consisting of the elements of the stream with leading and trailing white space, as defined by Unicode, removed.
+func TrimSpace(s Stream[string]) Stream[string] {
+	return mapString(s, trimSpaceOperatorName, strings.TrimSpace)
+}
+
+// ToLower returns a stream consisting of the elements of the stream with all Unicode letters mapped to their lower case.
+func ToLower(s Stream[string]) Stream[string] {
+	return mapString(s, toLowerOperatorName, strings.ToLower)
+}
+
+// NormalizeNFC returns a stream consisting of the elements of the stream in Unicode normalization form C, so that strings that are canonically
+// equivalent (i.e an accented letter written as one code point or as a letter followed by a combining accent) are equal.
+func NormalizeNFC(s Stream[string]) Stream[string] {
+	return mapString(s, normalizeNFCOperatorName, norm.NFC.String)
+}
+
+// DropEmpty returns a stream consisting of the elements of the stream that are not empty, i.e after TrimSpace to also drop blank lines.
+func DropEmpty(s Stream[string]) Stream[string] {
+	source := s.(*stream[string])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return new(source, operator[string]{
+		apply: func(x string) (string, bool) { return x, x != "" },
+		name:  dropEmptyOperatorName,
+	})
+}
+
+// ExtractRegexp returns a partitioned stream with an element for each match of the regular expression in the elements of the stream, consisting of
+// the capture groups of the match. The whole match is used as the only member of the element if the expression has no capture groups, elements
+// of the stream without a match are dropped.
+func ExtractRegexp(s Stream[string], re *regexp.Regexp) PartitionedStream[string] {
+	source := s.(*stream[string])
+	if re == nil {
+		panic(errIllegalArgument("ExtractRegexp", "nil"))
+	} else if err := source.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, e := source.source(), source.operations, source.executor
+	if source.parallel {
+		return &partitionedStream[string]{
+			supplier: func() [][]string {
+				matches := make([][]string, 0)
+				for _, partial := range run(supplier(), e, func(partition []string) [][]string { return extractRegexp(partition, operations, re) }) {
+					matches = append(matches, partial...)
+				}
+				return matches
+			},
+			operations: make([]operator[[]string], 0),
+			parallel:   true,
+			executor:   e,
+		}
+	}
+	return &partitionedStream[string]{
+		supplier:   func() [][]string { return extractRegexp(supplier(), operations, re) },
+		operations: make([]operator[[]string], 0),
+		executor:   e,
+	}
+}
+
+// extractRegexp returns the capture groups of each match of the regular expression in the resulting elements from applying given operations on
+// each input element of the data.
+func extractRegexp(data []string, operations []operator[string], re *regexp.Regexp) [][]string {
+	matches := make([][]string, 0)
+	for i := range data {
+		if val, ok := applyOperations(data[i], operations); ok {
+			for _, match := range re.FindAllStringSubmatch(val, -1) {
+				if len(match) > 1 {
+					match = match[1:]
+				}
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// mapString returns a stream consisting of the results of applying the given function to the elements of the stream, by an operation with the given
+// name.
+func mapString(s Stream[string], name string, f func(x string) string) Stream[string] {
+	source := s.(*stream[string])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return new(source, operator[string]{
+		apply: func(x string) (string, bool) { return f(x), true },
+		name:  name,
+	})
+}
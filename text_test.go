@@ -0,0 +1,73 @@
+package streams
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextNormalization(t *testing.T) {
+
+	supplier := func() []string { return []string{"  Café\n", "", "\t", "CAFÉ ", "straße"} }
+
+	type textTest struct {
+		s        func(s Stream[string]) Stream[string]
+		expected []string
+	}
+
+	var textTests = []textTest{
+		{s: TrimSpace, expected: []string{"Café", "", "", "CAFÉ", "straße"}},
+		{s: ToLower, expected: []string{"  café\n", "", "\t", "café ", "straße"}},
+		{s: NormalizeNFC, expected: []string{"  Café\n", "", "\t", "CAFÉ ", "straße"}},
+		{s: DropEmpty, expected: []string{"  Café\n", "\t", "CAFÉ ", "straße"}},
+		{s: func(s Stream[string]) Stream[string] { return DropEmpty(NormalizeNFC(ToLower(TrimSpace(s)))) }, expected: []string{"café", "café", "straße"}},
+	}
+
+	for _, test := range textTests {
+		s1, s2 := test.s(New(supplier)), test.s(New(supplier).Parallelize(2))
+		assert.Equal(t, test.expected, s1.Collect())
+		assert.ElementsMatch(t, test.expected, s2.Collect())
+	}
+
+	// Canonically equivalent strings are equal once normalized.
+	decomposed := New(func() []string { return []string{"Cafe\u0301", "Caf\u00e9"} })
+	assert.Equal(t, []string{"Caf\u00e9", "Caf\u00e9"}, NormalizeNFC(decomposed).Collect())
+
+	s := New(supplier)
+	assert.Contains(t, DropEmpty(TrimSpace(s)).String(), "operations=[TRIM_SPACE DROP_EMPTY]")
+	assert.Panics(t, func() { TrimSpace(s) })
+
+}
+
+func TestExtractRegexp(t *testing.T) {
+
+	supplier := func() []string {
+		return []string{"level=info user=ann", "no match", "level=warn user=bob level=error user=cid", ""}
+	}
+
+	type extractTest struct {
+		re       *regexp.Regexp
+		expected [][]string
+	}
+
+	var extractTests = []extractTest{
+		{re: regexp.MustCompile(`level=(\w+) user=(\w+)`), expected: [][]string{{"info", "ann"}, {"warn", "bob"}, {"error", "cid"}}},
+		{re: regexp.MustCompile(`user=\w+`), expected: [][]string{{"user=ann"}, {"user=bob"}, {"user=cid"}}},
+		{re: regexp.MustCompile(`debug`), expected: [][]string{}},
+	}
+
+	for _, test := range extractTests {
+		s1, s2 := ExtractRegexp(New(supplier), test.re), ExtractRegexp(New(supplier).Parallelize(2), test.re)
+		assert.Equal(t, test.expected, s1.Collect())
+		assert.ElementsMatch(t, test.expected, s2.Collect())
+	}
+
+	levels := ExtractRegexp(DropEmpty(New(supplier)), regexp.MustCompile(`level=(\w+)`)).FlatMap().Collect()
+	assert.Equal(t, []string{"info", "warn", "error"}, levels)
+
+	s := New(supplier)
+	ExtractRegexp(s, regexp.MustCompile(`.`))
+	assert.Panics(t, func() { ExtractRegexp(s, regexp.MustCompile(`.`)) })
+	assert.Panics(t, func() { ExtractRegexp(New(supplier), nil) })
+}
@@ -10,40 +10,36 @@ func transformSupplier[T any, U any](supplier func() []T, operations []operator[
 }
 
 // parallelTransformSupplier transforms a supplier from one type to another in parallel, the prior operations on previous supplier must be invoked once we evaluate new supplier.
-func parallelTransformSupplier[T any, U any](supplier func() []T, operations []operator[T], f func(data []T) []U, maxRoutines int) func() []U {
+func parallelTransformSupplier[T any, U any](supplier func() []T, operations []operator[T], f func(data []T) []U, e executor) func() []U {
 	transformedSupplier := func() []U {
-		data := parallelCollect(supplier(), operations, maxRoutines)
+		data := parallelCollect(supplier(), operations, e)
 		return f(data)
 	}
 	return transformedSupplier
 }
 
-// partitionSupplierElements converts each element of the supplier to a slice using the given function.
+// partitionSupplierElements converts each element of the supplier to a slice using the given function, a nil slice is converted to an empty slice.
 func partitionSupplierElements[T any](data []T, operations []operator[T], f func(x T) []T) [][]T {
 	partitions := make([][]T, 0)
 	for i := 0; i < len(data); i++ {
 		if val, ok := applyOperations(data[i], operations); ok {
-			partitions = append(partitions, f(val))
+			partition := f(val)
+			if partition == nil {
+				partition = []T{}
+			}
+			partitions = append(partitions, partition)
 		}
 	}
 	return partitions
 }
 
 // parallelPartitionSupplierElements converts each element of the supplier to a slice using the given function. Performed in parallel fashion.
-func parallelPartitionSupplierElements[T any](supplier func() []T, operations []operator[T], f func(x T) []T, maxRoutines int) func() [][]T {
+func parallelPartitionSupplierElements[T any](supplier func() []T, operations []operator[T], f func(x T) []T, e executor) func() [][]T {
 
 	partitionedSupplier := func() [][]T {
-		data := supplier()
-		subIntervals := subIntervals(len(data), maxRoutines)
-		channel := make(chan [][]T)
-		for i := 0; i < len(subIntervals)-1; i++ {
-			go func(_partition []T) {
-				channel <- partitionSupplierElements(_partition, operations, f)
-			}(data[subIntervals[i]:subIntervals[i+1]])
-		}
 		partitions := make([][]T, 0)
-		for i := 0; i < len(subIntervals)-1; i++ {
-			partitions = append(partitions, <-channel...)
+		for _, partial := range run(supplier(), e, func(partition []T) [][]T { return partitionSupplierElements(partition, operations, f) }) {
+			partitions = append(partitions, partial...)
 		}
 		return partitions
 	}
@@ -51,6 +47,26 @@ func parallelPartitionSupplierElements[T any](supplier func() []T, operations []
 	return partitionedSupplier
 }
 
+// windows returns the windows of the given size of consecutive elements of the data starting every step elements, a window with less elements than
+// the size at the end of the data is included only if partial is set.
+func windows[T any](data []T, size, step int, partial bool) [][]T {
+	partitions := make([][]T, 0)
+	for i := 0; i < len(data); i += step {
+		j := i + size
+		if j > len(data) {
+			if !partial {
+				break
+			}
+			j = len(data)
+		}
+		partitions = append(partitions, append(make([]T, 0, j-i), data[i:j]...))
+		if j == len(data) {
+			break
+		}
+	}
+	return partitions
+}
+
 // flatMapSupplier converts a supplier of the form [[], [], ...] to a supplier of the form [.......], by joining given slices.
 func flatMapSupplier[T any](supplier func() [][]T, operations []operator[[]T]) func() []T {
 	flatMappedSupplier := func() []T {
@@ -62,11 +78,130 @@ func flatMapSupplier[T any](supplier func() [][]T, operations []operator[[]T]) f
 }
 
 // parallelFlatMapSupplier converts a supplier of the form [[], [], ...] to a supplier of the form [.......], by joining given slices, does this in parallel.
-func parallelFlatMapSupplier[T any](supplier func() [][]T, operations []operator[[]T], maxRoutines int) func() []T {
+func parallelFlatMapSupplier[T any](supplier func() [][]T, operations []operator[[]T], e executor) func() []T {
 	flatMappedSupplier := func() []T {
-		data := parallelCollect(supplier(), operations, maxRoutines)
-		result, _ := parallelReduce(data, []operator[[]T]{}, func(x, y []T) []T { return append(x, y...) }, maxRoutines)
+		data := parallelCollect(supplier(), operations, e)
+		result, _ := parallelReduce(data, []operator[[]T]{}, func(x, y []T) []T { return append(x, y...) }, e)
 		return result
 	}
 	return flatMappedSupplier
 }
+
+// transform returns a stream whose source is the result of applying f to the elements of the given stream, the operations of the given stream are
+// invoked once the new stream is evaluated. The given stream is closed.
+func transform[T any, U any](s *stream[T], f func(data []T) []U) *stream[U] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier := transformSupplier(s.supplier, s.operations, f)
+	if s.parallel {
+		supplier = parallelTransformSupplier(s.supplier, s.operations, f, s.executor)
+	}
+	return derive(s, supplier)
+}
+
+// orderedTransform is like transform except that the elements passed to f are in the order of the source elements they result from, even if the
+// given stream is parallel.
+func orderedTransform[T any, U any](s *stream[T], f func(data []T) []U) *stream[U] {
+	if !s.parallel {
+		return transform(s, f)
+	} else if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, e := s.supplier, s.operations, s.executor
+	return derive(s, func() []U { return f(parallelCollectOrdered(supplier(), operations, e)) })
+}
+
+// derive returns a stream with the given source and the evaluation settings of the given stream, i.e its parallelism and whether the elements of a
+// parallel stream are collected in order. The stream is not known to be distinct as the source may map distinct elements to equal ones, the
+// operations of the given stream are checked by the DryRun of the returned stream.
+func derive[T any, U any](s *stream[T], supplier func() []U) *stream[U] {
+	return &stream[U]{
+		supplier:   supplier,
+		operations: make([]operator[U], 0),
+		parallel:   s.parallel,
+		auto:       s.auto,
+		capture:    s.capture,
+		release:    s.release,
+		interrupt:  s.interrupt,
+		hasher:     s.hasher,
+		failFast:   s.failFast,
+		ordered:    s.ordered,
+		offset:     s.offset,
+		origin:     s.origin,
+		upstream:   s.plan,
+		executor:   s.executor,
+	}
+}
+
+// mapElements returns a stream consisting of the results of applying the given function to the elements of the given stream, see multiMapElements.
+func mapElements[T any, U any](s *stream[T], f func(x T) U) *stream[U] {
+	return multiMapElements(s, func(x T, emit func(U)) { emit(f(x)) })
+}
+
+// multiMapElements returns a stream consisting of the elements emitted by the given function for each element of the given stream, in the order of
+// the elements they result from for a sequential or ordered stream. The operations of the given stream and the function are applied to each element
+// in a single pass, by the routines of the stream if it is parallel. The given stream is closed.
+func multiMapElements[T any, U any](s *stream[T], f func(x T, emit func(U))) *stream[U] {
+	if err := s.close(); err != nil {
+		panic(err)
+	}
+	supplier, operations, e := s.supplier, s.operations, s.executor
+	mapPartition := func(data []T) []U {
+		results := make([]U, 0, len(data))
+		emit := func(y U) { results = append(results, y) }
+		for i := range data {
+			if x, ok := applyOperations(data[i], operations); ok {
+				f(x, emit)
+			}
+		}
+		return results
+	}
+	mapData := mapPartition
+	if s.parallel {
+		partitions := run[T, []U]
+		if s.ordered {
+			partitions = runOrdered[T, []U]
+		}
+		mapData = func(data []T) []U {
+			results := make([]U, 0, len(data))
+			for _, partial := range partitions(data, e, mapPartition) {
+				results = append(results, partial...)
+			}
+			return results
+		}
+	}
+	if s.appended != nil {
+		// The pages of the source are mapped as they are pulled, so that the resulting stream is evaluated a page at a time as well.
+		next := s.appended.pull()
+		a := paged(func() ([]U, bool) {
+			data, ok := next()
+			results := mapData(data)
+			return results, ok && !exhausted(operations)
+		})
+		result := derive(s, a.supply)
+		result.appended = a.evaluatedBy(s.parallel, s.executor)
+		result.supplier = result.appended.supply
+		return result
+	}
+	return derive(s, func() []U { return mapData(supplier()) })
+}
+
+// orderedElementsSupplier returns a supplier of the elements of the stream in the order of the source elements they result from, even if the stream
+// is parallel. The release of the stream happens as soon as its elements have been supplied.
+func orderedElementsSupplier[T any](s *stream[T]) func() []T {
+	supplier, operations, e := s.source(), s.operations, s.executor
+	if s.parallel {
+		return func() []T { return parallelCollectOrdered(supplier(), operations, e) }
+	}
+	return func() []T { return collect(supplier(), operations) }
+}
+
+// elementsSupplier returns a supplier of the resulting elements from applying the operations of the given stream to its source, for a stream that has
+// been closed in order to be consumed by another stream.
+func elementsSupplier[T any](s *stream[T]) func() []T {
+	if s.parallel {
+		return func() []T { return s.collectParallel(s.supplier(), s.operations, s.executor) }
+	}
+	return func() []T { return collect(s.supplier(), s.operations) }
+}
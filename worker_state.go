@@ -0,0 +1,74 @@
+package streams
+
+import "sync"
+
+const mapStatefulOperatorName = "MAP_STATEFUL"
+
+// WorkerState a pool of states for operations that need an expensive resource (i.e a compiled regexp, a buffer or a connection) per worker. A state
+// is only ever used by one routine at a time and is reused across elements, so at most one state is created per concurrently running routine.
+type WorkerState[S any] struct {
+	newState func() S
+	mux      sync.Mutex
+	free     []S
+	states   []S
+}
+
+// WithWorkerState creates a pool of worker states which creates new states using the given function.
+func WithWorkerState[S any](newState func() S) *WorkerState[S] {
+	return &WorkerState[S]{newState: newState}
+}
+
+// acquire returns a state that is not in use, creating one if there is none.
+func (w *WorkerState[S]) acquire() S {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if n := len(w.free); n > 0 {
+		state := w.free[n-1]
+		w.free = w.free[:n-1]
+		return state
+	}
+	state := w.newState()
+	w.states = append(w.states, state)
+	return state
+}
+
+// release returns a state to the pool.
+func (w *WorkerState[S]) release(state S) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.free = append(w.free, state)
+}
+
+// Len returns the number of states that have been created.
+func (w *WorkerState[S]) Len() int {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return len(w.states)
+}
+
+// Each performs the given action on each state that has been created, i.e to release resources once a stream has been evaluated.
+func (w *WorkerState[S]) Each(f func(state S)) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	for _, state := range w.states {
+		f(state)
+	}
+}
+
+// MapStateful returns a stream consisting of the results of applying the given function to the elements of the stream along with a state from
+// the given pool. The state passed to f is not used by any other routine for the duration of the call.
+func MapStateful[T any, S any](s Stream[T], w *WorkerState[S], f func(state S, x T) T) Stream[T] {
+	source := s.(*stream[T])
+	if ok, err := source.valid(); !ok {
+		panic(err)
+	}
+	return new(source, operator[T]{
+		apply: func(x T) (T, bool) {
+			state := w.acquire()
+			defer w.release(state)
+			return f(state, x), true
+		},
+		name:      mapStatefulOperatorName,
+		undefined: f == nil || w == nil,
+	})
+}
@@ -0,0 +1,46 @@
+package streams
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapStateful(t *testing.T) {
+
+	data := make([]int, 100)
+	for i := range data {
+		data[i] = i
+	}
+
+	format := func(buffer *bytes.Buffer, x int) int {
+		buffer.Reset()
+		fmt.Fprint(buffer, x)
+		return buffer.Len()
+	}
+
+	type mapStatefulTest struct {
+		s         Stream[int]
+		maxStates int
+	}
+
+	mapStatefulTests := []mapStatefulTest{
+		{s: New(func() []int { return data }), maxStates: 1},
+		{s: New(func() []int { return data }).Parallelize(4), maxStates: 4},
+		{s: New(func() []int { return data }).ParallelizeWith(IOBound(8)), maxStates: 8},
+	}
+
+	for _, test := range mapStatefulTests {
+		pool := WithWorkerState(func() *bytes.Buffer { return &bytes.Buffer{} })
+		assert.Equal(t, 190, MapStateful(test.s, pool, format).Reduce(func(x, y int) int { return x + y }))
+		assert.GreaterOrEqual(t, pool.Len(), 1)
+		assert.LessOrEqual(t, pool.Len(), test.maxStates)
+
+		visited := 0
+		pool.Each(func(buffer *bytes.Buffer) { visited++ })
+		assert.Equal(t, pool.Len(), visited)
+	}
+
+}